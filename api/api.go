@@ -27,7 +27,6 @@ import (
 	"bytes"
 	"compress/zlib"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -35,11 +34,16 @@ import (
 	"net/http"
 	"net/http/httputil"
 	neturl "net/url"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/chaunsin/netease-cloud-music/api/types"
 	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
 	"github.com/chaunsin/netease-cloud-music/pkg/crypto"
 	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/transport"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
 
 	"github.com/andybalholm/brotli"
 	"github.com/cheggaaa/pb/v3"
@@ -50,8 +54,21 @@ type Config struct {
 	Debug   bool          `json:"debug" yaml:"debug"`
 	Timeout time.Duration `json:"timeout" yaml:"timeout"`
 	Retry   int           `json:"retry" yaml:"retry"`
-	Cookie  cookie.Config `json:"cookie" yaml:"cookie"`
-	// Agent   *Agent                     `json:"agent" yaml:"agent"`
+	// CheckCode 返回内容中code不为200时返回 types.Error 错误,单个请求可通过 Options.CheckCode 开启
+	CheckCode bool          `json:"checkCode" yaml:"checkCode"`
+	Cookie    cookie.Config `json:"cookie" yaml:"cookie"`
+	// Device 请求时模拟的设备,可选值: pc、mac、android、iphone、linux 为空时使用mac
+	Device      Device            `json:"device" yaml:"device"`
+	RateLimit   RateLimitConfig   `json:"rateLimit" yaml:"rateLimit"`
+	RetryPolicy RetryPolicyConfig `json:"retryPolicy" yaml:"retryPolicy"`
+	Transport   transport.Config  `json:"transport" yaml:"transport"`
+	Middleware  MiddlewareConfig  `json:"middleware" yaml:"middleware"`
+	// BaseURL 不为空时所有请求都转发到该地址,原始域名通过X-Forwarded-Host请求头传递,一般用于本地模拟服务测试.
+	// 例如: http://127.0.0.1:8080
+	BaseURL  string         `json:"baseURL" yaml:"baseURL"`
+	Endpoint EndpointConfig `json:"endpoint" yaml:"endpoint"`
+	// Session 登录会话管理,定时刷新token以及检测登录过期
+	Session SessionConfig `json:"session" yaml:"session"`
 }
 
 func (c *Config) Validate() error {
@@ -61,15 +78,46 @@ func (c *Config) Validate() error {
 	if c.Timeout < 0 {
 		return errors.New("timeout is < 0")
 	}
+	if err := c.Device.Validate(); err != nil {
+		return err
+	}
+	if err := c.RateLimit.Validate(); err != nil {
+		return err
+	}
+	if err := c.RetryPolicy.Validate(); err != nil {
+		return err
+	}
+	if err := c.Transport.Validate(); err != nil {
+		return err
+	}
+	if c.BaseURL != "" {
+		if _, err := parseURL(c.BaseURL); err != nil {
+			return fmt.Errorf("baseURL: %w", err)
+		}
+	}
+	if err := c.Endpoint.Validate(); err != nil {
+		return err
+	}
+	if err := c.Session.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
 type Client struct {
-	cfg    *Config
-	cli    *resty.Client
-	cookie *cookie.Cookie
-	l      *log.Logger
-	// agent  *Agent
+	cfg     *Config
+	cli     *resty.Client
+	cookie  *cookie.Cookie
+	l       *log.Logger
+	limiter Limiter
+	retry   *RetryPolicy
+	device  Device
+	mu      sync.Mutex
+	// 中间件
+	middlewares []Middleware
+	handler     Handler
+	metrics     *Metrics
+	session     *Session
 }
 
 func New(cfg *Config) *Client {
@@ -87,10 +135,18 @@ func NewClient(cfg *Config, l *log.Logger) (*Client, error) {
 
 	var opts = []cookie.Option{
 		cookie.WithSyncInterval(cfg.Cookie.Interval),
+		cookie.WithCrypto(cfg.Cookie.Crypto),
+		cookie.WithStorage(cfg.Cookie.Storage),
+		cookie.WithDatabase(cfg.Cookie.Database),
 	}
 	if cfg.Cookie.Filepath != "" {
 		opts = append(opts, cookie.WithFilePath(cfg.Cookie.Filepath))
 	}
+	if cfg.Cookie.Storage == cookie.StorageCookieCloud {
+		cc := cfg.Cookie.CookieCloud
+		cc.Transport = cfg.Transport
+		opts = append(opts, cookie.WithCookieCloud(cc))
+	}
 	if opt := cfg.Cookie.Options; opt != nil && opt.PublicSuffixList != nil {
 		opts = append(opts, cookie.WithPublicSuffixList(cfg.Cookie.PublicSuffixList))
 	}
@@ -99,10 +155,18 @@ func NewClient(cfg *Config, l *log.Logger) (*Client, error) {
 		return nil, fmt.Errorf("NewCookie: %w", err)
 	}
 
+	tr, err := transport.New(&cfg.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("transport.New: %w", err)
+	}
+
 	cli := resty.New()
+	cli.SetTransport(tr)
+	if r := newResolver(cfg); r != nil {
+		cli.SetTransport(&endpointTransport{resolver: r, next: tr})
+	}
 	cli.SetRetryCount(cfg.Retry)
 	cli.SetTimeout(cfg.Timeout)
-	cli.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
 	cli.SetDebug(cfg.Debug)
 	cli.SetCookieJar(jar)
 	cli.OnAfterResponse(contentEncoding)
@@ -115,20 +179,114 @@ func NewClient(cfg *Config, l *log.Logger) (*Client, error) {
 	// })
 
 	c := Client{
-		cfg:    cfg,
-		cli:    cli,
-		cookie: jar,
-		l:      l,
-		// agent:  NewAgent(),
-	}
+		cfg:     cfg,
+		cli:     cli,
+		cookie:  jar,
+		l:       l,
+		limiter: NewRateLimiter(cfg.RateLimit),
+		retry:   NewRetryPolicy(cfg.RetryPolicy),
+		device:  cfg.Device,
+	}
+	c.handler = c.do
+	c.session = newSession(&c, cfg.Session, sessionPath(cfg.Cookie.Filepath))
+	c.Use(c.session.middleware())
+	c.Use(cfg.Middleware.middlewares(&c)...)
+	c.session.start()
 	return &c, nil
 }
 
+// Use 添加中间件,先添加的中间件位于外层.需要在发起请求前调用.
+func (c *Client) Use(m ...Middleware) {
+	c.middlewares = append(c.middlewares, m...)
+	c.handler = Chain(c.middlewares...)(c.do)
+}
+
+// Session 获取登录会话管理
+func (c *Client) Session() *Session {
+	return c.session
+}
+
+// Metrics 获取接口调用统计,未开启 MiddlewareConfig.Metrics 时返回nil
+func (c *Client) Metrics() *Metrics {
+	return c.metrics
+}
+
+// SetLimiter 替换默认的令牌桶限流器
+func (c *Client) SetLimiter(l Limiter) {
+	c.limiter = l
+}
+
+// SetDevice 设置客户端默认模拟的设备,单个请求可通过 Options.Device 覆盖
+func (c *Client) SetDevice(d Device) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	c.device = d
+	return nil
+}
+
+// Profile 获取设备信息,d为空时使用客户端默认设备
+func (c *Client) Profile(d Device) Profile {
+	if d == "" {
+		d = c.device
+	}
+	p, ok := GetProfile(d)
+	if !ok {
+		p, _ = GetProfile(DefaultDevice)
+	}
+	return p
+}
+
+// UserAgent 客户端默认设备的User-Agent
+func (c *Client) UserAgent() string {
+	return c.Profile("").UserAgent
+}
+
+// UploadURL 云盘上传使用的NOS备用上传地址
+func (c *Client) UploadURL() string {
+	if c.cfg.Endpoint.Upload != "" {
+		return strings.TrimSuffix(c.cfg.Endpoint.Upload, "/")
+	}
+	return DefaultUploadURL
+}
+
+// DeviceId 获取设备id,不存在时生成并写入cookie中持久化,保证同一账号多次运行使用相同的设备id
+func (c *Client) DeviceId() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ck, ok := c.Cookie("https://music.163.com", "deviceId"); ok && ck.Value != "" {
+		return ck.Value
+	}
+	var (
+		id     = utils.GenerateDeviceId()
+		uri, _ = neturl.Parse("https://music.163.com")
+	)
+	c.cookie.SetCookies(uri, []*http.Cookie{{
+		Name:    "deviceId",
+		Value:   id,
+		Domain:  "music.163.com",
+		Path:    "/",
+		Expires: time.Now().AddDate(10, 0, 0),
+	}})
+	log.Debug("generate deviceId: %s", id)
+	return id
+}
+
 func (c *Client) Ping(ctx context.Context) error {
 	return nil
 }
 
 func (c *Client) Close(ctx context.Context) error {
+	if c.metrics != nil {
+		var snapshot = c.metrics.Snapshot()
+		for _, endpoint := range c.metrics.Endpoints() {
+			stat := snapshot[endpoint]
+			log.Debug("[metrics] %s requests=%d failures=%d avg=%s max=%s",
+				endpoint, stat.Requests, stat.Failures, stat.Avg(), stat.MaxLatency)
+		}
+	}
+	c.session.close()
 	c.cli.SetCloseConnection(true)
 	return c.cookie.Close(ctx)
 }
@@ -184,7 +342,7 @@ func (c *Client) GetCSRF(url string) (string, bool) {
 	return "", false
 }
 
-// Request 接口请求.
+// Request 接口请求.请求前会进行限流,当接口返回限流相关的错误码时按照重试策略退避重试.
 func (c *Client) Request(ctx context.Context, url string, req, resp interface{}, opts *Options) (*resty.Response, error) {
 	if url == "" || req == nil || resp == nil {
 		return nil, errors.New("request args invalid")
@@ -196,31 +354,77 @@ func (c *Client) Request(ctx context.Context, url string, req, resp interface{},
 		opts.Method = http.MethodPost
 	}
 
+	uri, err := neturl.Parse(url)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx, uri.Hostname()); err != nil {
+			return nil, fmt.Errorf("limiter: %w", err)
+		}
+		response, body, err := c.request(ctx, url, req, resp, opts, attempt)
+		if response == nil {
+			return response, err
+		}
+		code, retry := c.retry.Retryable(attempt, response.StatusCode(), body)
+		if !retry {
+			return response, err
+		}
+		wait := c.retry.Backoff(attempt)
+		log.Warn("[request] %s throttled status=%d code=%d, retry %d after %s", url, response.StatusCode(), code, attempt+1, wait)
+		c.limiter.Backoff(uri.Hostname(), wait)
+	}
+}
+
+// request 经过中间件发送一次接口请求,返回解密后的响应内容
+func (c *Client) request(ctx context.Context, url string, req, resp interface{}, opts *Options, attempt int) (*resty.Response, []byte, error) {
+	var call = Call{
+		Url:     url,
+		Options: opts.Clone(),
+		Request: req,
+		Reply:   resp,
+		Attempt: attempt,
+	}
+	err := c.handler(ctx, &call)
+	return call.Response, call.Body, err
+}
+
+// do 中间件链最内层的处理,完成请求参数加密、发送请求、响应解密以及解码
+func (c *Client) do(ctx context.Context, call *Call) error {
+	var err error
+	call.Response, call.Body, err = c.send(ctx, call)
+	return err
+}
+
+func (c *Client) send(ctx context.Context, call *Call) (*resty.Response, []byte, error) {
 	var (
+		url         = call.Url
+		req         = call.Request
+		resp        = call.Reply
+		opts        = call.Options
 		encryptData map[string]string
-		err         error
 		response    *resty.Response
+		encrypted   bool
 	)
 
 	uri, err := neturl.Parse(url)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// todo: set User-Agent config
+	var profile = c.Profile(opts.Device)
 
 	request := c.cli.R().
-		SetContext(ctx).
-		SetHeader("Host", "music.163.com").
+		SetContext(withCryptoMode(ctx, opts.CryptoMode)).
 		SetHeader("Connection", "keep-alive").
 		SetHeader("Accept", "*/*").
 		SetHeader("Accept-Encoding", "gzip, deflate, br").
 		SetHeader("Content-Type", "application/x-www-form-urlencoded").
 		SetHeader("Accept-language", "zh-CN,zh-Hans;q=0.9").
 		SetHeader("Referer", "https://music.163.com").
-		SetHeader("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) NeteaseMusicDesktop/2.3.17.1034").
+		SetHeader("User-Agent", profile.UserAgent).
 		SetCookie(&http.Cookie{Name: "__remember_me", Value: "true", Domain: ""})
-	// SetHeader("User-Agent", "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/25.1 Chrome/121.0.0.0 Mobile Safari/537.36")
 
 	// append
 	if len(opts.Headers) > 0 {
@@ -229,40 +433,36 @@ func (c *Client) Request(ctx context.Context, url string, req, resp interface{},
 	if len(opts.Cookies) > 0 {
 		request.SetCookies(opts.Cookies)
 	}
+	if len(opts.Queries) > 0 {
+		request.SetQueryParams(opts.Queries)
+	}
 
+	// 在注入eapi header之前输出,header中包含MUSIC_U等登录凭证
+	log.Debug("[request]: %+v", req)
 	switch opts.CryptoMode {
 	case CryptoModeEAPI:
-		// todo: set common params
-		// var dataHeader = http.Header{}
-		// dataHeader.Add("osver", getCookie(options.cookies, "osver"))
-		// dataHeader.Add("deviceId", getCookie(options.cookies, "deviceId"))
-		// dataHeader.Add("appver", getCookie(options.cookies, "appver", "6.1.1"))
-		// dataHeader.Add("versioncode", getCookie(options.cookies, "versioncode", "140"))
-		// dataHeader.Add("mobilename", getCookie(options.cookies, "mobilename"))
-		// dataHeader.Add("buildver", getCookie(options.cookies, "buildver"))
-		// dataHeader.Add("resolution", getCookie(options.cookies, "resolution", "1920x1080"))
-		// dataHeader.Add("__csrf", getCookie(options.cookies, "__csrf"))
-		// dataHeader.Add("os", getCookie(options.cookies, "os", "android"))
-		// dataHeader.Add("channel", getCookie(options.cookies, "channel"))
-		// dataHeader.Add("requestId", fmt.Sprintf("%d_%04d", time.Now().UnixNano()/1000000, r.Intn(1000)))
-		// if c := getCookie(options.cookies, "MUSIC_U"); c != "" {
-		// 	dataHeader.Add("MUSIC_U", c)
-		// }
-		// if c := getCookie(options.cookies, "MUSIC_A"); c != "" {
-		// 	dataHeader.Add("MUSIC_A", c)
-		// }
-		// req.Header.Set("Cookie", "")
-		// for k, v := range dataHeader {
-		// 	req.AddCookie(&http.Cookie{
-		// 		Name:  k,
-		// 		Value: v[0],
-		// 	})
-		// }
-		// data["header"] = dataHeader
+		var header = NewHeader(profile, c.DeviceId())
+		header.CSRF, _ = c.GetCSRF(url)
+		if ck, ok := c.Cookie(url, "MUSIC_U"); ok {
+			header.MusicU = ck.Value
+		}
+		if ck, ok := c.Cookie(url, "MUSIC_A"); ok {
+			header.MusicA = ck.Value
+		}
+		request.SetCookies(header.Cookies())
+
+		var params = map[string]interface{}{"header": header}
+		if encrypted = encryptResponse(req, opts); encrypted {
+			params["e_r"] = true
+		}
+		req, err = withParams(req, params)
+		if err != nil {
+			return nil, nil, fmt.Errorf("withParams: %w", err)
+		}
 
 		encryptData, err = crypto.EApiEncrypt(uri.Path, req)
 		if err != nil {
-			return nil, fmt.Errorf("EApiEncrypt: %w", err)
+			return nil, nil, fmt.Errorf("EApiEncrypt: %w", err)
 		}
 	case CryptoModeWEAPI:
 		// todo: 需要替换？因为有些 https://interface.music.163.com/api 得接口也会走这个逻辑
@@ -289,43 +489,43 @@ func (c *Client) Request(ctx context.Context, url string, req, resp interface{},
 
 		encryptData, err = crypto.WeApiEncrypt(req)
 		if err != nil {
-			return nil, fmt.Errorf("WeApiEncrypt: %w", err)
+			return nil, nil, fmt.Errorf("WeApiEncrypt: %w", err)
 		}
 	case CryptoModeLinux:
+		// GET请求时参数以明文形式放入查询参数中
+		if opts.Method == http.MethodGet {
+			encryptData, err = encodeForm(req)
+			if err != nil {
+				return nil, nil, fmt.Errorf("encodeForm: %w", err)
+			}
+			break
+		}
 		encryptData, err = crypto.LinuxApiEncrypt(req)
 		if err != nil {
-			return nil, fmt.Errorf("LinuxApiEncrypt: %w", err)
+			return nil, nil, fmt.Errorf("LinuxApiEncrypt: %w", err)
 		}
 	case CryptoModeAPI:
-		// 不需要加密处理请求
-		// todo: 待处理,在/api/xx/接口请求时则不需要参数加密处理,此处需要对结构体转换成map[string]string类型
-		// b, err := json.Marshal(req)
-		// if err != nil {
-		// 	return nil, fmt.Errorf("json.Marshal: %w", err)
-		// }
-		// var m map[string]interface{}
-		// if err := json.Unmarshal(b, &m); err != nil {
-		// 	return nil, fmt.Errorf("json.Unmarshal: %w", err)
-		// }
-		// encryptData = make(map[string]string)
-		// for k, v := range m {
-		// 	encryptData[k] = fmt.Sprint(v)
-		// }
+		// 不需要加密处理请求,在/api/xx/接口请求时参数以明文表单形式提交
+		encryptData, err = encodeForm(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encodeForm: %w", err)
+		}
 	default:
-		return nil, fmt.Errorf("%s crypto mode unknown", opts.CryptoMode)
+		return nil, nil, fmt.Errorf("%s crypto mode unknown", opts.CryptoMode)
 	}
-	log.Debug("[request]: %+v encrypt: %+v", req, encryptData)
+	call.Form = encryptData
 
 	switch opts.Method {
 	case http.MethodPost:
 		response, err = request.SetFormData(encryptData).Post(url)
 	case http.MethodGet:
-		response, err = request.Get(url)
+		// weapi、eapi为加密后的params、encSecKey,api、linux为明文参数
+		response, err = request.SetQueryParams(encryptData).Get(url)
 	default:
-		return nil, fmt.Errorf("%s not surpport http method", opts.Method)
+		return nil, nil, fmt.Errorf("%s not surpport http method", opts.Method)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
+		return nil, nil, fmt.Errorf("do request: %w", err)
 	}
 	log.Debug("[response.raw]: %s", string(response.Body()))
 
@@ -335,36 +535,46 @@ func (c *Client) Request(ctx context.Context, url string, req, resp interface{},
 		// tips: api接口返回数据是明文
 		decryptData = response.Body()
 	case CryptoModeEAPI:
-		// TODO: 貌似eapi接口返回数据是否是是明文,跟传入参数e_r: true有关,true为加密，false为明文。此处考虑采用反射req中得字段处理。
-		// see: https://gitlab.com/Binaryify/neteasecloudmusicapi/-/commit/58e9865b70e41197c2ab75c46a775fc45d6efa6e
-		// decryptData, err = crypto.EApiDecrypt(string(response.Body()), "")
-		// if err != nil {
-		// 	return nil, fmt.Errorf("EApiDecrypt: %w", err)
-		// }
+		// tips: eapi接口返回数据是否加密跟请求参数e_r有关,true为加密，false为明文。
+		// 要求加密时部分接口(例如出错时)仍会返回明文,因此返回内容为json时不再解密
 		decryptData = response.Body()
+		if encrypted && len(decryptData) > 0 && !isJSON(decryptData) {
+			decryptData, err = crypto.EApiDecrypt(string(response.Body()), "")
+			if err != nil {
+				return response, nil, fmt.Errorf("EApiDecrypt: %w", err)
+			}
+		}
 		log.Debug("[response.decrypt]: %s", string(decryptData))
 	case CryptoModeWEAPI:
 		// tips: weapi接口返回数据是明文
 		decryptData = response.Body()
 	case CryptoModeLinux:
-		decryptData, err = crypto.LinuxApiDecrypt(string(response.Body()))
-		if err != nil {
-			return nil, fmt.Errorf("LinuxApiDecrypt: %w", err)
+		decryptData = response.Body()
+		if !isJSON(decryptData) {
+			decryptData, err = crypto.LinuxApiDecrypt(string(response.Body()))
+			if err != nil {
+				return nil, nil, fmt.Errorf("LinuxApiDecrypt: %w", err)
+			}
 		}
 		log.Debug("[response.decrypt]: %s", string(decryptData))
 	default:
-		return nil, fmt.Errorf("%s crypto mode unknown", opts.CryptoMode)
+		return nil, nil, fmt.Errorf("%s crypto mode unknown", opts.CryptoMode)
 	}
 
 	decode := json.NewDecoder(bytes.NewReader(decryptData))
 	// decode.DisallowUnknownFields()
 	if err := decode.Decode(&resp); err != nil {
-		return nil, fmt.Errorf("json.NewDecoder: %w", err)
+		return response, decryptData, fmt.Errorf("json.NewDecoder: %w", err)
 	}
 	if response.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("http status code: %d detail: %s", response.StatusCode(), string(decryptData))
+		return response, decryptData, fmt.Errorf("http status code: %d detail: %s", response.StatusCode(), string(decryptData))
 	}
-	return response, nil
+	if opts.CheckCode || c.cfg.CheckCode {
+		if err := types.CheckCode(resp, uri.Path); err != nil {
+			return response, decryptData, err
+		}
+	}
+	return response, decryptData, nil
 }
 
 func (c *Client) Upload(ctx context.Context, url string, headers map[string]string, data io.Reader, resp interface{}, bar *pb.ProgressBar) (*resty.Response, error) {
@@ -372,6 +582,11 @@ func (c *Client) Upload(ctx context.Context, url string, headers map[string]stri
 	if bar != nil {
 		body = bar.NewProxyReader(data)
 	}
+	if uri, err := neturl.Parse(url); err == nil {
+		if err := c.limiter.Wait(ctx, uri.Hostname()); err != nil {
+			return nil, fmt.Errorf("limiter: %w", err)
+		}
+	}
 
 	response, err := c.cli.R().
 		SetContext(ctx).
@@ -379,7 +594,7 @@ func (c *Client) Upload(ctx context.Context, url string, headers map[string]stri
 		SetHeader("Connection", "keep-alive").
 		SetHeader("Accept", "*/*").
 		SetHeader("Referer", "https://music.163.com").
-		SetHeader("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) NeteaseMusicDesktop/2.3.17.1034").
+		SetHeader("User-Agent", c.UserAgent()).
 		SetBody(body).
 		Post(url)
 	if err != nil {
@@ -395,20 +610,11 @@ func (c *Client) Upload(ctx context.Context, url string, headers map[string]stri
 	return response, nil
 }
 
+// Download 下载文件并写入resp中,不支持断点续传,如需断点续传请使用 DownloadFile
 func (c *Client) Download(ctx context.Context, url string, headers map[string]string, reqBody io.Reader, resp io.Writer, bar *pb.ProgressBar) (*http.Response, error) {
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, reqBody)
+	request, err := c.newDownloadRequest(ctx, url, headers, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("NewRequestWithContext: %w", err)
-	}
-	request.Header.Set("Connection", "keep-alive")
-	request.Header.Set("Accept", "*/*")
-	request.Header.Set("Referer", "https://music.163.com")
-	request.Header.Set("Accept-Encoding", "gzip")
-	request.Header.Set("Accept-Language", "zh-CN,zh-Hans;q=0.9")
-	request.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) NeteaseMusicDesktop/2.3.17.1034")
-	request.Header.Set("Range", "bytes=0-")
-	for k, v := range headers {
-		request.Header.Set(k, v)
+		return nil, err
 	}
 
 	response, err := c.cli.GetClient().Do(request)
@@ -435,6 +641,26 @@ func (c *Client) Download(ctx context.Context, url string, headers map[string]st
 	return response, nil
 }
 
+func (c *Client) newDownloadRequest(ctx context.Context, url string, headers map[string]string, body io.Reader) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("NewRequestWithContext: %w", err)
+	}
+	request.Header.Set("Connection", "keep-alive")
+	request.Header.Set("Accept", "*/*")
+	request.Header.Set("Referer", "https://music.163.com")
+	request.Header.Set("Accept-Encoding", "gzip")
+	request.Header.Set("Accept-Language", "zh-CN,zh-Hans;q=0.9")
+	request.Header.Set("User-Agent", c.UserAgent())
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+	if err := c.limiter.Wait(ctx, request.URL.Hostname()); err != nil {
+		return nil, fmt.Errorf("limiter: %w", err)
+	}
+	return request, nil
+}
+
 func contentEncoding(c *resty.Client, resp *resty.Response) error {
 	var kind = resp.Header().Get("Content-Encoding")
 	// log.Debug("Content-Encoding: %s Uncompressed: %v", kind, resp.RawResponse.Uncompressed)
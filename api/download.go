@@ -0,0 +1,373 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/cheggaaa/pb/v3"
+)
+
+const (
+	// PartSuffix 未下载完成的文件后缀
+	PartSuffix = ".part"
+	// StateSuffix 断点续传状态文件后缀,与.part文件放在同一目录
+	StateSuffix = ".part.json"
+)
+
+var (
+	// ErrMd5Mismatch 下载完成后文件md5与期望值不一致
+	ErrMd5Mismatch = errors.New("md5 mismatch")
+	// ErrSizeMismatch 下载完成后文件大小与期望值不一致
+	ErrSizeMismatch = errors.New("size mismatch")
+)
+
+// PartState 断点续传状态,用于判断已下载的.part文件是否属于同一个资源
+type PartState struct {
+	Md5          string    `json:"md5"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	// Segments 分段下载时各分段的进度,单连接下载时为空
+	Segments []*PartSegment `json:"segments,omitempty"`
+}
+
+// DownloadFileReq 断点续传下载请求
+type DownloadFileReq struct {
+	// Url 下载地址
+	Url string
+	// Filename 最终文件路径,下载过程中数据写入 Filename+PartSuffix,状态写入 Filename+StateSuffix
+	Filename string
+	// Md5 文件期望md5值,为空则不校验,通常来自 SongPlayerV1 接口返回的md5
+	Md5 string
+	// Size 文件期望大小,小于等于0则不校验
+	Size int64
+	// Headers 额外请求头
+	Headers map[string]string
+	// Segments 分段数量,大于1时将文件按字节范围拆分成多段并发下载,服务端不支持Range时自动退化为单连接下载
+	Segments int
+}
+
+// PartName 返回下载过程中使用的.part文件路径
+func (r *DownloadFileReq) PartName() string {
+	return r.Filename + PartSuffix
+}
+
+// StateName 返回断点续传状态文件路径
+func (r *DownloadFileReq) StateName() string {
+	return r.Filename + StateSuffix
+}
+
+// DownloadFileResp 断点续传下载结果
+type DownloadFileResp struct {
+	// Response 最后一次请求的响应,当本地文件已完整时为nil
+	Response *http.Response
+	// Offset 续传起始位置,为0则表示从头下载
+	Offset int64
+	// Written 本次写入的字节数
+	Written int64
+	// Resumed 是否从已存在的.part文件继续下载
+	Resumed bool
+}
+
+// DownloadFile 支持断点续传的文件下载.
+// 数据先写入固定名称的.part文件,并在旁边保存一份状态文件,中断后再次调用时使用Range请求头从已下载的位置继续下载。
+// 当服务端不支持Range(返回200)时会自动截断文件从头下载。下载完成后校验文件大小以及md5,
+// 校验失败会删除.part文件,成功后删除状态文件并将.part文件重命名为 req.Filename。
+// 当 req.Segments 大于1时使用多连接分段下载,see: downloadSegments
+func (c *Client) DownloadFile(ctx context.Context, req *DownloadFileReq, bar *pb.ProgressBar) (*DownloadFileResp, error) {
+	if req == nil || req.Url == "" || req.Filename == "" {
+		return nil, errors.New("download args invalid")
+	}
+	var (
+		part  = req.PartName()
+		state = c.loadPartState(req)
+		reply DownloadFileResp
+	)
+
+	file, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("OpenFile: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("Stat: %w", err)
+	}
+	var offset = stat.Size()
+	// 状态文件不存在或者与本次下载资源不一致,则无法确认已下载内容的有效性,只能从头下载
+	if state == nil || (req.Size > 0 && offset > req.Size) {
+		offset = 0
+		state = &PartState{Md5: req.Md5, Size: req.Size}
+	}
+
+	// 分段下载,上一次为分段下载时即使本次未指定分段数量也继续分段下载,避免丢失已下载的进度
+	if len(state.Segments) > 0 || (req.Segments > 1 && req.Size >= minSegmentSize*2) {
+		resp, done, n, err := c.downloadSegments(ctx, req, file, state, offset, bar)
+		reply.Response, reply.Offset, reply.Resumed, reply.Written = resp, done, done > 0, n
+		switch {
+		case errors.Is(err, errRangeUnsupported):
+			log.Warn("download %s range unsupported, fallback to single connection", part)
+			state.Segments, offset = nil, 0
+		case errors.Is(err, errRemoteChanged):
+			// 已下载的分段属于旧文件,丢弃状态后从头下载
+			log.Warn("download %s %s, restart from 0", part, err)
+			state, offset = &PartState{Md5: req.Md5, Size: req.Size}, 0
+		case err != nil:
+			return &reply, err
+		default:
+			if err := c.finishPart(req, file); err != nil {
+				return &reply, err
+			}
+			return &reply, nil
+		}
+	}
+
+	// 本地文件已经下载完整
+	if req.Size > 0 && offset == req.Size {
+		if err := c.finishPart(req, file); err != nil {
+			return nil, err
+		}
+		if bar != nil {
+			bar.SetCurrent(offset)
+		}
+		reply.Offset, reply.Resumed = offset, true
+		return &reply, nil
+	}
+
+	for retry := 0; ; retry++ {
+		resp, start, n, err := c.downloadPart(ctx, req, file, state, offset, bar)
+		reply.Response, reply.Offset, reply.Resumed = resp, start, start > 0
+		reply.Written += n
+		if errors.Is(err, errRangeNotSatisfiable) && offset > 0 && retry == 0 {
+			log.Warn("download %s range %d not satisfiable, restart", part, offset)
+			offset = 0
+			continue
+		}
+		if err != nil {
+			return &reply, err
+		}
+		break
+	}
+
+	if err := c.finishPart(req, file); err != nil {
+		return &reply, err
+	}
+	return &reply, nil
+}
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// downloadPart 从offset位置开始下载,并返回实际的起始位置以及写入的字节数
+func (c *Client) downloadPart(ctx context.Context, req *DownloadFileReq, file *os.File, state *PartState, offset int64, bar *pb.ProgressBar) (*http.Response, int64, int64, error) {
+	request, err := c.newDownloadRequest(ctx, req.Url, req.Headers, nil)
+	if err != nil {
+		return nil, offset, 0, err
+	}
+	request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	if offset > 0 {
+		// 当远端文件发生变化时服务端会返回完整内容(200),从而避免拼接出错误的文件
+		if state.ETag != "" {
+			request.Header.Set("If-Range", state.ETag)
+		} else if state.LastModified != "" {
+			request.Header.Set("If-Range", state.LastModified)
+		}
+	}
+	// 续传时需要原始字节,不能让服务端压缩
+	request.Header.Set("Accept-Encoding", "identity")
+
+	response, err := c.cli.GetClient().Do(request)
+	if err != nil {
+		return nil, offset, 0, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		start, _, total, err := parseContentRange(response.Header.Get("Content-Range"))
+		if err != nil {
+			return response, offset, 0, fmt.Errorf("Content-Range: %w", err)
+		}
+		if start != offset {
+			return response, offset, 0, fmt.Errorf("unexpected Content-Range start %d want %d", start, offset)
+		}
+		if req.Size > 0 && total > 0 && total != req.Size {
+			return response, offset, 0, fmt.Errorf("%w: remote=%d want=%d", ErrSizeMismatch, total, req.Size)
+		}
+	case http.StatusOK:
+		// 服务端忽略了Range请求头,或者If-Range校验失败返回了完整内容
+		if offset > 0 {
+			log.Debug("download %s server ignored range, restart from 0", req.Url)
+		}
+		offset = 0
+	case http.StatusRequestedRangeNotSatisfiable:
+		return response, offset, 0, errRangeNotSatisfiable
+	default:
+		return response, offset, 0, fmt.Errorf("http status code: %d", response.StatusCode)
+	}
+
+	if err := file.Truncate(offset); err != nil {
+		return response, offset, 0, fmt.Errorf("Truncate: %w", err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return response, offset, 0, fmt.Errorf("Seek: %w", err)
+	}
+
+	state.ETag = response.Header.Get("ETag")
+	state.LastModified = response.Header.Get("Last-Modified")
+	if err := savePartState(req.StateName(), state); err != nil {
+		return response, offset, 0, fmt.Errorf("savePartState: %w", err)
+	}
+
+	var body io.Reader = response.Body
+	if bar != nil {
+		bar.SetCurrent(offset)
+		body = bar.NewProxyReader(response.Body)
+	}
+	n, err := io.Copy(file, body)
+	if err != nil {
+		return response, offset, n, fmt.Errorf("file transfer interrupted: %w", err)
+	}
+	if response.ContentLength >= 0 && n != response.ContentLength {
+		return response, offset, n, errors.New("file transfer interrupted")
+	}
+	return response, offset, n, nil
+}
+
+// finishPart 校验.part文件完整性,校验通过后删除状态文件并重命名为最终文件
+func (c *Client) finishPart(req *DownloadFileReq, file *os.File) error {
+	var part = req.PartName()
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("Stat: %w", err)
+	}
+	if req.Size > 0 && stat.Size() != req.Size {
+		return fmt.Errorf("%w: got=%d want=%d", ErrSizeMismatch, stat.Size(), req.Size)
+	}
+
+	if req.Md5 != "" {
+		src, err := os.Open(part)
+		if err != nil {
+			return fmt.Errorf("Open: %w", err)
+		}
+		var m = md5.New()
+		_, err = io.Copy(m, src)
+		_ = src.Close()
+		if err != nil {
+			return fmt.Errorf("md5: %w", err)
+		}
+		if got := hex.EncodeToString(m.Sum(nil)); !strings.EqualFold(got, req.Md5) {
+			// 内容已损坏,删除后下次从头下载
+			_ = file.Close()
+			_ = os.Remove(part)
+			_ = os.Remove(req.StateName())
+			return fmt.Errorf("%w: file=%s want=%s got=%s", ErrMd5Mismatch, part, req.Md5, got)
+		}
+	}
+
+	// 显示关闭文件避免Windows系统无法重命名错误: The process cannot access the file because it is being used by another process
+	if err := file.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+		return fmt.Errorf("close: %w", err)
+	}
+	if err := os.Rename(part, req.Filename); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	_ = os.Remove(req.StateName())
+	return nil
+}
+
+// loadPartState 读取状态文件,当状态文件不存在或者与本次请求资源不一致时返回nil
+func (c *Client) loadPartState(req *DownloadFileReq) *PartState {
+	data, err := os.ReadFile(req.StateName())
+	if err != nil {
+		return nil
+	}
+	var state PartState
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Warn("download state %s invalid: %s", req.StateName(), err)
+		return nil
+	}
+	if !strings.EqualFold(state.Md5, req.Md5) || state.Size != req.Size {
+		log.Debug("download state %s changed: %+v", req.StateName(), state)
+		return nil
+	}
+	return &state
+}
+
+func savePartState(filename string, state *PartState) error {
+	state.UpdatedAt = time.Now()
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// parseContentRange 解析 Content-Range: bytes 100-199/200 格式,total未知时返回-1
+func parseContentRange(value string) (start, end, total int64, err error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "bytes ") {
+		return 0, 0, 0, fmt.Errorf("invalid value: %q", value)
+	}
+	rng, size, ok := strings.Cut(strings.TrimPrefix(value, "bytes "), "/")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("invalid value: %q", value)
+	}
+	first, last, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("invalid value: %q", value)
+	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid start: %w", err)
+	}
+	if end, err = strconv.ParseInt(last, 10, 64); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid end: %w", err)
+	}
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid size: %w", err)
+		}
+	}
+	return start, end, total, nil
+}
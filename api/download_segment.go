@@ -0,0 +1,300 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/cheggaaa/pb/v3"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// minSegmentSize 单个分段最小字节数,文件过小时分段下载反而更慢
+	minSegmentSize = 1 << 20
+	// maxSegments 最大分段数量
+	maxSegments = 16
+	// segmentRetry 单个分段失败后的重试次数
+	segmentRetry = 3
+)
+
+var (
+	// errRangeUnsupported 服务端不支持Range请求,无法分段下载
+	errRangeUnsupported = errors.New("range unsupported")
+	// errRemoteChanged 远端文件的ETag或者Last-Modified发生变化,已下载的分段不能继续使用
+	errRemoteChanged = errors.New("remote file changed")
+)
+
+// PartSegment 分段下载中的一个分段,表示闭区间[Start, End]
+type PartSegment struct {
+	Start   int64 `json:"start"`
+	End     int64 `json:"end"`
+	Written int64 `json:"written"`
+}
+
+// Remain 分段剩余未下载的字节数
+func (s *PartSegment) Remain() int64 {
+	return s.End - s.Start + 1 - atomic.LoadInt64(&s.Written)
+}
+
+// splitSegments 将[offset, size)拆分为n个分段,[0, offset)视为已下载完成
+func splitSegments(offset, size int64, n int) []*PartSegment {
+	if n > maxSegments {
+		n = maxSegments
+	}
+	var (
+		remain = size - offset
+		step   = remain / int64(n)
+		list   = make([]*PartSegment, 0, n+1)
+	)
+	if step < minSegmentSize {
+		step = minSegmentSize
+	}
+	if offset > 0 {
+		list = append(list, &PartSegment{Start: 0, End: offset - 1, Written: offset})
+	}
+	for start := offset; start < size; start += step {
+		end := start + step - 1
+		// 最后一段包含剩余的所有字节,避免产生过小的分段
+		if end >= size-1 || size-end-1 < step {
+			end = size - 1
+		}
+		list = append(list, &PartSegment{Start: start, End: end})
+		if end == size-1 {
+			break
+		}
+	}
+	return list
+}
+
+// downloadSegments 多连接分段下载.文件预先分配为完整大小,各分段使用Range请求并发写入各自的位置,
+// 分段失败时单独重试,各分段进度保存在状态文件中以便中断后继续下载。
+// 返回开始时已完成的字节数以及本次写入的字节数
+func (c *Client) downloadSegments(ctx context.Context, req *DownloadFileReq, file *os.File, state *PartState, offset int64, bar *pb.ProgressBar) (*http.Response, int64, int64, error) {
+	if len(state.Segments) == 0 {
+		state.Segments = splitSegments(offset, req.Size, req.Segments)
+	}
+	if err := file.Truncate(req.Size); err != nil {
+		return nil, 0, 0, fmt.Errorf("Truncate: %w", err)
+	}
+
+	var (
+		mu    sync.Mutex
+		first *http.Response
+		done  int64
+		total atomic.Int64
+		save  = func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			return savePartState(req.StateName(), state.snapshot())
+		}
+		validator = &segmentValidator{mu: &mu, state: state}
+	)
+	for _, seg := range state.Segments {
+		done += atomic.LoadInt64(&seg.Written)
+	}
+	if bar != nil {
+		bar.SetCurrent(done)
+	}
+	if err := save(); err != nil {
+		return nil, done, 0, fmt.Errorf("savePartState: %w", err)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	for i, seg := range state.Segments {
+		if seg.Remain() <= 0 {
+			continue
+		}
+		g.Go(func() error {
+			var err error
+			for attempt := 0; attempt <= segmentRetry; attempt++ {
+				if attempt > 0 {
+					log.Debug("download %s segment #%d retry %d: %s", req.Url, i, attempt, err)
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(time.Duration(attempt) * time.Second):
+					}
+				}
+				var (
+					resp *http.Response
+					n    int64
+				)
+				resp, n, err = c.downloadSegment(ctx, req, file, validator, seg, bar)
+				total.Add(n)
+				if resp != nil {
+					mu.Lock()
+					if first == nil {
+						first = resp
+					}
+					mu.Unlock()
+				}
+				if serr := save(); serr != nil {
+					log.Warn("download %s save state: %s", req.Url, serr)
+				}
+				if err == nil || errors.Is(err, errRangeUnsupported) || errors.Is(err, errRemoteChanged) || ctx.Err() != nil {
+					return err
+				}
+			}
+			return fmt.Errorf("segment #%d [%d-%d]: %w", i, seg.Start, seg.End, err)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return first, done, total.Load(), err
+	}
+	return first, done, total.Load(), nil
+}
+
+// downloadSegment 下载单个分段剩余的部分
+func (c *Client) downloadSegment(ctx context.Context, req *DownloadFileReq, file *os.File, validator *segmentValidator, seg *PartSegment, bar *pb.ProgressBar) (*http.Response, int64, error) {
+	var start = seg.Start + atomic.LoadInt64(&seg.Written)
+	request, err := c.newDownloadRequest(ctx, req.Url, req.Headers, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	request.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, seg.End))
+	request.Header.Set("Accept-Encoding", "identity")
+	// 当远端文件发生变化时服务端会返回完整内容(200),从而避免拼接出错误的文件
+	var ifRange = validator.ifRange()
+	if ifRange != "" {
+		request.Header.Set("If-Range", ifRange)
+	}
+
+	response, err := c.cli.GetClient().Do(request)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		begin, _, total, err := parseContentRange(response.Header.Get("Content-Range"))
+		if err != nil {
+			return response, 0, fmt.Errorf("Content-Range: %w", err)
+		}
+		if begin != start {
+			return response, 0, fmt.Errorf("unexpected Content-Range start %d want %d", begin, start)
+		}
+		if total > 0 && total != req.Size {
+			return response, 0, fmt.Errorf("%w: remote=%d want=%d", ErrSizeMismatch, total, req.Size)
+		}
+		if err := validator.check(response); err != nil {
+			return response, 0, err
+		}
+	case http.StatusOK:
+		if ifRange != "" {
+			return response, 0, fmt.Errorf("%w: If-Range %s", errRemoteChanged, ifRange)
+		}
+		return response, 0, errRangeUnsupported
+	default:
+		return response, 0, fmt.Errorf("http status code: %d", response.StatusCode)
+	}
+
+	var w = &segmentWriter{file: file, seg: seg, bar: bar}
+	n, err := io.Copy(w, io.LimitReader(response.Body, seg.Remain()))
+	if err != nil {
+		return response, n, fmt.Errorf("file transfer interrupted: %w", err)
+	}
+	if seg.Remain() > 0 {
+		return response, n, errors.New("file transfer interrupted")
+	}
+	return response, n, nil
+}
+
+// segmentValidator 校验各分段是否来自远端同一个文件,与状态文件的保存共用同一把锁
+type segmentValidator struct {
+	mu    *sync.Mutex
+	state *PartState
+}
+
+// ifRange 返回续传时使用的If-Range请求头,优先使用ETag
+func (v *segmentValidator) ifRange() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.state.ETag != "" {
+		return v.state.ETag
+	}
+	return v.state.LastModified
+}
+
+// check 记录第一个206响应的ETag以及Last-Modified,之后的响应与记录不一致时说明远端文件已经变化
+func (v *segmentValidator) check(resp *http.Response) error {
+	var (
+		etag     = resp.Header.Get("ETag")
+		modified = resp.Header.Get("Last-Modified")
+	)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.state.ETag == "" && v.state.LastModified == "" {
+		v.state.ETag, v.state.LastModified = etag, modified
+		return nil
+	}
+	if v.state.ETag != "" && etag != v.state.ETag {
+		return fmt.Errorf("%w: ETag %s want %s", errRemoteChanged, etag, v.state.ETag)
+	}
+	if v.state.ETag == "" && modified != v.state.LastModified {
+		return fmt.Errorf("%w: Last-Modified %s want %s", errRemoteChanged, modified, v.state.LastModified)
+	}
+	return nil
+}
+
+// segmentWriter 将数据写入分段对应的文件位置并记录进度
+type segmentWriter struct {
+	file *os.File
+	seg  *PartSegment
+	bar  *pb.ProgressBar
+}
+
+func (w *segmentWriter) Write(p []byte) (int, error) {
+	n, err := w.file.WriteAt(p, w.seg.Start+atomic.LoadInt64(&w.seg.Written))
+	atomic.AddInt64(&w.seg.Written, int64(n))
+	if w.bar != nil {
+		w.bar.Add(n)
+	}
+	return n, err
+}
+
+// snapshot 复制一份状态用于持久化,避免与正在下载的分段产生数据竞争
+func (s *PartState) snapshot() *PartState {
+	var cp = *s
+	cp.Segments = make([]*PartSegment, 0, len(s.Segments))
+	for _, seg := range s.Segments {
+		cp.Segments = append(cp.Segments, &PartSegment{
+			Start:   seg.Start,
+			End:     seg.End,
+			Written: atomic.LoadInt64(&seg.Written),
+		})
+	}
+	return &cp
+}
@@ -0,0 +1,240 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSegments(t *testing.T) {
+	var list = splitSegments(0, 10*minSegmentSize+3, 3)
+	assert.Len(t, list, 3)
+	assert.Equal(t, int64(0), list[0].Start)
+	assert.Equal(t, int64(10*minSegmentSize+2), list[2].End)
+	for i := 1; i < len(list); i++ {
+		assert.Equal(t, list[i-1].End+1, list[i].Start)
+	}
+
+	// 已下载的部分作为一个完成的分段
+	list = splitSegments(100, 4*minSegmentSize, 2)
+	assert.Equal(t, &PartSegment{Start: 0, End: 99, Written: 100}, list[0])
+	assert.Equal(t, int64(100), list[1].Start)
+
+	// 文件较小时分段数量会减少
+	list = splitSegments(0, 2*minSegmentSize, 16)
+	assert.Len(t, list, 2)
+}
+
+func TestDownloadFileSegments(t *testing.T) {
+	var (
+		data, sum = testContent(3*minSegmentSize + 123)
+		mu        sync.Mutex
+		ranges    = make(map[string]int)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		var rng = r.Header.Get("Range")
+		ranges[rng]++
+		count := ranges[rng]
+		mu.Unlock()
+		// 第二个分段第一次请求失败,验证分段单独重试
+		if rng == "bytes=1048617-2097233" && count == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		http.ServeContent(w, r, "song.flac", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	var (
+		cli  = newTestClient(t)
+		dest = filepath.Join(t.TempDir(), "song.flac")
+		req  = &DownloadFileReq{Url: srv.URL, Filename: dest, Md5: sum, Size: int64(len(data)), Segments: 3}
+	)
+	resp, err := cli.DownloadFile(context.TODO(), req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), resp.Written)
+	assert.Len(t, ranges, 3)
+	assert.Equal(t, 2, ranges["bytes=1048617-2097233"])
+
+	got, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.NoFileExists(t, req.StateName())
+}
+
+func TestDownloadFileSegmentsResume(t *testing.T) {
+	var (
+		data, sum = testContent(2*minSegmentSize + 10)
+		mu        sync.Mutex
+		ranges    []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		http.ServeContent(w, r, "song.flac", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	var (
+		cli  = newTestClient(t)
+		dest = filepath.Join(t.TempDir(), "song.flac")
+		req  = &DownloadFileReq{Url: srv.URL, Filename: dest, Md5: sum, Size: int64(len(data))}
+		mid  = int64(minSegmentSize)
+	)
+	// 上一次分段下载时第一段已完成,第二段下载了10个字节
+	var part = make([]byte, len(data))
+	copy(part[:mid+10], data[:mid+10])
+	assert.NoError(t, os.WriteFile(req.PartName(), part, 0644))
+	assert.NoError(t, savePartState(req.StateName(), &PartState{
+		Md5:  sum,
+		Size: req.Size,
+		Segments: []*PartSegment{
+			{Start: 0, End: mid - 1, Written: mid},
+			{Start: mid, End: req.Size - 1, Written: 10},
+		},
+	}))
+
+	resp, err := cli.DownloadFile(context.TODO(), req, nil)
+	assert.NoError(t, err)
+	assert.True(t, resp.Resumed)
+	assert.Equal(t, mid+10, resp.Offset)
+	assert.Equal(t, []string{"bytes=1048586-2097161"}, ranges)
+
+	got, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+}
+
+func TestDownloadFileSegmentsRangeUnsupported(t *testing.T) {
+	var data, sum = testContent(2*minSegmentSize + 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	var (
+		cli  = newTestClient(t)
+		dest = filepath.Join(t.TempDir(), "song.flac")
+		req  = &DownloadFileReq{Url: srv.URL, Filename: dest, Md5: sum, Size: int64(len(data)), Segments: 4}
+	)
+	_, err := cli.DownloadFile(context.TODO(), req, nil)
+	assert.NoError(t, err)
+
+	got, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+}
+
+func TestDownloadFileSegmentsRemoteChanged(t *testing.T) {
+	var tests = []struct {
+		name string
+		// etag 第n次请求时服务端返回的ETag
+		etag func(n int) string
+		// ignoreIfRange 服务端忽略If-Range请求头,始终返回206
+		ignoreIfRange bool
+		// resume 是否存在上一次ETag为"v1"时下载了一部分的状态文件
+		resume bool
+	}{
+		{name: "if-range", etag: func(int) string { return `"v2"` }, resume: true},
+		{name: "if-range ignored", etag: func(int) string { return `"v2"` }, ignoreIfRange: true, resume: true},
+		{name: "changed while downloading", etag: func(n int) string { return fmt.Sprintf(`"v%d"`, n) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				old, _   = testContent(2*minSegmentSize + 10)
+				data     = bytes.ToUpper(old)
+				sum      = md5.Sum(data)
+				mu       sync.Mutex
+				count    int
+				ranges   []string
+				ifRanges []string
+				mid      = int64(minSegmentSize)
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				count++
+				ranges = append(ranges, r.Header.Get("Range"))
+				ifRanges = append(ifRanges, r.Header.Get("If-Range"))
+				w.Header().Set("ETag", tt.etag(count))
+				mu.Unlock()
+				if tt.ignoreIfRange {
+					r.Header.Del("If-Range")
+				}
+				http.ServeContent(w, r, "song.flac", time.Time{}, bytes.NewReader(data))
+			}))
+			defer srv.Close()
+
+			var (
+				cli  = newTestClient(t)
+				dest = filepath.Join(t.TempDir(), "song.flac")
+				req  = &DownloadFileReq{Url: srv.URL, Filename: dest, Md5: hex.EncodeToString(sum[:]), Size: int64(len(data)), Segments: 2}
+			)
+			if tt.resume {
+				// 上一次下载的是旧文件,第一段已完成,第二段下载了10个字节
+				var part = make([]byte, len(data))
+				copy(part[:mid+10], old[:mid+10])
+				assert.NoError(t, os.WriteFile(req.PartName(), part, 0644))
+				assert.NoError(t, savePartState(req.StateName(), &PartState{
+					Md5:  req.Md5,
+					Size: req.Size,
+					ETag: `"v1"`,
+					Segments: []*PartSegment{
+						{Start: 0, End: mid - 1, Written: mid},
+						{Start: mid, End: req.Size - 1, Written: 10},
+					},
+				}))
+			}
+
+			_, err := cli.DownloadFile(context.TODO(), req, nil)
+			assert.NoError(t, err)
+			if tt.resume {
+				assert.Equal(t, `"v1"`, ifRanges[0])
+			}
+			// 发现远端文件变化后丢弃已下载的内容从头下载
+			assert.Equal(t, "bytes=0-", ranges[len(ranges)-1])
+			assert.Empty(t, ifRanges[len(ifRanges)-1])
+
+			got, err := os.ReadFile(dest)
+			assert.NoError(t, err)
+			assert.Equal(t, data, got)
+			assert.NoFileExists(t, req.StateName())
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+	cli, err := NewClient(&Config{
+		Timeout: 10 * time.Second,
+		Cookie: cookie.Config{
+			Filepath: filepath.Join(t.TempDir(), "cookie.json"),
+			Interval: 0,
+		},
+	}, log.Default)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = cli.Close(context.TODO()) })
+	return cli
+}
+
+func testContent(size int) ([]byte, string) {
+	var data = bytes.Repeat([]byte("netease-cloud-music"), size/19+1)[:size]
+	sum := md5.Sum(data)
+	return data, hex.EncodeToString(sum[:])
+}
+
+func TestDownloadFileResume(t *testing.T) {
+	var (
+		data, sum = testContent(64 * 1024)
+		ranges    []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "song.flac", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	var (
+		cli  = newTestClient(t)
+		dest = filepath.Join(t.TempDir(), "song.flac")
+		req  = &DownloadFileReq{Url: srv.URL, Filename: dest, Md5: sum, Size: int64(len(data))}
+	)
+	// 模拟上一次下载中断
+	assert.NoError(t, os.WriteFile(req.PartName(), data[:1000], 0644))
+	assert.NoError(t, savePartState(req.StateName(), &PartState{Md5: sum, Size: req.Size}))
+
+	resp, err := cli.DownloadFile(context.TODO(), req, nil)
+	assert.NoError(t, err)
+	assert.True(t, resp.Resumed)
+	assert.Equal(t, int64(1000), resp.Offset)
+	assert.Equal(t, int64(len(data)-1000), resp.Written)
+	assert.Equal(t, []string{"bytes=1000-"}, ranges)
+
+	got, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.NoFileExists(t, req.PartName())
+	assert.NoFileExists(t, req.StateName())
+}
+
+func TestDownloadFileRangeIgnored(t *testing.T) {
+	var data, sum = testContent(4096)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 不支持Range请求头的服务端
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	var (
+		cli  = newTestClient(t)
+		dest = filepath.Join(t.TempDir(), "song.mp3")
+		req  = &DownloadFileReq{Url: srv.URL, Filename: dest, Md5: sum, Size: int64(len(data))}
+	)
+	assert.NoError(t, os.WriteFile(req.PartName(), data[:100], 0644))
+	assert.NoError(t, savePartState(req.StateName(), &PartState{Md5: sum, Size: req.Size}))
+
+	resp, err := cli.DownloadFile(context.TODO(), req, nil)
+	assert.NoError(t, err)
+	assert.False(t, resp.Resumed)
+	assert.Equal(t, int64(len(data)), resp.Written)
+
+	got, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+}
+
+func TestDownloadFileStateChanged(t *testing.T) {
+	var (
+		data, sum = testContent(2048)
+		ranges    []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "song.flac", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	var (
+		cli  = newTestClient(t)
+		dest = filepath.Join(t.TempDir(), "song.flac")
+		req  = &DownloadFileReq{Url: srv.URL, Filename: dest, Md5: sum, Size: int64(len(data))}
+	)
+	// 状态文件中记录的是其他音质的文件,不能续传
+	assert.NoError(t, os.WriteFile(req.PartName(), []byte("other level"), 0644))
+	assert.NoError(t, savePartState(req.StateName(), &PartState{Md5: "other", Size: 100}))
+
+	_, err := cli.DownloadFile(context.TODO(), req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bytes=0-"}, ranges)
+
+	got, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+}
+
+func TestDownloadFileMd5Mismatch(t *testing.T) {
+	var data, _ = testContent(1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "song.flac", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	var (
+		cli  = newTestClient(t)
+		dest = filepath.Join(t.TempDir(), "song.flac")
+		req  = &DownloadFileReq{Url: srv.URL, Filename: dest, Md5: "d41d8cd98f00b204e9800998ecf8427e", Size: int64(len(data))}
+	)
+	_, err := cli.DownloadFile(context.TODO(), req, nil)
+	assert.ErrorIs(t, err, ErrMd5Mismatch)
+	assert.NoFileExists(t, dest)
+	assert.NoFileExists(t, req.PartName())
+	assert.NoFileExists(t, req.StateName())
+}
+
+func TestParseContentRange(t *testing.T) {
+	start, end, total, err := parseContentRange("bytes 100-199/200")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{100, 199, 200}, []int64{start, end, total})
+
+	_, _, total, err = parseContentRange("bytes 0-9/*")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), total)
+
+	_, _, _, err = parseContentRange("items 0-9/10")
+	assert.Error(t, err)
+}
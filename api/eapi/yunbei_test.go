@@ -35,5 +35,6 @@ func TestYunBeiInSign(t *testing.T) {
 	}
 	got, err := cli.YunBeiSignIn(ctx, &req)
 	assert.NoError(t, err)
-	t.Logf("YunBeiSignIn: %+v\n", got)
+	// -2: 重复签到
+	assert.Contains(t, []int64{200, -2}, got.Code)
 }
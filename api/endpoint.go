@@ -0,0 +1,199 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// DefaultUploadURL 云盘上传时查询上传节点失败使用的NOS上传地址
+const DefaultUploadURL = "http://59.111.242.121"
+
+// HostOverride 将指定域名的请求转发到其他地址
+type HostOverride struct {
+	Host string `json:"host" yaml:"host"` // eg: interface3.music.163.com 以.开头时匹配所有子域名,eg: .music.163.com
+	URL  string `json:"url" yaml:"url"`   // eg: https://proxy.example.com/ncm
+}
+
+// EndpointConfig 接口地址配置,用于反向代理、本地模拟服务以及接口域名迁移.
+// 请求地址按照 Config.BaseURL > Hosts > 加密方式对应地址 的优先级替换,替换发生在传输层,
+// 因此cookie仍然按照接口定义的原始域名保存,原始域名通过X-Forwarded-Host请求头传递.
+type EndpointConfig struct {
+	// WEAPI weapi接口使用的地址,为空时使用接口定义的默认域名,下同. eg: https://music.163.com
+	WEAPI string `json:"weapi" yaml:"weapi"`
+	// EAPI eapi接口使用的地址 eg: https://interface3.music.163.com
+	EAPI string `json:"eapi" yaml:"eapi"`
+	// Linux linux接口使用的地址
+	Linux string `json:"linux" yaml:"linux"`
+	// API 明文api接口使用的地址
+	API string `json:"api" yaml:"api"`
+	// RealIP 海外访问时设置为国内ip,通过X-Real-IP以及X-Forwarded-For请求头传递,可以避免部分接口返回无版权
+	RealIP string `json:"realIP" yaml:"realIP"`
+	// Upload 云盘上传时查询上传节点(lbs)失败使用的NOS上传地址,为空时使用 DefaultUploadURL.
+	// 该地址同样会经过 Hosts 替换,eg: host: 59.111.242.121
+	Upload string `json:"upload" yaml:"upload"`
+	// Hosts 域名替换
+	Hosts []HostOverride `json:"hosts" yaml:"hosts"`
+}
+
+func (c *EndpointConfig) Validate() error {
+	for mode, addr := range c.modes() {
+		if addr == "" {
+			continue
+		}
+		if _, err := parseURL(addr); err != nil {
+			return fmt.Errorf("endpoint.%s: %w", mode, err)
+		}
+	}
+	if c.Upload != "" {
+		if _, err := parseURL(c.Upload); err != nil {
+			return fmt.Errorf("endpoint.upload: %w", err)
+		}
+	}
+	for _, h := range c.Hosts {
+		if h.Host == "" {
+			return errors.New("endpoint.hosts host is empty")
+		}
+		if _, err := parseURL(h.URL); err != nil {
+			return fmt.Errorf("endpoint.hosts %s: %w", h.Host, err)
+		}
+	}
+	return nil
+}
+
+func (c *EndpointConfig) modes() map[CryptoMode]string {
+	return map[CryptoMode]string{
+		CryptoModeWEAPI: c.WEAPI,
+		CryptoModeEAPI:  c.EAPI,
+		CryptoModeLinux: c.Linux,
+		CryptoModeAPI:   c.API,
+	}
+}
+
+// parseURL 解析替换的目标地址
+func parseURL(addr string) (*neturl.URL, error) {
+	uri, err := neturl.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	if uri.Scheme != "http" && uri.Scheme != "https" {
+		return nil, fmt.Errorf("%q scheme must be http or https", addr)
+	}
+	if uri.Host == "" {
+		return nil, fmt.Errorf("%q host is empty", addr)
+	}
+	return uri, nil
+}
+
+type hostTarget struct {
+	host string
+	url  *neturl.URL
+}
+
+// resolver 根据配置计算请求实际发送的地址
+type resolver struct {
+	base   *neturl.URL
+	modes  map[CryptoMode]*neturl.URL
+	hosts  []hostTarget
+	realIP string
+}
+
+// newResolver 创建resolver,配置需要先经过校验.没有任何替换配置时返回nil
+func newResolver(cfg *Config) *resolver {
+	var r = resolver{
+		modes:  make(map[CryptoMode]*neturl.URL),
+		realIP: cfg.Endpoint.RealIP,
+	}
+	if cfg.BaseURL != "" {
+		r.base, _ = parseURL(cfg.BaseURL)
+	}
+	for mode, addr := range cfg.Endpoint.modes() {
+		if addr != "" {
+			r.modes[mode], _ = parseURL(addr)
+		}
+	}
+	for _, h := range cfg.Endpoint.Hosts {
+		uri, _ := parseURL(h.URL)
+		r.hosts = append(r.hosts, hostTarget{host: strings.ToLower(h.Host), url: uri})
+	}
+	if r.base == nil && len(r.modes) == 0 && len(r.hosts) == 0 && r.realIP == "" {
+		return nil
+	}
+	return &r
+}
+
+// target 返回替换后的目标地址,不需要替换时返回nil
+func (r *resolver) target(uri *neturl.URL, mode CryptoMode) *neturl.URL {
+	if r.base != nil {
+		return r.base
+	}
+	var host = strings.ToLower(uri.Hostname())
+	for _, h := range r.hosts {
+		if h.host == host || (strings.HasPrefix(h.host, ".") && strings.HasSuffix(host, h.host)) {
+			return h.url
+		}
+	}
+	return r.modes[mode]
+}
+
+type cryptoModeCtx struct{}
+
+// withCryptoMode 将请求的加密方式传递给传输层,用于选择加密方式对应的地址
+func withCryptoMode(ctx context.Context, mode CryptoMode) context.Context {
+	return context.WithValue(ctx, cryptoModeCtx{}, mode)
+}
+
+// endpointTransport 在传输层按照 resolver 替换请求地址
+type endpointTransport struct {
+	resolver *resolver
+	next     http.RoundTripper
+}
+
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		mode, _ = req.Context().Value(cryptoModeCtx{}).(CryptoMode)
+		target  = t.resolver.target(req.URL, mode)
+		r       = req.Clone(req.Context())
+	)
+	if ip := t.resolver.realIP; ip != "" {
+		r.Header.Set("X-Real-IP", ip)
+		r.Header.Set("X-Forwarded-For", ip)
+	}
+	if target != nil {
+		r.Header.Set("X-Forwarded-Host", req.URL.Host)
+		r.Host = target.Host
+		r.URL.Scheme = target.Scheme
+		r.URL.Host = target.Host
+		if prefix := strings.TrimSuffix(target.Path, "/"); prefix != "" {
+			r.URL.Path = prefix + req.URL.Path
+			r.URL.RawPath = ""
+		}
+	}
+	return t.next.RoundTrip(r)
+}
@@ -0,0 +1,131 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseURL(t *testing.T) {
+	var host, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, path = r.Header.Get("X-Forwarded-Host"), r.URL.Path
+		http.SetCookie(w, &http.Cookie{Name: "MUSIC_U", Value: "token", Path: "/"})
+		_, _ = w.Write([]byte(`{"code":200}`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(&Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+		BaseURL: srv.URL + "/proxy/",
+	}, log.Default)
+	assert.NoError(t, err)
+	defer cli.Close(context.TODO())
+
+	var reply types.RespCommon[any]
+	_, err = cli.Request(context.TODO(), "https://music.163.com/weapi/test", struct{}{}, &reply, NewOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, "music.163.com", host)
+	assert.Equal(t, "/proxy/weapi/test", path)
+
+	// cookie按照原始域名保存
+	ck, ok := cli.Cookie("https://music.163.com", "MUSIC_U")
+	assert.True(t, ok)
+	assert.Equal(t, "token", ck.Value)
+
+	assert.Error(t, (&Config{BaseURL: "127.0.0.1:8080"}).Validate())
+}
+
+func TestEndpoint(t *testing.T) {
+	var hosts = make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts <- r.Header.Get("X-Forwarded-Host") + r.URL.Path + " " + r.Header.Get("X-Real-IP")
+		_, _ = w.Write([]byte(`{"code":200}`))
+	}))
+	defer srv.Close()
+
+	var cfg = Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+		Endpoint: EndpointConfig{
+			EAPI:   srv.URL + "/eapi-proxy",
+			RealIP: "116.25.146.177",
+			Hosts:  []HostOverride{{Host: ".music.163.com", URL: srv.URL}},
+		},
+	}
+	cli, err := NewClient(&cfg, log.Default)
+	assert.NoError(t, err)
+	defer cli.Close(context.TODO())
+
+	var (
+		reply types.RespCommon[any]
+		opts  = NewOptions()
+	)
+	// 域名替换优先于加密方式对应地址
+	opts.CryptoMode = CryptoModeEAPI
+	_, err = cli.Request(context.TODO(), "https://interface.music.163.com/eapi/test", struct{}{}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "interface.music.163.com/eapi/test 116.25.146.177", <-hosts)
+
+	// 未匹配域名时使用加密方式对应地址
+	_, err = cli.Request(context.TODO(), "https://example.com/eapi/test", struct{}{}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com/eapi-proxy/eapi/test 116.25.146.177", <-hosts)
+
+	cfg.Endpoint.Hosts = append(cfg.Endpoint.Hosts, HostOverride{Host: "music.163.com"})
+	assert.Error(t, cfg.Validate())
+}
+
+func TestUploadURL(t *testing.T) {
+	var cfg = Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+	}
+	cli, err := NewClient(&cfg, log.Default)
+	assert.NoError(t, err)
+	defer cli.Close(context.TODO())
+	assert.Equal(t, DefaultUploadURL, cli.UploadURL())
+
+	cfg.Endpoint.Upload = "http://127.0.0.1:8080/"
+	assert.NoError(t, cfg.Validate())
+	cli2, err := NewClient(&cfg, log.Default)
+	assert.NoError(t, err)
+	defer cli2.Close(context.TODO())
+	assert.Equal(t, "http://127.0.0.1:8080", cli2.UploadURL())
+
+	cfg.Endpoint.Upload = "127.0.0.1:8080"
+	assert.Error(t, cfg.Validate())
+}
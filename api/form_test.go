@@ -0,0 +1,178 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type formReq struct {
+	types.ReqCommon
+	Id      int64            `json:"id"`
+	Name    string           `json:"name"`
+	Ok      bool             `json:"ok"`
+	Ids     types.IntsString `json:"ids"`
+	List    []string         `json:"list"`
+	Extra   formReqExtra     `json:"extra"`
+	C       string           `json:"c"` // 本身就是json字符串的字段
+	Ignored *string          `json:"ignored"`
+	Omit    string           `json:"omit,omitempty"`
+}
+
+type formReqExtra struct {
+	Level string `json:"level"`
+	Br    int64  `json:"br"`
+}
+
+func TestEncodeForm(t *testing.T) {
+	var req = formReq{
+		ReqCommon: types.ReqCommon{CSRFToken: "token"},
+		Id:        9011496609,
+		Name:      "名字 &=?",
+		Ok:        true,
+		Ids:       types.IntsString{1, 2},
+		List:      []string{"a", "b"},
+		Extra:     formReqExtra{Level: "lossless", Br: 999000},
+		C:         `[{"id":1}]`,
+	}
+	got, err := encodeForm(&req)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"csrf_token": "token",
+		"id":         "9011496609",
+		"name":       "名字 &=?",
+		"ok":         "true",
+		"ids":        "[1,2]",
+		"list":       `["a","b"]`,
+		"extra":      `{"level":"lossless","br":999000}`,
+		"c":          `[{"id":1}]`,
+	}, got)
+
+	got, err = encodeForm(map[string]interface{}{"id": 1.5, "s": []int{1}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"id": "1.5", "s": "[1]"}, got)
+
+	_, err = encodeForm([]int{1})
+	assert.Error(t, err)
+}
+
+func TestRequestApiForm(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		form = r.PostForm
+		_, _ = w.Write([]byte(`{"code":200}`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(&Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+	}, log.Default)
+	assert.NoError(t, err)
+	defer cli.Close(context.TODO())
+
+	var (
+		reply types.RespCommon[any]
+		opts  = NewOptions()
+		req   = formReq{
+			Id:    1,
+			Name:  "a b&c",
+			Ids:   types.IntsString{3, 4},
+			Extra: formReqExtra{Level: "exhigh"},
+			C:     `{"k":"v"}`,
+		}
+	)
+	opts.CryptoMode = CryptoModeAPI
+	_, err = cli.Request(context.TODO(), srv.URL+"/api/test", &req, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), reply.Code)
+	assert.Equal(t, "1", form.Get("id"))
+	assert.Equal(t, "a b&c", form.Get("name"))
+	assert.Equal(t, "false", form.Get("ok"))
+	assert.Equal(t, "[3,4]", form.Get("ids"))
+	assert.False(t, form.Has("list"))
+	assert.Equal(t, `{"level":"exhigh","br":0}`, form.Get("extra"))
+	assert.Equal(t, `{"k":"v"}`, form.Get("c"))
+	assert.False(t, form.Has("ignored"))
+	assert.False(t, form.Has("omit"))
+}
+
+func TestRequestGetQuery(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`{"code":200}`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(&Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+	}, log.Default)
+	assert.NoError(t, err)
+	defer cli.Close(context.TODO())
+
+	var req = formReq{Id: 9011496609, Name: "a b&c", Ids: types.IntsString{1, 2}}
+	for _, mode := range []CryptoMode{CryptoModeAPI, CryptoModeLinux, CryptoModeWEAPI, CryptoModeEAPI} {
+		var (
+			reply types.RespCommon[any]
+			opts  = NewOptions()
+		)
+		opts.Method = http.MethodGet
+		opts.CryptoMode = mode
+		opts.SetQuery("from", "1")
+		_, err = cli.Request(context.TODO(), srv.URL+"/"+string(mode)+"/test", &req, &reply, opts)
+		assert.NoError(t, err, mode)
+		assert.Equal(t, int64(200), reply.Code, mode)
+		assert.Equal(t, "1", query.Get("from"), mode)
+
+		switch mode {
+		case CryptoModeAPI, CryptoModeLinux:
+			assert.Equal(t, "9011496609", query.Get("id"), mode)
+			assert.Equal(t, "a b&c", query.Get("name"), mode)
+			assert.Equal(t, "[1,2]", query.Get("ids"), mode)
+		case CryptoModeWEAPI:
+			assert.NotEmpty(t, query.Get("params"))
+			assert.NotEmpty(t, query.Get("encSecKey"))
+			assert.False(t, query.Has("id"))
+		case CryptoModeEAPI:
+			assert.NotEmpty(t, query.Get("params"))
+			assert.False(t, query.Has("id"))
+		}
+	}
+}
@@ -23,5 +23,93 @@
 
 package api
 
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// Header eapi请求参数中的header字段,其中设备相关信息同时会以cookie的形式携带
 type Header struct {
+	OSVer       string `json:"osver"`
+	DeviceId    string `json:"deviceId"`
+	AppVer      string `json:"appver"`
+	VersionCode string `json:"versioncode"`
+	MobileName  string `json:"mobilename"`
+	BuildVer    string `json:"buildver"`
+	Resolution  string `json:"resolution"`
+	CSRF        string `json:"__csrf"`
+	OS          string `json:"os"`
+	Channel     string `json:"channel"`
+	RequestId   string `json:"requestId"`
+	MusicU      string `json:"MUSIC_U,omitempty"`
+	MusicA      string `json:"MUSIC_A,omitempty"`
+}
+
+// NewHeader 根据设备信息生成header
+func NewHeader(p Profile, deviceId string) *Header {
+	h := Header{
+		OSVer:       p.OSVer,
+		DeviceId:    deviceId,
+		AppVer:      p.AppVer,
+		VersionCode: p.VersionCode,
+		MobileName:  p.MobileName,
+		BuildVer:    p.BuildVer,
+		Resolution:  p.Resolution,
+		OS:          p.OS,
+		Channel:     p.Channel,
+		RequestId:   fmt.Sprintf("%d_%04d", time.Now().UnixMilli(), rand.Intn(1000)),
+	}
+	return &h
+}
+
+// Cookies 设备相关的cookie,登录态相关的cookie由cookiejar携带
+func (h *Header) Cookies() []*http.Cookie {
+	var (
+		kv = [][2]string{
+			{"osver", h.OSVer},
+			{"deviceId", h.DeviceId},
+			{"appver", h.AppVer},
+			{"versioncode", h.VersionCode},
+			{"mobilename", h.MobileName},
+			{"buildver", h.BuildVer},
+			{"resolution", h.Resolution},
+			{"os", h.OS},
+			{"channel", h.Channel},
+			{"requestId", h.RequestId},
+		}
+		cookies = make([]*http.Cookie, 0, len(kv))
+	)
+	for _, v := range kv {
+		if v[1] == "" {
+			continue
+		}
+		cookies = append(cookies, &http.Cookie{Name: v[0], Value: v[1]})
+	}
+	return cookies
+}
+
+// withParams 将params中请求参数不存在的字段放入请求参数中,请求参数不是json对象时则原样返回
+func withParams(req interface{}, params map[string]interface{}) (interface{}, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil || m == nil {
+		return req, nil
+	}
+	for k, v := range params {
+		if _, ok := m[k]; ok {
+			continue
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("json.Marshal: %w", err)
+		}
+		m[k] = value
+	}
+	return m, nil
 }
@@ -0,0 +1,217 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	neturl "net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
+)
+
+// Call 一次接口调用的上下文,中间件可以在调用下一个 Handler 前修改请求,调用后读取结果
+type Call struct {
+	Id      string            // 请求id,由 RequestId 中间件生成
+	Url     string            // 请求地址
+	Options *Options          // 请求选项,修改后只对本次调用生效
+	Request interface{}       // 加密前的请求参数
+	Form    map[string]string // 加密后的表单或查询参数
+	Attempt int               // 第几次重试,从0开始
+
+	Response *resty.Response // 原始响应
+	Body     []byte          // 解密后的响应内容
+	Reply    interface{}     // 解码后的响应结构体
+}
+
+// Handler 处理一次接口调用
+type Handler func(ctx context.Context, call *Call) error
+
+// Middleware 中间件
+type Middleware func(next Handler) Handler
+
+// Chain 将多个中间件组合为一个,第一个中间件位于最外层
+func Chain(m ...Middleware) Middleware {
+	return func(next Handler) Handler {
+		for i := len(m) - 1; i >= 0; i-- {
+			next = m[i](next)
+		}
+		return next
+	}
+}
+
+// MiddlewareConfig 内置中间件配置
+type MiddlewareConfig struct {
+	// Dump 通过日志输出每次请求的明文参数、加密表单以及响应内容
+	Dump bool `json:"dump" yaml:"dump"`
+	// Metrics 统计每个接口的调用次数、失败次数以及耗时
+	Metrics bool `json:"metrics" yaml:"metrics"`
+	// RequestId 注入请求id的header名称,例如: X-Request-Id 为空则不注入
+	RequestId string `json:"requestId" yaml:"requestId"`
+}
+
+func (c MiddlewareConfig) middlewares(client *Client) []Middleware {
+	var list []Middleware
+	if c.RequestId != "" {
+		list = append(list, RequestId(c.RequestId))
+	}
+	if c.Metrics {
+		client.metrics = NewMetrics()
+		list = append(list, client.metrics.Middleware())
+	}
+	if c.Dump {
+		list = append(list, Dump())
+	}
+	return list
+}
+
+// RequestId 为每次调用生成请求id并放入header中,header已存在时使用已有的值
+func RequestId(header string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) error {
+			if id, ok := call.Options.Headers[header]; ok && id != "" {
+				call.Id = id
+			} else {
+				if call.Id == "" {
+					call.Id = uuid.NewString()
+				}
+				call.Options.SetHeader(header, call.Id)
+			}
+			return next(ctx, call)
+		}
+	}
+}
+
+// Dump 通过debug级别日志输出请求以及响应内容,用于调试. 内容包含cookie以及表单等敏感信息
+func Dump() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) error {
+			var start = time.Now()
+			err := next(ctx, call)
+
+			request, _ := json.Marshal(call.Request)
+			var status int
+			if call.Response != nil {
+				status = call.Response.StatusCode()
+			}
+			log.Debug("[dump] id=%s %s %s crypto=%s attempt=%d cost=%s\nrequest: %s\nform: %v\nstatus: %d\nresponse: %s\nerror: %v",
+				call.Id, call.Options.Method, call.Url, call.Options.CryptoMode, call.Attempt, time.Since(start),
+				request, call.Form, status, call.Body, err)
+			return err
+		}
+	}
+}
+
+// MetricsStat 单个接口的调用统计
+type MetricsStat struct {
+	Requests   int64         // 调用次数
+	Failures   int64         // 失败次数,包含请求出错以及http状态码不为200
+	Latency    time.Duration // 累计耗时
+	MaxLatency time.Duration // 最大耗时
+}
+
+// Avg 平均耗时
+func (s MetricsStat) Avg() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.Latency / time.Duration(s.Requests)
+}
+
+// Metrics 按接口路径统计调用情况
+type Metrics struct {
+	mu    sync.Mutex
+	stats map[string]*MetricsStat
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{stats: make(map[string]*MetricsStat)}
+}
+
+// Middleware 返回统计中间件
+func (m *Metrics) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) error {
+			var start = time.Now()
+			err := next(ctx, call)
+			failed := err != nil || call.Response == nil || call.Response.StatusCode() != http.StatusOK
+			m.observe(endpoint(call.Url), time.Since(start), failed)
+			return err
+		}
+	}
+}
+
+func (m *Metrics) observe(endpoint string, cost time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stat, ok := m.stats[endpoint]
+	if !ok {
+		stat = &MetricsStat{}
+		m.stats[endpoint] = stat
+	}
+	stat.Requests++
+	if failed {
+		stat.Failures++
+	}
+	stat.Latency += cost
+	stat.MaxLatency = max(stat.MaxLatency, cost)
+}
+
+// Snapshot 获取当前统计结果,key为接口路径
+func (m *Metrics) Snapshot() map[string]MetricsStat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var snapshot = make(map[string]MetricsStat, len(m.stats))
+	for k, v := range m.stats {
+		snapshot[k] = *v
+	}
+	return snapshot
+}
+
+// Endpoints 返回所有统计过的接口路径
+func (m *Metrics) Endpoints() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list = make([]string, 0, len(m.stats))
+	for k := range m.stats {
+		list = append(list, k)
+	}
+	slices.Sort(list)
+	return list
+}
+
+func endpoint(url string) string {
+	uri, err := neturl.Parse(url)
+	if err != nil {
+		return url
+	}
+	return uri.Host + uri.Path
+}
@@ -0,0 +1,128 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) error {
+				order = append(order, name+":before")
+				err := next(ctx, call)
+				order = append(order, name+":after")
+				return err
+			}
+		}
+	}
+	var h = Chain(mark("a"), mark("b"))(func(ctx context.Context, call *Call) error {
+		order = append(order, "handler")
+		return errors.New("failed")
+	})
+	assert.Error(t, h(context.TODO(), &Call{}))
+	assert.Equal(t, []string{"a:before", "b:before", "handler", "b:after", "a:after"}, order)
+}
+
+func TestMiddleware(t *testing.T) {
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		if r.URL.Path == "/api/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_, _ = w.Write([]byte(`{"code":200}`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(&Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+		Middleware: MiddlewareConfig{
+			Dump:      true,
+			Metrics:   true,
+			RequestId: "X-Request-Id",
+		},
+	}, log.Default)
+	assert.NoError(t, err)
+	defer cli.Close(context.TODO())
+
+	var calls []Call
+	cli.Use(func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) error {
+			// 调用前修改请求参数以及header
+			call.Request = map[string]string{"id": "2"}
+			call.Options.SetHeader("X-Test", "1")
+			err := next(ctx, call)
+			calls = append(calls, *call)
+			return err
+		}
+	})
+
+	var (
+		reply types.RespCommon[any]
+		opts  = NewOptions()
+	)
+	opts.CryptoMode = CryptoModeAPI
+	_, err = cli.Request(context.TODO(), srv.URL+"/api/ok", map[string]string{"id": "1"}, &reply, opts)
+	assert.NoError(t, err)
+	_, err = cli.Request(context.TODO(), srv.URL+"/api/fail", map[string]string{"id": "1"}, &reply, opts)
+	assert.Error(t, err)
+
+	// 中间件修改的header不影响调用方的Options
+	assert.Empty(t, opts.Headers)
+	assert.Equal(t, "1", header.Get("X-Test"))
+	assert.NotEmpty(t, header.Get("X-Request-Id"))
+
+	assert.Len(t, calls, 2)
+	assert.Equal(t, map[string]string{"id": "2"}, calls[0].Form)
+	assert.Equal(t, `{"code":200}`, string(calls[0].Body))
+	assert.Equal(t, http.StatusOK, calls[0].Response.StatusCode())
+	assert.Equal(t, int64(200), calls[0].Reply.(*types.RespCommon[any]).Code)
+	assert.NotEmpty(t, calls[0].Id)
+	assert.NotEqual(t, calls[0].Id, calls[1].Id)
+
+	var (
+		snapshot = cli.Metrics().Snapshot()
+		host     = srv.Listener.Addr().String()
+	)
+	assert.Equal(t, []string{host + "/api/fail", host + "/api/ok"}, cli.Metrics().Endpoints())
+	assert.Equal(t, int64(1), snapshot[host+"/api/ok"].Requests)
+	assert.Equal(t, int64(0), snapshot[host+"/api/ok"].Failures)
+	assert.Equal(t, int64(1), snapshot[host+"/api/fail"].Failures)
+}
@@ -37,8 +37,14 @@ const (
 type Options struct {
 	Method     string
 	CryptoMode CryptoMode
-	Headers    map[string]string
-	Cookies    []*http.Cookie
+	Device     Device // 模拟的设备,为空时使用客户端默认设备
+	// EncryptResponse eapi接口返回内容是否加密,为true时请求参数中会携带e_r,返回内容会自动解密
+	EncryptResponse bool
+	// CheckCode 返回内容中code不为200时返回 types.Error 错误
+	CheckCode bool
+	Headers   map[string]string
+	Queries   map[string]string // url查询参数,GET请求时请求参数会自动编码到查询参数中
+	Cookies   []*http.Cookie
 }
 
 func (o *Options) SetCookies(c ...*http.Cookie) {
@@ -50,6 +56,15 @@ func (o *Options) SetHeader(key, value string) *Options {
 	return o
 }
 
+// SetQuery 设置url查询参数
+func (o *Options) SetQuery(key, value string) *Options {
+	if o.Queries == nil {
+		o.Queries = make(map[string]string)
+	}
+	o.Queries[key] = value
+	return o
+}
+
 func (o *Options) SetHeaders(h map[string]string) *Options {
 	for k, v := range h {
 		o.Headers[k] = v
@@ -57,11 +72,27 @@ func (o *Options) SetHeaders(h map[string]string) *Options {
 	return o
 }
 
+// Clone 复制一份 Options,避免中间件修改影响调用方
+func (o *Options) Clone() *Options {
+	var c = *o
+	c.Headers = make(map[string]string, len(o.Headers))
+	for k, v := range o.Headers {
+		c.Headers[k] = v
+	}
+	c.Queries = make(map[string]string, len(o.Queries))
+	for k, v := range o.Queries {
+		c.Queries[k] = v
+	}
+	c.Cookies = append([]*http.Cookie{}, o.Cookies...)
+	return &c
+}
+
 func NewOptions() *Options {
 	return &Options{
 		Method:     http.MethodPost,
 		CryptoMode: CryptoModeWEAPI,
 		Headers:    make(map[string]string),
+		Queries:    make(map[string]string),
 		Cookies:    []*http.Cookie{},
 	}
 }
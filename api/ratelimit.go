@@ -0,0 +1,183 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limiter 请求限流器,可以通过 Client.SetLimiter 替换为自定义实现
+type Limiter interface {
+	// Wait 阻塞直到允许向host发送请求
+	Wait(ctx context.Context, host string) error
+	// Backoff 接口返回限流时暂停向host发送请求一段时间
+	Backoff(host string, d time.Duration)
+}
+
+// RateLimit 令牌桶限流参数
+type RateLimit struct {
+	Rate  float64 `json:"rate" yaml:"rate"`   // 每秒允许的请求数,小于等于0时不限流
+	Burst int     `json:"burst" yaml:"burst"` // 令牌桶容量,即允许的突发请求数
+}
+
+// HostRateLimit 指定host的限流参数
+type HostRateLimit struct {
+	Host  string  `json:"host" yaml:"host"` // eg: music.163.com 以.开头时匹配所有子域名,eg: .music.126.net
+	Rate  float64 `json:"rate" yaml:"rate"`
+	Burst int     `json:"burst" yaml:"burst"`
+}
+
+// RateLimitConfig 限流配置,每个host使用单独的令牌桶
+type RateLimitConfig struct {
+	Rate  float64         `json:"rate" yaml:"rate"`   // 默认每秒允许的请求数,小于等于0时不限流
+	Burst int             `json:"burst" yaml:"burst"` // 默认令牌桶容量
+	Hosts []HostRateLimit `json:"hosts" yaml:"hosts"` // 指定host的限流参数
+}
+
+func (c *RateLimitConfig) Validate() error {
+	if c.Rate < 0 || c.Burst < 0 {
+		return errors.New("rateLimit rate or burst is < 0")
+	}
+	for _, h := range c.Hosts {
+		if h.Host == "" {
+			return errors.New("rateLimit host is empty")
+		}
+		if h.Rate < 0 || h.Burst < 0 {
+			return errors.New("rateLimit " + h.Host + " rate or burst is < 0")
+		}
+	}
+	return nil
+}
+
+// limit 获取host对应的限流参数
+func (c *RateLimitConfig) limit(host string) RateLimit {
+	for _, h := range c.Hosts {
+		if strings.EqualFold(h.Host, host) ||
+			(strings.HasPrefix(h.Host, ".") && strings.HasSuffix(strings.ToLower(host), strings.ToLower(h.Host))) {
+			return RateLimit{Rate: h.Rate, Burst: h.Burst}
+		}
+	}
+	return RateLimit{Rate: c.Rate, Burst: c.Burst}
+}
+
+// RateLimiter 按host区分的令牌桶限流器
+type RateLimiter struct {
+	cfg     RateLimitConfig
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	return &RateLimiter{cfg: cfg, buckets: make(map[string]*bucket)}
+}
+
+func (l *RateLimiter) bucket(host string) *bucket {
+	host = strings.ToLower(host)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[host]
+	if !ok {
+		b = newBucket(l.cfg.limit(host))
+		l.buckets[host] = b
+	}
+	return b
+}
+
+func (l *RateLimiter) Wait(ctx context.Context, host string) error {
+	var d = l.bucket(host).reserve(time.Now())
+	if d <= 0 {
+		return nil
+	}
+	var timer = time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *RateLimiter) Backoff(host string, d time.Duration) {
+	l.bucket(host).pause(time.Now().Add(d))
+}
+
+// bucket 令牌桶,tokens为负数时表示已经预定了未来的令牌
+type bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	until  time.Time // 被限流后暂停请求的截止时间
+}
+
+func newBucket(limit RateLimit) *bucket {
+	var burst = float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucket{rate: limit.Rate, burst: burst, tokens: burst}
+}
+
+// reserve 获取一个令牌,返回需要等待的时间
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var wait time.Duration
+	if now.Before(b.until) {
+		wait = b.until.Sub(now)
+	}
+	if b.rate <= 0 {
+		return wait
+	}
+
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens < 0 {
+		if d := time.Duration(-b.tokens / b.rate * float64(time.Second)); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+func (b *bucket) pause(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if until.After(b.until) {
+		b.until = until
+	}
+}
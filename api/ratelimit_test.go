@@ -0,0 +1,143 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketReserve(t *testing.T) {
+	var (
+		now = time.Now()
+		b   = newBucket(RateLimit{Rate: 10, Burst: 2})
+	)
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+	assert.Equal(t, 100*time.Millisecond, b.reserve(now))
+	assert.Equal(t, 200*time.Millisecond, b.reserve(now))
+	// 一秒后令牌桶重新填满
+	assert.Equal(t, time.Duration(0), b.reserve(now.Add(time.Second)))
+
+	// 不限流时只受暂停时间影响
+	b = newBucket(RateLimit{})
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+	b.pause(now.Add(time.Second))
+	assert.Equal(t, time.Second, b.reserve(now))
+}
+
+func TestRateLimitConfigHost(t *testing.T) {
+	var cfg = RateLimitConfig{
+		Rate:  5,
+		Burst: 10,
+		Hosts: []HostRateLimit{
+			{Host: "music.163.com", Rate: 1, Burst: 1},
+			{Host: ".music.126.net", Rate: 0, Burst: 0},
+		},
+	}
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, RateLimit{Rate: 1, Burst: 1}, cfg.limit("Music.163.com"))
+	assert.Equal(t, RateLimit{}, cfg.limit("m701.music.126.net"))
+	assert.Equal(t, RateLimit{Rate: 5, Burst: 10}, cfg.limit("interface.music.163.com"))
+
+	cfg.Hosts = append(cfg.Hosts, HostRateLimit{Rate: 1})
+	assert.Error(t, cfg.Validate())
+}
+
+func TestRetryPolicy(t *testing.T) {
+	var p = NewRetryPolicy(RetryPolicyConfig{Attempts: 2, MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second})
+
+	code, ok := p.Retryable(0, http.StatusOK, []byte(`{"code":-460,"message":"Cheating"}`))
+	assert.True(t, ok)
+	assert.Equal(t, int64(-460), code)
+	code, ok = p.Retryable(0, http.StatusOK, []byte(`{"code":"405","msg":"操作频繁"}`))
+	assert.True(t, ok)
+	assert.Equal(t, int64(405), code)
+	_, ok = p.Retryable(0, http.StatusTooManyRequests, []byte(`<html></html>`))
+	assert.True(t, ok)
+	_, ok = p.Retryable(0, http.StatusOK, []byte(`{"code":200}`))
+	assert.False(t, ok)
+	_, ok = p.Retryable(0, http.StatusOK, []byte(`{"code":-462,"message":"需要进行安全验证"}`))
+	assert.False(t, ok)
+	_, ok = p.Retryable(2, http.StatusOK, []byte(`{"code":-460}`))
+	assert.False(t, ok)
+
+	for attempt, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		d := p.Backoff(attempt)
+		assert.GreaterOrEqual(t, d, max/2)
+		assert.LessOrEqual(t, d, max)
+	}
+}
+
+func TestRequestThrottleRetry(t *testing.T) {
+	var (
+		count     atomic.Int64
+		throttled atomic.Bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if count.Add(1) == 1 || throttled.Load() {
+			_, _ = w.Write([]byte(`{"code":-460,"message":"网络太拥挤，请稍候再试！"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":200}`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(&Config{
+		Timeout:     10 * time.Second,
+		Cookie:      cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+		RetryPolicy: RetryPolicyConfig{Attempts: 1, MinBackoff: 10 * time.Millisecond},
+	}, log.Default)
+	assert.NoError(t, err)
+	defer cli.Close(context.TODO())
+
+	var (
+		reply types.RespCommon[any]
+		opts  = NewOptions()
+	)
+	opts.CryptoMode = CryptoModeAPI
+	_, err = cli.Request(context.TODO(), srv.URL+"/api/test", struct{}{}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), reply.Code)
+	assert.Equal(t, int64(2), count.Load())
+
+	// 超过重试次数后返回最后一次的结果
+	count.Store(0)
+	throttled.Store(true)
+	_, err = cli.Request(context.TODO(), srv.URL+"/api/test", struct{}{}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-460), reply.Code)
+	assert.Equal(t, int64(2), count.Load())
+}
@@ -0,0 +1,360 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package recorder 录制以及回放接口请求,用于离线测试.
+//
+// 由于weapi等接口参数每次加密结果都不相同,因此通过 api.Middleware 获取加密前的请求参数,
+// 按照"接口地址+明文参数"生成key,录制时将请求以及响应保存到 fixture 文件中,回放时根据key查找.
+// 录制的内容会移除cookie以及token等敏感信息.
+package recorder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Mode 运行模式
+type Mode string
+
+const (
+	// ModeAuto fixture存在时回放,不存在时请求真实服务且不录制
+	ModeAuto Mode = "auto"
+	// ModeRecord 请求真实服务并录制
+	ModeRecord Mode = "record"
+	// ModeReplay 只回放,fixture不存在时返回 ErrFixtureNotFound
+	ModeReplay Mode = "replay"
+)
+
+// ErrFixtureNotFound 回放模式下未找到对应的fixture
+var ErrFixtureNotFound = errors.New("recorder: fixture not found")
+
+// Scrubbed 敏感信息替换后的值
+const Scrubbed = "[scrubbed]"
+
+// DefaultScrub 默认需要移除的参数以及响应字段名称
+var DefaultScrub = []string{
+	"csrf_token", "__csrf", "header", "MUSIC_U", "MUSIC_A", "MUSIC_R_T", "MUSIC_A_T",
+	"token", "checkToken", "cookie", "bizToken", "password", "captcha",
+}
+
+// Fixture 录制的一次请求
+type Fixture struct {
+	Comment  string          `json:"comment,omitempty"` // 说明,例如标记为非真实服务录制的模拟数据,回放时忽略
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+type FixtureRequest struct {
+	Method   string          `json:"method"`
+	Endpoint string          `json:"endpoint"`
+	Params   json.RawMessage `json:"params,omitempty"` // 加密前的请求参数
+	Headers  http.Header     `json:"headers,omitempty"`
+}
+
+type FixtureResponse struct {
+	Status     int         `json:"status"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	BodyBase64 []byte      `json:"bodyBase64,omitempty"` // 非utf8内容,例如eapi加密后的响应
+}
+
+type keyCtx struct{}
+
+type requestKey struct {
+	params json.RawMessage
+}
+
+// Recorder 录制以及回放请求的 http.RoundTripper
+type Recorder struct {
+	dir   string
+	mode  Mode
+	scrub map[string]bool
+	next  http.RoundTripper
+	mu    sync.Mutex
+}
+
+// New 创建 Recorder,dir为fixture保存目录,scrub为额外需要移除的字段名称
+func New(dir string, mode Mode, scrub ...string) *Recorder {
+	if mode == "" {
+		mode = ModeAuto
+	}
+	r := Recorder{
+		dir:   dir,
+		mode:  mode,
+		scrub: make(map[string]bool),
+		next:  http.DefaultTransport,
+	}
+	for _, v := range append(DefaultScrub, scrub...) {
+		r.scrub[strings.ToLower(v)] = true
+	}
+	return &r
+}
+
+// ModeFromEnv 从环境变量中读取运行模式,为空时使用 ModeReplay,保证测试默认不会访问真实服务
+func ModeFromEnv(key string) Mode {
+	switch mode := Mode(strings.ToLower(os.Getenv(key))); mode {
+	case ModeRecord, ModeAuto:
+		return mode
+	default:
+		return ModeReplay
+	}
+}
+
+// Install 将 Recorder 安装到 api.Client 中
+func (r *Recorder) Install(c *api.Client) {
+	if next := c.GetClient().Transport; next != nil {
+		r.next = next
+	}
+	c.GetClient().Transport = r
+	c.Use(r.Middleware())
+}
+
+// Middleware 将加密前的请求参数放入context中,用于生成fixture的key
+func (r *Recorder) Middleware() api.Middleware {
+	return func(next api.Handler) api.Handler {
+		return func(ctx context.Context, call *api.Call) error {
+			params, err := json.Marshal(call.Request)
+			if err != nil {
+				return fmt.Errorf("json.Marshal: %w", err)
+			}
+			ctx = context.WithValue(ctx, keyCtx{}, requestKey{params: r.scrubJSON(params)})
+			return next(ctx, call)
+		}
+	}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		file, fixReq = r.key(req)
+		path         = filepath.Join(r.dir, file)
+	)
+
+	if r.mode != ModeRecord {
+		fixture, err := r.load(path)
+		if err == nil {
+			return fixture.Response.response(req), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s", ErrFixtureNotFound, fixReq.Endpoint, path)
+		}
+		return r.next.RoundTrip(req)
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	fixture, err := r.record(fixReq, resp)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.save(path, fixture); err != nil {
+		return nil, err
+	}
+	return fixture.Response.response(req), nil
+}
+
+// key 根据请求方法、接口地址以及明文参数生成fixture文件名称
+func (r *Recorder) key(req *http.Request) (string, FixtureRequest) {
+	var fixReq = FixtureRequest{
+		Method:   req.Method,
+		Endpoint: req.URL.Host + req.URL.Path,
+	}
+	if k, ok := req.Context().Value(keyCtx{}).(requestKey); ok {
+		fixReq.Params = k.params
+	} else {
+		// 非 api.Client.Request 发起的请求,例如下载、上传,使用查询参数作为key
+		var query = req.URL.Query()
+		for k := range query {
+			if r.scrub[strings.ToLower(k)] {
+				query.Set(k, Scrubbed)
+			}
+		}
+		if len(query) > 0 {
+			fixReq.Params, _ = json.Marshal(query.Encode())
+		}
+	}
+	fixReq.Headers = req.Header.Clone()
+	for k := range fixReq.Headers {
+		if k == "Cookie" || r.scrub[strings.ToLower(k)] {
+			fixReq.Headers.Del(k)
+		}
+	}
+
+	sum := sha1.Sum([]byte(fixReq.Method + " " + fixReq.Endpoint + "\n" + string(fixReq.Params)))
+	name := strings.Trim(strings.NewReplacer("/", "_", ":", "_").Replace(fixReq.Endpoint), "_")
+	return fmt.Sprintf("%s_%s_%s.json", strings.ToLower(req.Method), name, hex.EncodeToString(sum[:])[:12]), fixReq
+}
+
+func (r *Recorder) record(req FixtureRequest, resp *http.Response) (*Fixture, error) {
+	defer resp.Body.Close()
+	body, err := decompress(resp.Header.Get("Content-Encoding"), resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers = resp.Header.Clone()
+	headers.Del("Set-Cookie")
+	headers.Del("Content-Encoding")
+	headers.Del("Content-Length")
+
+	var fixResp = FixtureResponse{Status: resp.StatusCode, Headers: headers}
+	if json.Valid(body) {
+		fixResp.Body = string(r.scrubJSON(body))
+	} else if utf8.Valid(body) {
+		fixResp.Body = string(body)
+	} else {
+		fixResp.BodyBase64 = body
+	}
+	return &Fixture{Request: req, Response: fixResp}, nil
+}
+
+func (r *Recorder) load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+func (r *Recorder) save(path string, fixture *Fixture) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("MkdirAll: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	return nil
+}
+
+// scrubJSON 将json中敏感字段的值替换为 Scrubbed,不是json对象或数组时原样返回
+func (r *Recorder) scrubJSON(data []byte) []byte {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return data
+	}
+	result, err := json.Marshal(r.scrubValue(value))
+	if err != nil {
+		return data
+	}
+	return result
+}
+
+func (r *Recorder) scrubValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if r.scrub[strings.ToLower(k)] {
+				v[k] = Scrubbed
+				continue
+			}
+			v[k] = r.scrubValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.scrubValue(item)
+		}
+	}
+	return value
+}
+
+func (f FixtureResponse) response(req *http.Request) *http.Response {
+	var body = []byte(f.Body)
+	if len(f.BodyBase64) > 0 {
+		body = f.BodyBase64
+	}
+	var headers = f.Headers.Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	headers.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func decompress(encoding string, body io.Reader) ([]byte, error) {
+	var (
+		r   = body
+		err error
+	)
+	switch strings.ToLower(encoding) {
+	case "gzip":
+		r, err = gzip.NewReader(body)
+	case "deflate":
+		r, err = zlib.NewReader(body)
+	case "br":
+		r = brotli.NewReader(body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decompress %s: %w", encoding, err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("ReadAll: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,122 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(t *testing.M) {
+	log.Default = log.New(&log.Config{
+		Level:  "debug",
+		Stdout: true,
+	})
+	os.Exit(t.Run())
+}
+
+func newClient(t *testing.T, r *Recorder) *api.Client {
+	cli, err := api.NewClient(&api.Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+	}, log.Default)
+	assert.NoError(t, err)
+	r.Install(cli)
+	return cli
+}
+
+func TestRecordReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "MUSIC_U", Value: "secret"})
+		_, _ = w.Write([]byte(`{"code":200,"data":{"id":1,"token":"secret"}}`))
+	}))
+
+	var (
+		dir   = t.TempDir()
+		req   = map[string]interface{}{"id": 1, "csrf_token": "secret"}
+		reply types.RespCommon[map[string]interface{}]
+	)
+
+	// 录制
+	cli := newClient(t, New(dir, ModeRecord))
+	_, err := cli.Request(context.TODO(), srv.URL+"/weapi/test", req, &reply, api.NewOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), reply.Code)
+	assert.Equal(t, Scrubbed, reply.Data["token"])
+	srv.Close()
+	_ = cli.Close(context.TODO())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	var fixture Fixture
+	assert.NoError(t, json.Unmarshal(data, &fixture))
+	assert.Equal(t, http.MethodPost, fixture.Request.Method)
+	assert.True(t, strings.HasSuffix(fixture.Request.Endpoint, "/weapi/test"))
+	assert.Empty(t, fixture.Response.Headers.Values("Set-Cookie"))
+
+	// 服务关闭后回放,敏感参数不同也能命中
+	cli = newClient(t, New(dir, ModeReplay))
+	defer cli.Close(context.TODO())
+	reply = types.RespCommon[map[string]interface{}]{}
+	req["csrf_token"] = "other"
+	_, err = cli.Request(context.TODO(), srv.URL+"/weapi/test", req, &reply, api.NewOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), reply.Code)
+	assert.Equal(t, float64(1), reply.Data["id"])
+
+	// 参数不同时未命中
+	req["id"] = 2
+	_, err = cli.Request(context.TODO(), srv.URL+"/weapi/test", req, &reply, api.NewOptions())
+	assert.ErrorIs(t, err, ErrFixtureNotFound)
+}
+
+func TestModeFromEnv(t *testing.T) {
+	t.Setenv("NCM_TEST_MODE", "Replay")
+	assert.Equal(t, ModeReplay, ModeFromEnv("NCM_TEST_MODE"))
+	t.Setenv("NCM_TEST_MODE", "record")
+	assert.Equal(t, ModeRecord, ModeFromEnv("NCM_TEST_MODE"))
+	t.Setenv("NCM_TEST_MODE", "auto")
+	assert.Equal(t, ModeAuto, ModeFromEnv("NCM_TEST_MODE"))
+	// 默认只回放
+	t.Setenv("NCM_TEST_MODE", "")
+	assert.Equal(t, ModeReplay, ModeFromEnv("NCM_TEST_MODE"))
+}
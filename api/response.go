@@ -0,0 +1,81 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// ResponseEncrypter 请求参数实现该接口时,由 EncryptResponse 决定eapi接口返回内容是否加密
+type ResponseEncrypter interface {
+	EncryptResponse() bool
+}
+
+// encryptResponse 判断eapi请求是否要求返回内容加密,即请求参数中e_r为true.
+// 优先级: Options.EncryptResponse > ResponseEncrypter > 请求参数中的e_r字段(例如结构体tag为`json:"e_r"`)
+// see: https://gitlab.com/Binaryify/neteasecloudmusicapi/-/commit/58e9865b70e41197c2ab75c46a775fc45d6efa6e
+func encryptResponse(req interface{}, opts *Options) bool {
+	if opts != nil && opts.EncryptResponse {
+		return true
+	}
+	if e, ok := req.(ResponseEncrypter); ok {
+		return e.EncryptResponse()
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return false
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return false
+	}
+	raw, ok := m["e_r"]
+	if !ok {
+		return false
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return false
+	}
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
+
+// isJSON 判断返回内容是否为明文json,部分接口即使要求加密也会返回明文
+func isJSON(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return false
+	}
+	return json.Valid(data)
+}
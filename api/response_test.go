@@ -0,0 +1,149 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"crypto/aes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/crypto"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type encrypterReq struct {
+	Id string `json:"id"`
+}
+
+func (encrypterReq) EncryptResponse() bool { return true }
+
+func TestEncryptResponse(t *testing.T) {
+	type tagReq struct {
+		Id string `json:"id"`
+		ER bool   `json:"e_r"`
+	}
+	var opts = NewOptions()
+	assert.False(t, encryptResponse(struct{}{}, opts))
+	assert.False(t, encryptResponse(tagReq{}, opts))
+	assert.True(t, encryptResponse(tagReq{ER: true}, opts))
+	assert.True(t, encryptResponse(map[string]interface{}{"e_r": "true"}, opts))
+	assert.True(t, encryptResponse(encrypterReq{}, opts))
+	opts.EncryptResponse = true
+	assert.True(t, encryptResponse(struct{}{}, opts))
+
+	assert.True(t, isJSON([]byte(` {"code":200}`)))
+	assert.False(t, isJSON([]byte("\x7f{")))
+	assert.False(t, isJSON(nil))
+}
+
+func TestRequestEApiDecrypt(t *testing.T) {
+	const body = `{"code":200,"message":"ok"}`
+	var plain bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plaintext, err := crypto.EApiDecrypt(r.FormValue("params"), "hex")
+		assert.NoError(t, err)
+		var params map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal([]byte(strings.Split(string(plaintext), "-36cd479b6b5-")[1]), &params))
+		if plain || string(params["e_r"]) != "true" {
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		block, err := aes.NewCipher([]byte("e82ckenh8dichen8"))
+		assert.NoError(t, err)
+		data, err := crypto.Pkcs7Padding([]byte(body), block.BlockSize())
+		assert.NoError(t, err)
+		_, _ = w.Write(crypto.AesEncryptECB(block, data))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(&Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+	}, log.Default)
+	assert.NoError(t, err)
+	defer cli.Close(context.TODO())
+
+	var opts = NewOptions()
+	opts.CryptoMode = CryptoModeEAPI
+
+	for _, req := range []interface{}{
+		struct{}{},
+		encrypterReq{Id: "1"},
+		map[string]interface{}{"e_r": true},
+	} {
+		var reply types.RespCommon[any]
+		_, err = cli.Request(context.TODO(), srv.URL+"/eapi/test", req, &reply, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(200), reply.Code)
+		assert.Equal(t, "ok", reply.Message)
+	}
+
+	// 通过Options要求加密,服务端返回明文时不解密
+	plain = true
+	opts.EncryptResponse = true
+	var reply types.RespCommon[any]
+	_, err = cli.Request(context.TODO(), srv.URL+"/eapi/test", struct{}{}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), reply.Code)
+}
+
+func TestRequestCheckCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":301,"msg":"需要登录"}`))
+	}))
+	defer srv.Close()
+
+	cli, err := NewClient(&Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+	}, log.Default)
+	assert.NoError(t, err)
+	defer cli.Close(context.TODO())
+
+	var (
+		reply types.RespCommon[any]
+		opts  = NewOptions()
+	)
+	opts.CryptoMode = CryptoModeAPI
+	_, err = cli.Request(context.TODO(), srv.URL+"/api/test", struct{}{}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(301), reply.Code)
+
+	opts.CheckCode = true
+	_, err = cli.Request(context.TODO(), srv.URL+"/api/test", struct{}{}, &reply, opts)
+	assert.ErrorIs(t, err, types.ErrNeedLogin)
+	var e *types.Error
+	assert.ErrorAs(t, err, &e)
+	assert.Equal(t, "/api/test", e.Endpoint)
+}
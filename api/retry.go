@@ -0,0 +1,120 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// DefaultThrottleCodes 网易云接口返回的限流相关错误码
+// -460: 网络太拥挤,请稍候再试(风控)
+// 405: 操作频繁,请稍候再试
+// -462(需要进行安全验证)重试无法通过验证,不在此列,直接返回给调用方处理
+var DefaultThrottleCodes = []int64{-460, 405}
+
+// RetryPolicyConfig 接口返回限流错误码时的重试策略。网络错误的重试次数由 Config.Retry 控制
+type RetryPolicyConfig struct {
+	Attempts   int           `json:"attempts" yaml:"attempts"`     // 最大重试次数,0为不重试
+	Codes      []int64       `json:"codes" yaml:"codes"`           // 需要重试的错误码,为空时使用 DefaultThrottleCodes
+	MinBackoff time.Duration `json:"minBackoff" yaml:"minBackoff"` // 第一次重试等待时间,默认1s
+	MaxBackoff time.Duration `json:"maxBackoff" yaml:"maxBackoff"` // 最大等待时间,默认30s
+}
+
+func (c *RetryPolicyConfig) Validate() error {
+	if c.Attempts < 0 {
+		return errors.New("retryPolicy attempts is < 0")
+	}
+	if c.MinBackoff < 0 || c.MaxBackoff < 0 {
+		return errors.New("retryPolicy backoff is < 0")
+	}
+	return nil
+}
+
+// RetryPolicy 根据接口返回的code判断是否需要重试,并计算指数退避等待时间
+type RetryPolicy struct {
+	cfg RetryPolicyConfig
+}
+
+func NewRetryPolicy(cfg RetryPolicyConfig) *RetryPolicy {
+	if len(cfg.Codes) == 0 {
+		cfg.Codes = DefaultThrottleCodes
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 30 * time.Second
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = cfg.MinBackoff
+	}
+	return &RetryPolicy{cfg: cfg}
+}
+
+// Retryable 第attempt次(从0开始)请求返回结果后是否需要重试,返回响应中的code
+func (p *RetryPolicy) Retryable(attempt, status int, body []byte) (int64, bool) {
+	var code = responseCode(body)
+	if attempt >= p.cfg.Attempts {
+		return code, false
+	}
+	if status == http.StatusTooManyRequests {
+		return code, true
+	}
+	return code, code != 0 && slices.Contains(p.cfg.Codes, code)
+}
+
+// Backoff 第attempt次重试前需要等待的时间,指数增长并增加随机抖动避免多个请求同时重试
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	var d = p.cfg.MinBackoff
+	for i := 0; i < attempt && d < p.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.cfg.MaxBackoff {
+		d = p.cfg.MaxBackoff
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// responseCode 解析响应中的code字段,部分接口返回的code为字符串
+func responseCode(body []byte) int64 {
+	var reply struct {
+		Code json.RawMessage `json:"code"`
+	}
+	if err := json.Unmarshal(body, &reply); err != nil || len(reply.Code) == 0 {
+		return 0
+	}
+	code, err := strconv.ParseInt(string(reply.Code), 10, 64)
+	if err != nil {
+		if s, err := strconv.Unquote(string(reply.Code)); err == nil {
+			code, _ = strconv.ParseInt(s, 10, 64)
+		}
+	}
+	return code
+}
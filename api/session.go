@@ -0,0 +1,337 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+)
+
+var musicURL, _ = neturl.Parse("https://music.163.com")
+
+// SessionConfig 登录会话管理配置
+type SessionConfig struct {
+	// RefreshInterval 定时刷新登录token的间隔,为0时只在token即将过期时刷新
+	RefreshInterval time.Duration `json:"refreshInterval" yaml:"refreshInterval"`
+	// RefreshBefore MUSIC_U距离过期时间小于该值时刷新 默认7天
+	RefreshBefore time.Duration `json:"refreshBefore" yaml:"refreshBefore"`
+}
+
+func (c *SessionConfig) Validate() error {
+	if c.RefreshInterval < 0 || c.RefreshBefore < 0 {
+		return errors.New("session refresh interval is < 0")
+	}
+	return nil
+}
+
+type SessionEventType string
+
+const (
+	SessionRefreshed     SessionEventType = "refreshed"     // token刷新成功
+	SessionRefreshFailed SessionEventType = "refreshFailed" // token刷新失败
+	SessionExpired       SessionEventType = "expired"       // 登录已过期,需要重新登录
+)
+
+// SessionEvent 会话事件
+type SessionEvent struct {
+	Type     SessionEventType
+	Time     time.Time
+	Endpoint string    // 触发事件的接口
+	Code     int64     // 接口返回的业务码
+	Expires  time.Time // MUSIC_U过期时间
+	Err      error
+}
+
+func (e SessionEvent) String() string {
+	return fmt.Sprintf("event=%s time=%s endpoint=%s code=%d expires=%s err=%v",
+		e.Type, e.Time.Format(time.RFC3339), e.Endpoint, e.Code, e.Expires.Format(time.RFC3339), e.Err)
+}
+
+// sessionState 需要持久化的会话状态
+type sessionState struct {
+	LastRefresh int64 `json:"lastRefresh"` // 上次刷新token的时间,unix秒
+}
+
+// sessionPath 会话状态保存路径,与cookie文件同目录,例如: cookie.json 对应 cookie.session.json
+// cookie文件路径为空时返回空,此时会话状态只保存在内存中
+func sessionPath(cookiePath string) string {
+	if cookiePath == "" {
+		return ""
+	}
+	return strings.TrimSuffix(cookiePath, filepath.Ext(cookiePath)) + ".session.json"
+}
+
+// Session 登录会话管理,负责跟踪MUSIC_U过期时间、定时刷新token以及检测登录过期
+type Session struct {
+	client   *Client
+	cfg      SessionConfig
+	path     string
+	mu       sync.Mutex
+	state    sessionState
+	handlers []func(SessionEvent)
+	expired  string // 已经判定过期的MUSIC_U,避免重复通知
+	done     chan struct{}
+	once     sync.Once
+}
+
+func newSession(client *Client, cfg SessionConfig, path string) *Session {
+	if cfg.RefreshBefore <= 0 {
+		cfg.RefreshBefore = 7 * 24 * time.Hour
+	}
+	s := &Session{client: client, cfg: cfg, path: path, done: make(chan struct{})}
+	if err := s.load(); err != nil {
+		log.Warn("[session] load %s: %s", path, err)
+	}
+	s.OnEvent(func(e SessionEvent) {
+		if e.Type == SessionRefreshed {
+			log.Info("[session] %s", e)
+			return
+		}
+		log.Warn("[session] %s", e)
+	})
+	return s
+}
+
+// OnEvent 注册会话事件回调,回调在触发事件的goroutine中同步执行
+func (s *Session) OnEvent(fn func(SessionEvent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, fn)
+}
+
+func (s *Session) emit(e SessionEvent) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Expires.IsZero() {
+		e.Expires, _ = s.Expires()
+	}
+	s.mu.Lock()
+	handlers := append([]func(SessionEvent){}, s.handlers...)
+	s.mu.Unlock()
+	for _, fn := range handlers {
+		fn(e)
+	}
+}
+
+// Token 获取登录凭证MUSIC_U,未登录或已过期时返回false
+func (s *Session) Token() (cookie.Entry, bool) {
+	e, ok := s.client.cookie.Entry(musicURL, "MUSIC_U")
+	return e, ok && e.Value != ""
+}
+
+// Expires 获取MUSIC_U过期时间
+func (s *Session) Expires() (time.Time, bool) {
+	e, ok := s.Token()
+	if !ok || !e.Persistent {
+		return time.Time{}, false
+	}
+	return e.Expires, true
+}
+
+// LoggedIn 本地是否存在有效的登录凭证并且没有检测到登录过期,不会请求接口
+func (s *Session) LoggedIn() bool {
+	e, ok := s.Token()
+	if !ok {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expired != e.Value
+}
+
+// LastRefresh 获取上次刷新token的时间
+func (s *Session) LastRefresh() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state.LastRefresh <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(s.state.LastRefresh, 0), true
+}
+
+// load 加载会话状态,文件不存在时忽略
+func (s *Session) load() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("ReadFile: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := json.Unmarshal(data, &s.state); err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return nil
+}
+
+// save 保存会话状态
+func (s *Session) save() error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	data, err := json.Marshal(s.state)
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), os.ModePerm); err != nil {
+		return fmt.Errorf("MkdirAll: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	return nil
+}
+
+// Expire 标记当前登录已过期,每个登录凭证只通知一次.未登录时忽略
+func (s *Session) Expire(endpoint string, code int64) {
+	e, ok := s.Token()
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	if s.expired == e.Value {
+		s.mu.Unlock()
+		return
+	}
+	s.expired = e.Value
+	s.mu.Unlock()
+	s.emit(SessionEvent{Type: SessionExpired, Endpoint: endpoint, Code: code, Expires: e.Expires})
+}
+
+// Refresh 刷新登录token
+func (s *Session) Refresh(ctx context.Context) error {
+	var (
+		url   = "https://music.163.com/weapi/login/token/refresh"
+		req   types.ReqCommon
+		reply struct {
+			types.RespCommon[any]
+			BizCode string `json:"bizCode"`
+		}
+		opts = NewOptions()
+	)
+	req.CSRFToken, _ = s.client.GetCSRF(url)
+	opts.SetCookies(&http.Cookie{Name: "os", Value: "pc"})
+
+	_, err := s.client.Request(ctx, url, &req, &reply, opts)
+	if err == nil && reply.Code != http.StatusOK {
+		err = types.NewError(reply.Code, reply.GetMessage(), endpoint(url))
+	}
+	if err != nil {
+		s.emit(SessionEvent{Type: SessionRefreshFailed, Endpoint: endpoint(url), Code: reply.Code, Err: err})
+		return fmt.Errorf("TokenRefresh: %w", err)
+	}
+
+	s.mu.Lock()
+	s.state.LastRefresh = time.Now().Unix()
+	s.mu.Unlock()
+	if err := s.save(); err != nil {
+		log.Warn("[session] save %s: %s", s.path, err)
+	}
+	s.emit(SessionEvent{Type: SessionRefreshed, Endpoint: endpoint(url), Code: reply.Code})
+	return nil
+}
+
+// RefreshIfNeeded 已登录并且距离上次刷新超过 SessionConfig.RefreshInterval 或者token即将过期时刷新,返回是否进行了刷新
+func (s *Session) RefreshIfNeeded(ctx context.Context) (bool, error) {
+	if !s.LoggedIn() {
+		return false, nil
+	}
+	var due bool
+	if expires, ok := s.Expires(); ok && time.Until(expires) < s.cfg.RefreshBefore {
+		due = true
+	}
+	if s.cfg.RefreshInterval > 0 {
+		last, ok := s.LastRefresh()
+		due = due || !ok || time.Since(last) >= s.cfg.RefreshInterval
+	}
+	if !due {
+		return false, nil
+	}
+	return true, s.Refresh(ctx)
+}
+
+// middleware 检测接口返回的需要登录错误码
+func (s *Session) middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) error {
+			err := next(ctx, call)
+			code, ok := types.Code(err)
+			if !ok {
+				code = responseCode(call.Body)
+			}
+			if types.Matches(code, types.ErrNeedLogin) {
+				s.Expire(endpoint(call.Url), code)
+			}
+			return err
+		}
+	}
+}
+
+// start 开启定时刷新
+func (s *Session) start() {
+	if s.cfg.RefreshInterval <= 0 {
+		return
+	}
+	go func() {
+		tick := time.NewTicker(min(s.cfg.RefreshInterval, time.Hour))
+		defer tick.Stop()
+		for {
+			select {
+			case <-tick.C:
+				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+				if _, err := s.RefreshIfNeeded(ctx); err != nil {
+					log.Warn("[session] refresh: %s", err)
+				}
+				cancel()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+func (s *Session) close() {
+	s.once.Do(func() { close(s.done) })
+}
@@ -0,0 +1,133 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/weapi/login":
+			http.SetCookie(w, &http.Cookie{Name: "MUSIC_U", Value: "token", Path: "/", Expires: time.Now().Add(time.Hour)})
+			_, _ = w.Write([]byte(`{"code":200}`))
+		case "/weapi/login/token/refresh":
+			http.SetCookie(w, &http.Cookie{Name: "MUSIC_U", Value: "refreshed", Path: "/", Expires: time.Now().Add(30 * 24 * time.Hour)})
+			_, _ = w.Write([]byte(`{"code":200,"bizCode":"201"}`))
+		default:
+			_, _ = w.Write([]byte(`{"code":301,"message":"需要登录"}`))
+		}
+	}))
+	defer srv.Close()
+
+	var (
+		dir = t.TempDir()
+		cfg = &Config{
+			Timeout: 10 * time.Second,
+			Cookie:  cookie.Config{Filepath: filepath.Join(dir, "cookie.json")},
+			BaseURL: srv.URL,
+			Session: SessionConfig{RefreshBefore: 24 * time.Hour},
+		}
+	)
+	cli, err := NewClient(cfg, log.Default)
+	assert.NoError(t, err)
+	defer cli.Close(context.TODO())
+
+	var (
+		ctx     = context.TODO()
+		session = cli.Session()
+		events  []SessionEvent
+		reply   types.RespCommon[any]
+	)
+	session.OnEvent(func(e SessionEvent) { events = append(events, e) })
+
+	// 未登录时不会触发过期事件
+	assert.False(t, session.LoggedIn())
+	_, err = cli.Request(ctx, "https://music.163.com/weapi/user", struct{}{}, &reply, NewOptions())
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	_, err = cli.Request(ctx, "https://music.163.com/weapi/login", struct{}{}, &reply, NewOptions())
+	assert.NoError(t, err)
+	assert.True(t, session.LoggedIn())
+	expires, ok := session.Expires()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expires, time.Minute)
+	_, ok = session.LastRefresh()
+	assert.False(t, ok)
+
+	// 即将过期时刷新
+	refreshed, err := session.RefreshIfNeeded(ctx)
+	assert.NoError(t, err)
+	assert.True(t, refreshed)
+	token, _ := session.Token()
+	assert.Equal(t, "refreshed", token.Value)
+	last, ok := session.LastRefresh()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now(), last, 2*time.Second)
+	// 会话状态保存在单独的文件中,不会写入cookie
+	assert.FileExists(t, filepath.Join(dir, "cookie.session.json"))
+	_, ok = cli.cookie.Entry(&neturl.URL{Scheme: "https", Host: "session.ncmctl.invalid"}, "lastRefresh")
+	assert.False(t, ok)
+	cli2, err := NewClient(cfg, log.Default)
+	assert.NoError(t, err)
+	last2, ok := cli2.Session().LastRefresh()
+	assert.True(t, ok)
+	assert.Equal(t, last.Unix(), last2.Unix())
+	assert.NoError(t, cli2.Close(ctx))
+
+	refreshed, err = session.RefreshIfNeeded(ctx)
+	assert.NoError(t, err)
+	assert.False(t, refreshed)
+
+	// 检测到需要登录时只通知一次
+	for range 2 {
+		_, err = cli.Request(ctx, "https://music.163.com/weapi/user", struct{}{}, &reply, NewOptions())
+		assert.NoError(t, err)
+	}
+	assert.False(t, session.LoggedIn())
+	assert.Len(t, events, 2)
+	assert.Equal(t, SessionRefreshed, events[0].Type)
+	assert.Equal(t, SessionExpired, events[1].Type)
+	assert.Equal(t, "music.163.com/weapi/user", events[1].Endpoint)
+	assert.Equal(t, int64(301), events[1].Code)
+
+	// 过期后不再刷新
+	refreshed, err = session.RefreshIfNeeded(ctx)
+	assert.NoError(t, err)
+	assert.False(t, refreshed)
+}
@@ -0,0 +1,138 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// Error 网易云接口返回的业务错误,即返回内容中code不为200
+type Error struct {
+	Code     int64  // 业务错误码
+	Message  string // 错误信息
+	Endpoint string // 接口路径
+}
+
+func NewError(code int64, message, endpoint string) *Error {
+	return &Error{Code: code, Message: message, Endpoint: endpoint}
+}
+
+func (e *Error) Error() string {
+	if e.Endpoint == "" {
+		return fmt.Sprintf("code: %d message: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s code: %d message: %s", e.Endpoint, e.Code, e.Message)
+}
+
+// Is 错误码相同或者属于同一类错误码时认为是同一个错误,用于 errors.Is 判断
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return Matches(e.Code, t)
+}
+
+// Matches 业务错误码是否属于target这一类错误,用于只有错误码而没有错误的场景
+func Matches(code int64, target *Error) bool {
+	if codes, ok := errorCodes[target]; ok {
+		return slices.Contains(codes, code)
+	}
+	return target.Code == code
+}
+
+var (
+	ErrNeedLogin   = &Error{Code: 301, Message: "需要登录"}
+	ErrRateLimited = &Error{Code: 405, Message: "操作频繁"}
+	ErrNotPartner  = &Error{Code: 703, Message: "不是音乐合伙人"}
+	ErrCaptcha     = &Error{Code: 8821, Message: "需要行为验证码验证"}
+	ErrNoSource    = &Error{Code: -110, Message: "无音源"}
+	ErrNoPrivilege = &Error{Code: -105, Message: "无会员权益"}
+	ErrNoCopyright = &Error{Code: -200, Message: "资源已下架或无版权"}
+)
+
+// errorCodes 同一类错误包含的错误码
+var errorCodes = map[*Error][]int64{
+	ErrNeedLogin:   {301, 302},
+	ErrRateLimited: {405, -460},  // -460: 网络太拥挤,请稍候再试
+	ErrCaptcha:     {8821, -462}, // -462: 需要安全验证
+}
+
+// IsNeedLogin 是否为未登录或登录已过期
+func IsNeedLogin(err error) bool {
+	return errors.Is(err, ErrNeedLogin)
+}
+
+// IsRateLimited 是否为接口限流或风控
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// Code 获取错误中的业务错误码,不是业务错误时返回false
+func Code(err error) (int64, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code, true
+	}
+	return 0, false
+}
+
+// Coder 带有业务错误码的返回结构
+type Coder interface {
+	GetCode() int64
+	GetMessage() string
+}
+
+func (r *RespCommon[T]) GetCode() int64 { return r.Code }
+
+func (r *RespCommon[T]) GetMessage() string {
+	if r.Message != "" {
+		return r.Message
+	}
+	return r.Msg
+}
+
+func (r *ApiRespCommon[T]) GetCode() int64 { return r.Code }
+
+func (r *ApiRespCommon[T]) GetMessage() string {
+	if r.Message != "" {
+		return r.Message
+	}
+	return r.Msg
+}
+
+// CheckCode 检查返回结构中的业务错误码,code为0(未返回)或者200时返回nil
+func CheckCode(reply interface{}, endpoint string) error {
+	c, ok := reply.(Coder)
+	if !ok {
+		return nil
+	}
+	if code := c.GetCode(); code != 0 && code != http.StatusOK {
+		return NewError(code, c.GetMessage(), endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	var tests = []struct {
+		code   int64
+		target *Error
+		want   bool
+	}{
+		{code: 301, target: ErrNeedLogin, want: true},
+		{code: 302, target: ErrNeedLogin, want: true},
+		{code: 405, target: ErrRateLimited, want: true},
+		{code: -460, target: ErrRateLimited, want: true},
+		{code: -462, target: ErrCaptcha, want: true},
+		{code: 8821, target: ErrCaptcha, want: true},
+		{code: 703, target: ErrNotPartner, want: true},
+		{code: -110, target: ErrNoSource, want: true},
+		{code: -105, target: ErrNoPrivilege, want: true},
+		{code: -200, target: ErrNoCopyright, want: true},
+		{code: 404, target: ErrNoCopyright, want: false},
+		{code: -105, target: ErrNoSource, want: false},
+		{code: 200, target: ErrNeedLogin, want: false},
+	}
+	for _, tt := range tests {
+		var err = fmt.Errorf("wrap: %w", NewError(tt.code, "msg", "/api/test"))
+		assert.Equal(t, tt.want, errors.Is(err, tt.target), "code=%d target=%v", tt.code, tt.target)
+		assert.Equal(t, tt.want, Matches(tt.code, tt.target), "code=%d target=%v", tt.code, tt.target)
+	}
+
+	var err = fmt.Errorf("wrap: %w", NewError(301, "需要登录", "/weapi/test"))
+	assert.True(t, IsNeedLogin(err))
+	assert.False(t, IsRateLimited(err))
+	code, ok := Code(err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(301), code)
+	_, ok = Code(errors.New("other"))
+	assert.False(t, ok)
+	assert.Equal(t, "/weapi/test code: 301 message: 需要登录", errors.Unwrap(err).Error())
+}
+
+func TestCheckCode(t *testing.T) {
+	type reply struct {
+		RespCommon[any]
+		Extra string
+	}
+	assert.NoError(t, CheckCode(&reply{RespCommon: RespCommon[any]{Code: 200}}, "/api/test"))
+	assert.NoError(t, CheckCode(&reply{}, "/api/test"))
+	assert.NoError(t, CheckCode(map[string]any{"code": 301}, "/api/test"))
+
+	err := CheckCode(&reply{RespCommon: RespCommon[any]{Code: 301, Msg: "需要登录"}}, "/api/test")
+	assert.ErrorIs(t, err, ErrNeedLogin)
+	var e *Error
+	assert.ErrorAs(t, err, &e)
+	assert.Equal(t, "需要登录", e.Message)
+	assert.Equal(t, "/api/test", e.Endpoint)
+
+	err = CheckCode(&ApiRespCommon[any]{Code: -460, Message: "Cheating"}, "/api/test")
+	assert.True(t, IsRateLimited(err))
+}
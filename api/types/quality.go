@@ -60,6 +60,28 @@ var LevelString = map[Level]string{
 	// LevelDolby: "杜比全景声(Dolby Atmos)",
 }
 
+// levelRank 音质从低到高排序,用于比较音质高低
+var levelRank = map[Level]int{
+	LevelStandard: 1,
+	LevelHigher:   2,
+	LevelExhigh:   3,
+	LevelLossless: 4,
+	LevelHires:    5,
+	LevelJyeffect: 6,
+	LevelSky:      7,
+	LevelJymaster: 8,
+}
+
+// Rank 返回音质等级,数值越大音质越高,未知音质返回0
+func (l Level) Rank() int {
+	return levelRank[l]
+}
+
+// Higher 判断音质l是否比other高
+func (l Level) Higher(other Level) bool {
+	return l.Rank() > other.Rank()
+}
+
 // Quality 音质信息
 type Quality struct {
 	// Br(Bit Rate) 码率
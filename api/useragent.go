@@ -23,49 +23,117 @@
 
 package api
 
-import "sync"
+import (
+	"fmt"
+	"slices"
+)
 
-type UserAgent struct {
-	Android []string `json:"android" yaml:"android"`
-	IOS     []string `json:"ios" yaml:"ios"`
-	Mac     []string `json:"mac" yaml:"mac"`
-	Windows []string `json:"windows" yaml:"windows"`
-	Linux   []string `json:"linux" yaml:"linux"`
-}
+// Device 请求时模拟的设备类型,不同设备使用不同的User-Agent以及eapi header参数
+type Device string
+
+const (
+	DevicePC      Device = "pc"
+	DeviceMac     Device = "mac"
+	DeviceAndroid Device = "android"
+	DeviceIPhone  Device = "iphone"
+	DeviceLinux   Device = "linux"
+)
+
+// DefaultDevice 未指定设备时使用mac客户端
+const DefaultDevice = DeviceMac
 
-func (u *UserAgent) Get(os string) string {
-	switch os {
-	case "android":
-		return u.Android[0]
-	case "ios":
-		return u.IOS[0]
-	case "mac":
-		return u.Mac[0]
-	case "windows":
-		return u.Windows[0]
-	case "linux":
-		return u.Linux[0]
+func (d Device) Validate() error {
+	if d == "" {
+		return nil
 	}
-	return ""
+	if _, ok := profiles[d]; !ok {
+		return fmt.Errorf("device %q unknown, must be one of %v", d, Devices())
+	}
+	return nil
+}
+
+// Profile 设备信息,eapi接口会将这些信息放入header参数以及cookie中
+type Profile struct {
+	Device      Device
+	UserAgent   string
+	OS          string // 系统 pc、osx、android、iPhone OS、linux
+	OSVer       string // 系统版本,mac客户端为url编码内容,解码后为: 版本12.6（版本21G115）
+	AppVer      string // 客户端版本
+	VersionCode string
+	Channel     string // 渠道
+	MobileName  string // 手机型号
+	BuildVer    string
+	Resolution  string // 屏幕分辨率
 }
 
-type Agent struct {
-	mux   sync.Mutex
-	store map[string]UserAgent
+var profiles = map[Device]Profile{
+	DevicePC: {
+		Device:     DevicePC,
+		UserAgent:  "Mozilla/5.0 (Windows NT 10.0; WOW64) AppleWebKit/537.36 (KHTML, like Gecko) Safari/537.36 Chrome/91.0.4472.164 NeteaseMusicDesktop/3.0.18.203152",
+		OS:         "pc",
+		OSVer:      "Microsoft-Windows-10-Professional-build-22631-64bit",
+		AppVer:     "3.0.18.203152",
+		Channel:    "netease",
+		Resolution: "1920x1080",
+	},
+	DeviceMac: {
+		Device:     DeviceMac,
+		UserAgent:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) NeteaseMusicDesktop/2.3.17.1034",
+		OS:         "osx",
+		OSVer:      "%E7%89%88%E6%9C%AC12.6%EF%BC%88%E7%89%88%E5%8F%B721G115%EF%BC%89",
+		AppVer:     "2.3.17",
+		Channel:    "netease",
+		Resolution: "1920x1080",
+	},
+	DeviceAndroid: {
+		Device:      DeviceAndroid,
+		UserAgent:   "NeteaseMusic/9.1.65.240927161425(9001065);Dalvik/2.1.0 (Linux; U; Android 14; 23013RK75C Build/UKQ1.230804.001)",
+		OS:          "android",
+		OSVer:       "14",
+		AppVer:      "9.1.65",
+		VersionCode: "9001065",
+		Channel:     "xiaomi",
+		MobileName:  "23013RK75C",
+		BuildVer:    "240927161425",
+		Resolution:  "2400x1080",
+	},
+	DeviceIPhone: {
+		Device:     DeviceIPhone,
+		UserAgent:  "NeteaseMusic 9.0.90/5038 (iPhone; iOS 16.2; zh_CN)",
+		OS:         "iPhone OS",
+		OSVer:      "16.2",
+		AppVer:     "9.0.90",
+		Channel:    "distribution",
+		MobileName: "iPhone14,5",
+		BuildVer:   "5038",
+		Resolution: "1170x2532",
+	},
+	DeviceLinux: {
+		Device:     DeviceLinux,
+		UserAgent:  "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/60.0.3112.90 Safari/537.36",
+		OS:         "linux",
+		OSVer:      "Deepin 20.9",
+		AppVer:     "1.2.1.0428",
+		Channel:    "netease",
+		Resolution: "1920x1080",
+	},
 }
 
-func NewAgent() *Agent {
-	a := Agent{
-		mux:   sync.Mutex{},
-		store: make(map[string]UserAgent),
+// Devices 返回所有支持的设备类型
+func Devices() []Device {
+	var list = make([]Device, 0, len(profiles))
+	for d := range profiles {
+		list = append(list, d)
 	}
-	return &a
+	slices.Sort(list)
+	return list
 }
 
-func (a *Agent) Get(user, os string) string {
-	data, ok := a.store[user]
-	if !ok {
-		return ""
+// GetProfile 获取设备信息,设备为空时返回 DefaultDevice 的设备信息
+func GetProfile(d Device) (Profile, bool) {
+	if d == "" {
+		d = DefaultDevice
 	}
-	return data.Get(os)
+	p, ok := profiles[d]
+	return p, ok
 }
@@ -0,0 +1,125 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/crypto"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceValidate(t *testing.T) {
+	for _, d := range []Device{"", DevicePC, DeviceMac, DeviceAndroid, DeviceIPhone, DeviceLinux} {
+		assert.NoError(t, d.Validate(), d)
+	}
+	assert.Error(t, Device("windows").Validate())
+	assert.Len(t, Devices(), 5)
+
+	p, ok := GetProfile("")
+	assert.True(t, ok)
+	assert.Equal(t, DefaultDevice, p.Device)
+}
+
+func TestRequestDeviceProfile(t *testing.T) {
+	type payload struct {
+		UserAgent string
+		Cookies   map[string]string
+		Params    map[string]json.RawMessage
+	}
+	var last payload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = payload{UserAgent: r.UserAgent(), Cookies: make(map[string]string)}
+		for _, c := range r.Cookies() {
+			last.Cookies[c.Name] = c.Value
+		}
+		if params := r.FormValue("params"); params != "" {
+			plaintext, err := crypto.EApiDecrypt(params, "hex")
+			assert.NoError(t, err)
+			parts := strings.Split(string(plaintext), "-36cd479b6b5-")
+			assert.Len(t, parts, 3)
+			assert.NoError(t, json.Unmarshal([]byte(parts[1]), &last.Params))
+		}
+		_, _ = w.Write([]byte(`{"code":200}`))
+	}))
+	defer srv.Close()
+
+	var cookieFile = filepath.Join(t.TempDir(), "cookie.json")
+	cli, err := NewClient(&Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: cookieFile},
+		Device:  DeviceAndroid,
+	}, log.Default)
+	assert.NoError(t, err)
+
+	var (
+		reply types.RespCommon[any]
+		opts  = NewOptions()
+	)
+	opts.CryptoMode = CryptoModeEAPI
+	_, err = cli.Request(context.TODO(), srv.URL+"/eapi/test", map[string]string{"id": "1"}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, profiles[DeviceAndroid].UserAgent, last.UserAgent)
+	assert.Equal(t, "android", last.Cookies["os"])
+	assert.JSONEq(t, `"1"`, string(last.Params["id"]))
+
+	var header Header
+	assert.NoError(t, json.Unmarshal(last.Params["header"], &header))
+	assert.Equal(t, "android", header.OS)
+	assert.Equal(t, profiles[DeviceAndroid].AppVer, header.AppVer)
+	assert.NotEmpty(t, header.RequestId)
+	assert.Equal(t, cli.DeviceId(), header.DeviceId)
+	assert.Equal(t, header.DeviceId, last.Cookies["deviceId"])
+
+	// 单个请求覆盖设备
+	opts.Device = DevicePC
+	_, err = cli.Request(context.TODO(), srv.URL+"/eapi/test", struct{}{}, &reply, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, profiles[DevicePC].UserAgent, last.UserAgent)
+	assert.NoError(t, json.Unmarshal(last.Params["header"], &header))
+	assert.Equal(t, "pc", header.OS)
+
+	// 设备id持久化到cookie文件中,重新创建客户端后保持不变
+	var id = cli.DeviceId()
+	assert.NoError(t, cli.Close(context.TODO()))
+	cli, err = NewClient(&Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: cookieFile},
+	}, log.Default)
+	assert.NoError(t, err)
+	defer cli.Close(context.TODO())
+	assert.Equal(t, id, cli.DeviceId())
+	assert.Equal(t, profiles[DefaultDevice].UserAgent, cli.UserAgent())
+}
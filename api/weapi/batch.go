@@ -24,69 +24,145 @@
 package weapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-)
 
-// Headers 自定义 Headers 数据类型 (仅对于非 eapi 有效)
-type Headers []struct {
-	Name  string
-	Value string
-}
+	"github.com/chaunsin/netease-cloud-music/api"
+)
 
-// RequestData 传入请求数据类型
-type RequestData struct {
-	Cookies []*http.Cookie
-	Headers Headers
-	Body    string
+// BatchItemError 批处理中单个接口返回的错误
+type BatchItemError struct {
+	Key     string
+	Code    int64
+	Message string
 }
 
-// EapiOption eapi 请求所需要的参数
-type EapiOption struct {
-	Json string
-	Path string
-	Url  string
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("batch %s code: %d message: %s", e.Key, e.Code, e.Message)
 }
 
-// Batch 批处理 APi
+// Batch 批处理 APi,将多个接口合并为一次 /eapi/batch 请求
 type Batch struct {
 	API    map[string]interface{}
 	Result string
 	Header http.Header
 	Error  error
+	Errors map[string]error // 每个接口的错误,key为接口路径
+	apis   []BatchAPI
 }
 
 // BatchAPI 被批处理的 API
 type BatchAPI struct {
-	Key  string
-	Json string
+	Key  string      // 接口路径,例如: /api/point/dailyTask
+	Json string      // 接口请求参数json,为空时使用 Req
+	Req  interface{} // 接口请求参数,Json 与 Req 都为空时为{}
+	Resp interface{} // 接口返回内容需要解析到的结构体指针,可为空
 }
 
 // Add 添加 API
 func (b *Batch) Add(apis ...BatchAPI) *Batch {
 	for _, api := range apis {
 		b.API[api.Key] = api.Json
+		b.apis = append(b.apis, api)
 	}
 	return b
 }
 
-// Do 请求批处理 API
-func (b *Batch) Do(data RequestData) *Batch {
-	reqBodyJson, err := json.Marshal(b.API)
+// Do 请求批处理 API,请求成功后每个接口的返回内容会解析到对应的 BatchAPI.Resp 中,
+// 单个接口失败不会影响其他接口,可通过 Err 获取单个接口的错误.
+func (b *Batch) Do(ctx context.Context, client *api.Client) *Batch {
+	var (
+		url   = "https://music.163.com/eapi/batch"
+		req   = make(map[string]string, len(b.apis))
+		reply map[string]json.RawMessage
+		opts  = api.NewOptions()
+	)
+	opts.CryptoMode = api.CryptoModeEAPI
+
+	for _, v := range b.apis {
+		var data = v.Json
+		if data == "" {
+			data = "{}"
+			if v.Req != nil {
+				raw, err := json.Marshal(v.Req)
+				if err != nil {
+					b.Error = fmt.Errorf("json.Marshal(%s): %w", v.Key, err)
+					return b
+				}
+				data = string(raw)
+			}
+		}
+		req[v.Key] = data
+	}
+
+	resp, err := client.Request(ctx, url, req, &reply, opts)
+	if resp != nil {
+		b.Header = resp.Header()
+	}
 	if err != nil {
-		b.Error = err
+		b.Error = fmt.Errorf("Request: %w", err)
+		return b
+	}
+	result, err := json.Marshal(reply)
+	if err != nil {
+		b.Error = fmt.Errorf("json.Marshal: %w", err)
+		return b
+	}
+	b.Result = string(result)
+
+	var common struct {
+		Code    int64  `json:"code"`
+		Message string `json:"message"`
+		Msg     string `json:"msg"`
+	}
+	if err := json.Unmarshal(result, &common); err != nil {
+		b.Error = fmt.Errorf("json.Unmarshal: %w", err)
 		return b
 	}
-	var options EapiOption
-	options.Path = "/api/batch"
-	options.Url = "https://music.163.com/eapi/batch"
-	options.Json = string(reqBodyJson)
-	// todo:
-	// b.Result, b.Header, b.Error = utils.ApiRequest(options, data)
+	if common.Code != 0 && common.Code != http.StatusOK {
+		b.Error = &BatchItemError{Key: "/api/batch", Code: common.Code, Message: common.Message}
+		return b
+	}
+
+	b.Errors = make(map[string]error, len(b.apis))
+	for _, v := range b.apis {
+		raw, ok := reply[v.Key]
+		if !ok {
+			b.Errors[v.Key] = fmt.Errorf("batch %s: response not found", v.Key)
+			continue
+		}
+		common.Code, common.Message, common.Msg = 0, "", ""
+		if err := json.Unmarshal(raw, &common); err != nil {
+			b.Errors[v.Key] = fmt.Errorf("batch %s: json.Unmarshal: %w", v.Key, err)
+			continue
+		}
+		if v.Resp != nil {
+			if err := json.Unmarshal(raw, v.Resp); err != nil {
+				b.Errors[v.Key] = fmt.Errorf("batch %s: json.Unmarshal: %w", v.Key, err)
+				continue
+			}
+		}
+		if common.Code != http.StatusOK {
+			var message = common.Message
+			if message == "" {
+				message = common.Msg
+			}
+			b.Errors[v.Key] = &BatchItemError{Key: v.Key, Code: common.Code, Message: message}
+		}
+	}
 	return b
 }
 
+// Err 获取单个接口的错误,批处理请求本身失败时返回该错误
+func (b *Batch) Err(key string) error {
+	if b.Error != nil {
+		return b.Error
+	}
+	return b.Errors[key]
+}
+
 // Parse 解析 Batch 的 Json 数据
 func (b *Batch) Parse() (*Batch, map[string]string) {
 	jsonData := make(map[string]interface{})
@@ -107,8 +183,10 @@ func NewBatch(apis ...BatchAPI) *Batch {
 	var b = &Batch{
 		API: make(map[string]interface{}),
 	}
-	for _, api := range apis {
-		b.API[api.Key] = api.Json
-	}
-	return b
+	return b.Add(apis...)
+}
+
+// Batch 批量请求多个接口,等同于 NewBatch(apis...).Do(ctx, a.client)
+func (a *Api) Batch(ctx context.Context, apis ...BatchAPI) *Batch {
+	return NewBatch(apis...).Do(ctx, a.client)
 }
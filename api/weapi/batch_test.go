@@ -0,0 +1,87 @@
+// MIT License
+//
+// Copyright (c) 2024 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package weapi
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/fakeserver"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatch(t *testing.T) {
+	s := fakeserver.New()
+	defer s.Close()
+	s.Reply("/api/fakeserver/fail", map[string]interface{}{"code": 301, "message": "需要登录"})
+
+	client, err := api.NewClient(&api.Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+		BaseURL: s.URL,
+	}, log.Default)
+	assert.NoError(t, err)
+	defer client.Close(context.TODO())
+
+	var (
+		sign YunBeiSignInResp
+		todo YunBeiTaskTodoResp
+		fail YunBeiSignInResp
+	)
+	got := New(client).Batch(context.TODO(),
+		BatchAPI{Key: "/api/pointmall/user/sign", Req: &YunBeiSignInReq{}, Resp: &sign},
+		BatchAPI{Key: "/api/usertool/task/todo/query", Json: `{"type":1}`, Resp: &todo},
+		BatchAPI{Key: "/api/fakeserver/fail", Resp: &fail},
+	)
+	assert.NoError(t, got.Error)
+	assert.NoError(t, got.Err("/api/pointmall/user/sign"))
+	assert.True(t, sign.Data.Sign)
+	assert.NoError(t, got.Err("/api/usertool/task/todo/query"))
+	assert.Equal(t, int64(200), todo.Code)
+
+	// 单个接口失败不影响其他接口
+	var itemErr *BatchItemError
+	assert.ErrorAs(t, got.Err("/api/fakeserver/fail"), &itemErr)
+	assert.Equal(t, int64(301), itemErr.Code)
+	assert.Equal(t, "需要登录", itemErr.Message)
+	assert.Equal(t, int64(301), fail.Code)
+
+	// 合并为一次请求,参数原样传递
+	assert.Len(t, s.Requests("/api/batch"), 1)
+	todoReq := s.Requests("/api/usertool/task/todo/query")
+	assert.Len(t, todoReq, 1)
+	assert.Equal(t, "1", todoReq[0].Param("type"))
+
+	// 批量请求本身失败时所有接口返回该错误
+	s.Reply("/api/batch", map[string]interface{}{"code": -460, "message": "网络太拥挤"})
+	got = New(client).Batch(context.TODO(), BatchAPI{Key: "/api/pointmall/user/sign", Resp: &sign})
+	assert.Error(t, got.Error)
+	assert.ErrorIs(t, got.Err("/api/pointmall/user/sign"), got.Error)
+}
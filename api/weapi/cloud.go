@@ -341,7 +341,7 @@ func (a *Api) CloudUpload(ctx context.Context, req *CloudUploadReq) (*CloudUploa
 	var (
 		addr      = fmt.Sprintf("https://wanproxy.127.net/lbs?version=1.0&bucketname=%s", req.Bucket)
 		urlFormat = "%s/%s/%s"
-		ip        = "http://59.111.242.121"
+		ip        = a.client.UploadURL()
 		uploadUrl = fmt.Sprintf(urlFormat, ip, req.Bucket, objectKey)
 		reply     CloudUploadResp
 	)
@@ -351,7 +351,7 @@ func (a *Api) CloudUpload(ctx context.Context, req *CloudUploadReq) (*CloudUploa
 		NewRequest().
 		SetContext(ctx).
 		SetHeader("Referer", "https://music.163.com").
-		SetHeader("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) NeteaseMusicDesktop/2.3.17.1034"). // todo: hard code
+		SetHeader("User-Agent", a.client.UserAgent()).
 		Get(addr)
 	if err != nil || resp.StatusCode() != http.StatusOK {
 		log.Error("user default upload lbs node. get %s error: %v", addr, err)
@@ -752,7 +752,7 @@ type CloudUploadNodeResp struct {
 // needLogin: 不需要
 func (a *Api) CloudUploadNode(ctx context.Context, req *CloudUploadNodeReq) (*CloudUploadNodeResp, error) {
 	var (
-		url   = "http://wanproxy.127.net/lbs?version=%s"
+		url   = "http://wanproxy.127.net/lbs"
 		reply CloudUploadNodeResp
 		opts  = api.NewOptions()
 	)
@@ -761,7 +761,6 @@ func (a *Api) CloudUploadNode(ctx context.Context, req *CloudUploadNodeReq) (*Cl
 	if req.Version == "" {
 		req.Version = "1.0"
 	}
-	url = fmt.Sprintf(url, req.Version)
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -92,12 +92,64 @@ type CommentsReq struct {
 }
 
 type CommentsResp struct {
-	IsMusician  bool          `json:"isMusician"`
-	Cnum        int64         `json:"cnum"`
-	UserId      int64         `json:"userId"`
-	TopComments []interface{} `json:"topComments"`
-	Code        int64         `json:"code"`
-	Comments    []struct {
+	IsMusician  bool                  `json:"isMusician"`
+	Cnum        int64                 `json:"cnum"`
+	UserId      int64                 `json:"userId"`
+	TopComments []interface{}         `json:"topComments"`
+	Code        int64                 `json:"code"`
+	Comments    []CommentsRespComment `json:"comments"`
+	Total       int64                 `json:"total"`
+	More        bool                  `json:"more"`
+}
+
+type CommentsRespComment struct {
+	User struct {
+		LocationInfo interface{} `json:"locationInfo"`
+		LiveInfo     interface{} `json:"liveInfo"`
+		Anonym       int64       `json:"anonym"`
+		Highlight    bool        `json:"highlight"`
+		AvatarUrl    string      `json:"avatarUrl"`
+		AvatarDetail *struct {
+			UserType        int64  `json:"userType"`
+			IdentityLevel   int64  `json:"identityLevel"`
+			IdentityIconUrl string `json:"identityIconUrl"`
+		} `json:"avatarDetail"`
+		UserType     int64       `json:"userType"`
+		Followed     bool        `json:"followed"`
+		Mutual       bool        `json:"mutual"`
+		RemarkName   interface{} `json:"remarkName"`
+		SocialUserId interface{} `json:"socialUserId"`
+		VipRights    *struct {
+			Associator *struct {
+				VipCode int64  `json:"vipCode"`
+				Rights  bool   `json:"rights"`
+				IconUrl string `json:"iconUrl"`
+			} `json:"associator"`
+			MusicPackage *struct {
+				VipCode int64  `json:"vipCode"`
+				Rights  bool   `json:"rights"`
+				IconUrl string `json:"iconUrl"`
+			} `json:"musicPackage"`
+			Redplus *struct {
+				VipCode int64  `json:"vipCode"`
+				Rights  bool   `json:"rights"`
+				IconUrl string `json:"iconUrl"`
+			} `json:"redplus"`
+			RedVipAnnualCount int64       `json:"redVipAnnualCount"`
+			RedVipLevel       int64       `json:"redVipLevel"`
+			RelationType      int64       `json:"relationType"`
+			MemberLogo        interface{} `json:"memberLogo"`
+		} `json:"vipRights"`
+		Nickname       string      `json:"nickname"`
+		AuthStatus     int64       `json:"authStatus"`
+		ExpertTags     interface{} `json:"expertTags"`
+		Experts        interface{} `json:"experts"`
+		VipType        int64       `json:"vipType"`
+		CommonIdentity interface{} `json:"commonIdentity"`
+		UserId         int64       `json:"userId"`
+		Target         interface{} `json:"target"`
+	} `json:"user"`
+	BeReplied []struct {
 		User struct {
 			LocationInfo interface{} `json:"locationInfo"`
 			LiveInfo     interface{} `json:"liveInfo"`
@@ -109,32 +161,12 @@ type CommentsResp struct {
 				IdentityLevel   int64  `json:"identityLevel"`
 				IdentityIconUrl string `json:"identityIconUrl"`
 			} `json:"avatarDetail"`
-			UserType     int64       `json:"userType"`
-			Followed     bool        `json:"followed"`
-			Mutual       bool        `json:"mutual"`
-			RemarkName   interface{} `json:"remarkName"`
-			SocialUserId interface{} `json:"socialUserId"`
-			VipRights    *struct {
-				Associator *struct {
-					VipCode int64  `json:"vipCode"`
-					Rights  bool   `json:"rights"`
-					IconUrl string `json:"iconUrl"`
-				} `json:"associator"`
-				MusicPackage *struct {
-					VipCode int64  `json:"vipCode"`
-					Rights  bool   `json:"rights"`
-					IconUrl string `json:"iconUrl"`
-				} `json:"musicPackage"`
-				Redplus *struct {
-					VipCode int64  `json:"vipCode"`
-					Rights  bool   `json:"rights"`
-					IconUrl string `json:"iconUrl"`
-				} `json:"redplus"`
-				RedVipAnnualCount int64       `json:"redVipAnnualCount"`
-				RedVipLevel       int64       `json:"redVipLevel"`
-				RelationType      int64       `json:"relationType"`
-				MemberLogo        interface{} `json:"memberLogo"`
-			} `json:"vipRights"`
+			UserType       int64       `json:"userType"`
+			Followed       bool        `json:"followed"`
+			Mutual         bool        `json:"mutual"`
+			RemarkName     interface{} `json:"remarkName"`
+			SocialUserId   interface{} `json:"socialUserId"`
+			VipRights      interface{} `json:"vipRights"`
 			Nickname       string      `json:"nickname"`
 			AuthStatus     int64       `json:"authStatus"`
 			ExpertTags     interface{} `json:"expertTags"`
@@ -144,79 +176,49 @@ type CommentsResp struct {
 			UserId         int64       `json:"userId"`
 			Target         interface{} `json:"target"`
 		} `json:"user"`
-		BeReplied []struct {
-			User struct {
-				LocationInfo interface{} `json:"locationInfo"`
-				LiveInfo     interface{} `json:"liveInfo"`
-				Anonym       int64       `json:"anonym"`
-				Highlight    bool        `json:"highlight"`
-				AvatarUrl    string      `json:"avatarUrl"`
-				AvatarDetail *struct {
-					UserType        int64  `json:"userType"`
-					IdentityLevel   int64  `json:"identityLevel"`
-					IdentityIconUrl string `json:"identityIconUrl"`
-				} `json:"avatarDetail"`
-				UserType       int64       `json:"userType"`
-				Followed       bool        `json:"followed"`
-				Mutual         bool        `json:"mutual"`
-				RemarkName     interface{} `json:"remarkName"`
-				SocialUserId   interface{} `json:"socialUserId"`
-				VipRights      interface{} `json:"vipRights"`
-				Nickname       string      `json:"nickname"`
-				AuthStatus     int64       `json:"authStatus"`
-				ExpertTags     interface{} `json:"expertTags"`
-				Experts        interface{} `json:"experts"`
-				VipType        int64       `json:"vipType"`
-				CommonIdentity interface{} `json:"commonIdentity"`
-				UserId         int64       `json:"userId"`
-				Target         interface{} `json:"target"`
-			} `json:"user"`
-			BeRepliedCommentId int64       `json:"beRepliedCommentId"`
-			Content            *string     `json:"content"`
-			RichContent        *string     `json:"richContent"`
-			Status             int64       `json:"status"`
-			ExpressionUrl      interface{} `json:"expressionUrl"`
-			IpLocation         struct {
-				Ip       interface{} `json:"ip"`
-				Location string      `json:"location"`
-				UserId   int64       `json:"userId"`
-			} `json:"ipLocation"`
-		} `json:"beReplied"`
-		PendantData *struct {
-			Id       int64  `json:"id"`
-			ImageUrl string `json:"imageUrl"`
-		} `json:"pendantData"`
-		ShowFloorComment    interface{} `json:"showFloorComment"`
-		Status              int64       `json:"status"`
-		CommentId           int64       `json:"commentId"`
-		Content             string      `json:"content"` // 评论内容
-		RichContent         *string     `json:"richContent"`
-		ContentResource     interface{} `json:"contentResource"`
-		Time                int64       `json:"time"`
-		TimeStr             string      `json:"timeStr"`
-		NeedDisplayTime     bool        `json:"needDisplayTime"`
-		LikedCount          int64       `json:"likedCount"`
-		ExpressionUrl       interface{} `json:"expressionUrl"`
-		CommentLocationType int64       `json:"commentLocationType"`
-		ParentCommentId     int64       `json:"parentCommentId"`
-		Decoration          struct {
-		} `json:"decoration"`
-		RepliedMark   interface{} `json:"repliedMark"`
-		Grade         interface{} `json:"grade"`
-		UserBizLevels interface{} `json:"userBizLevels"`
-		IpLocation    struct {
+		BeRepliedCommentId int64       `json:"beRepliedCommentId"`
+		Content            *string     `json:"content"`
+		RichContent        *string     `json:"richContent"`
+		Status             int64       `json:"status"`
+		ExpressionUrl      interface{} `json:"expressionUrl"`
+		IpLocation         struct {
 			Ip       interface{} `json:"ip"`
 			Location string      `json:"location"`
 			UserId   int64       `json:"userId"`
 		} `json:"ipLocation"`
-		Owner            bool        `json:"owner"`
-		Medal            interface{} `json:"medal"`
-		LikeAnimationMap struct {
-		} `json:"likeAnimationMap"`
-		Liked bool `json:"liked"`
-	} `json:"comments"`
-	Total int64 `json:"total"`
-	More  bool  `json:"more"`
+	} `json:"beReplied"`
+	PendantData *struct {
+		Id       int64  `json:"id"`
+		ImageUrl string `json:"imageUrl"`
+	} `json:"pendantData"`
+	ShowFloorComment    interface{} `json:"showFloorComment"`
+	Status              int64       `json:"status"`
+	CommentId           int64       `json:"commentId"`
+	Content             string      `json:"content"` // 评论内容
+	RichContent         *string     `json:"richContent"`
+	ContentResource     interface{} `json:"contentResource"`
+	Time                int64       `json:"time"`
+	TimeStr             string      `json:"timeStr"`
+	NeedDisplayTime     bool        `json:"needDisplayTime"`
+	LikedCount          int64       `json:"likedCount"`
+	ExpressionUrl       interface{} `json:"expressionUrl"`
+	CommentLocationType int64       `json:"commentLocationType"`
+	ParentCommentId     int64       `json:"parentCommentId"`
+	Decoration          struct {
+	} `json:"decoration"`
+	RepliedMark   interface{} `json:"repliedMark"`
+	Grade         interface{} `json:"grade"`
+	UserBizLevels interface{} `json:"userBizLevels"`
+	IpLocation    struct {
+		Ip       interface{} `json:"ip"`
+		Location string      `json:"location"`
+		UserId   int64       `json:"userId"`
+	} `json:"ipLocation"`
+	Owner            bool        `json:"owner"`
+	Medal            interface{} `json:"medal"`
+	LikeAnimationMap struct {
+	} `json:"likeAnimationMap"`
+	Liked bool `json:"liked"`
 }
 
 // Comments 获取歌曲评论列表
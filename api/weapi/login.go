@@ -87,11 +87,7 @@ func (a *Api) QrcodeGenerate(ctx context.Context, req *QrcodeGenerateReq) (*Qrco
 	if req.Platform == "web" {
 		var did = req.DeviceId
 		if req.DeviceId == "" {
-			if ck, ok := a.client.Cookie("https://music.163.com", "deviceId"); ok {
-				did = ck.Value
-			} else {
-				did = utils.GenerateDeviceId()
-			}
+			did = a.client.DeviceId()
 		}
 		content += fmt.Sprintf("&chainId=%s", utils.GenerateChainId(did))
 	}
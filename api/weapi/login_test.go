@@ -39,7 +39,8 @@ func TestQrcodeCreateKey(t *testing.T) {
 	}
 	got, err := cli.QrcodeCreateKey(ctx, &req)
 	assert.NoError(t, err)
-	t.Logf("QrcodeCreateKey: %+v\n", got)
+	assert.Equal(t, int64(200), got.Code)
+	assert.NotEmpty(t, got.UniKey)
 }
 
 func TestQrcodeGetReq(t *testing.T) {
@@ -60,5 +61,6 @@ func TestQrcodeCheck(t *testing.T) {
 	}
 	got, err := cli.QrcodeCheck(ctx, &req)
 	assert.NoError(t, err)
-	t.Logf("QrcodeCheck: %+v\n", got)
+	// 800: 二维码不存在或已过期
+	assert.Equal(t, int64(800), got.Code)
 }
@@ -0,0 +1,172 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package weapi
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strconv"
+)
+
+// fetchFunc 查询一页数据,offset为已经返回的记录数量,last为上一页最后一条记录(第一页为nil)用于游标分页.
+// 返回当前页记录以及是否还有下一页.
+type fetchFunc[T any] func(ctx context.Context, offset int64, last *T) ([]T, bool, error)
+
+// paginate 将分页接口包装为迭代器,依次返回每一条记录.
+// 当接口返回没有更多数据或者当前页为空时结束,出错或ctx取消时返回错误后结束.
+// 每页请求都经过 api.Client.Request 因此同样受限流以及重试策略控制.
+func paginate[T any](ctx context.Context, fetch fetchFunc[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var (
+			zero   T
+			offset int64
+			last   *T
+		)
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			items, more, err := fetch(ctx, offset, last)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if !more || len(items) == 0 {
+				return
+			}
+			offset += int64(len(items))
+			last = &items[len(items)-1]
+		}
+	}
+}
+
+// ArtistSongsAll 迭代歌手所有歌曲,req中的Offset会被忽略
+func (a *Api) ArtistSongsAll(ctx context.Context, req *ArtistSongsReq) iter.Seq2[ArtistSongsRespSongs, error] {
+	return paginate(ctx, func(ctx context.Context, offset int64, _ *ArtistSongsRespSongs) ([]ArtistSongsRespSongs, bool, error) {
+		var r = *req
+		r.Offset = offset
+		reply, err := a.ArtistSongs(ctx, &r)
+		if err != nil {
+			return nil, false, err
+		}
+		if reply.Code != 200 {
+			return nil, false, fmt.Errorf("ArtistSongs(%v) code: %v msg: %s", req.Id, reply.Code, reply.GetMessage())
+		}
+		return reply.Songs, reply.More, nil
+	})
+}
+
+// CloudListAll 迭代云盘所有歌曲,req中的Offset会被忽略
+func (a *Api) CloudListAll(ctx context.Context, req *CloudListReq) iter.Seq2[CloudListRespData, error] {
+	return paginate(ctx, func(ctx context.Context, offset int64, _ *CloudListRespData) ([]CloudListRespData, bool, error) {
+		var r = *req
+		r.Offset = offset
+		reply, err := a.CloudList(ctx, &r)
+		if err != nil {
+			return nil, false, err
+		}
+		if reply.Code != 200 {
+			return nil, false, fmt.Errorf("CloudList code: %v msg: %s", reply.Code, reply.GetMessage())
+		}
+		return reply.Data, reply.HasMore, nil
+	})
+}
+
+// PlaylistAll 迭代用户所有歌单,req中的Offset会被忽略
+func (a *Api) PlaylistAll(ctx context.Context, req *PlaylistReq) iter.Seq2[PlaylistRespList, error] {
+	return paginate(ctx, func(ctx context.Context, offset int64, _ *PlaylistRespList) ([]PlaylistRespList, bool, error) {
+		var r = *req
+		r.Offset = strconv.FormatInt(offset, 10)
+		reply, err := a.Playlist(ctx, &r)
+		if err != nil {
+			return nil, false, err
+		}
+		if reply.Code != 200 {
+			return nil, false, fmt.Errorf("Playlist(%v) code: %v msg: %s", req.Uid, reply.Code, reply.GetMessage())
+		}
+		return reply.Playlist, reply.More, nil
+	})
+}
+
+// commentsCursorOffset 评论超过5000条后需要使用上一页最后一条评论的时间作为游标
+const commentsCursorOffset = 5000
+
+// CommentsAll 迭代资源所有评论,req中的Offset以及BeforeTime会被忽略
+func (a *Api) CommentsAll(ctx context.Context, req *CommentsReq) iter.Seq2[CommentsRespComment, error] {
+	return paginate(ctx, func(ctx context.Context, offset int64, last *CommentsRespComment) ([]CommentsRespComment, bool, error) {
+		var r = *req
+		r.Offset = strconv.FormatInt(offset, 10)
+		r.BeforeTime = "0"
+		if last != nil && offset >= commentsCursorOffset {
+			r.BeforeTime = strconv.FormatInt(last.Time, 10)
+		}
+		reply, err := a.Comments(ctx, &r)
+		if err != nil {
+			return nil, false, err
+		}
+		if reply.Code != 200 {
+			return nil, false, fmt.Errorf("Comments(%v) code: %v", req.ThreadId, reply.Code)
+		}
+		return reply.Comments, reply.More, nil
+	})
+}
+
+// YunBeiExpenseAll 迭代用户所有云贝支出记录,req中的Offset会被忽略
+func (a *Api) YunBeiExpenseAll(ctx context.Context, req *YunBeiExpenseReq) iter.Seq2[YunBeiReceiptAndExpenseRespData, error] {
+	return paginate(ctx, func(ctx context.Context, offset int64, _ *YunBeiReceiptAndExpenseRespData) ([]YunBeiReceiptAndExpenseRespData, bool, error) {
+		var r = *req
+		r.Offset = offset
+		reply, err := a.YunBeiExpense(ctx, &r)
+		if err != nil {
+			return nil, false, err
+		}
+		if reply.Code != 200 {
+			return nil, false, fmt.Errorf("YunBeiExpense code: %v msg: %s", reply.Code, reply.GetMessage())
+		}
+		return reply.Data, reply.HasMore, nil
+	})
+}
+
+// YunBeiReceiptAll 迭代用户所有云贝收入记录,req中的Offset会被忽略
+func (a *Api) YunBeiReceiptAll(ctx context.Context, req *YunBeiReceiptReq) iter.Seq2[YunBeiReceiptAndExpenseRespData, error] {
+	return paginate(ctx, func(ctx context.Context, offset int64, _ *YunBeiReceiptAndExpenseRespData) ([]YunBeiReceiptAndExpenseRespData, bool, error) {
+		var r = *req
+		r.Offset = offset
+		reply, err := a.YunBeiReceipt(ctx, &r)
+		if err != nil {
+			return nil, false, err
+		}
+		if reply.Code != 200 {
+			return nil, false, fmt.Errorf("YunBeiReceipt code: %v msg: %s", reply.Code, reply.GetMessage())
+		}
+		return reply.Data, reply.HasMore, nil
+	})
+}
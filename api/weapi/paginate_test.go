@@ -0,0 +1,211 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package weapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/fakeserver"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	var (
+		offsets []int64
+		lasts   []int
+		data    = []int{1, 2, 3, 4, 5}
+	)
+	fetch := func(ctx context.Context, offset int64, last *int) ([]int, bool, error) {
+		offsets = append(offsets, offset)
+		if last != nil {
+			lasts = append(lasts, *last)
+		}
+		end := min(offset+2, int64(len(data)))
+		return data[offset:end], end < int64(len(data)), nil
+	}
+
+	var got []int
+	for v, err := range paginate(context.TODO(), fetch) {
+		assert.NoError(t, err)
+		got = append(got, v)
+	}
+	// 最后一页同样需要返回
+	assert.Equal(t, data, got)
+	assert.Equal(t, []int64{0, 2, 4}, offsets)
+	assert.Equal(t, []int{2, 4}, lasts)
+
+	// 提前结束迭代时不再请求下一页
+	offsets = nil
+	for v := range paginate(context.TODO(), fetch) {
+		if v == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []int64{0}, offsets)
+
+	// 出错
+	var count int
+	for _, err := range paginate(context.TODO(), func(ctx context.Context, offset int64, last *int) ([]int, bool, error) {
+		return nil, false, errors.New("fetch failed")
+	}) {
+		count++
+		assert.EqualError(t, err, "fetch failed")
+	}
+	assert.Equal(t, 1, count)
+
+	// ctx取消
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	for _, err := range paginate(ctx, fetch) {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+// newPaginateApi 创建请求模拟服务的Api
+func newPaginateApi(t *testing.T, s *fakeserver.Server) *Api {
+	client, err := api.NewClient(&api.Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+		BaseURL: s.URL,
+	}, log.Default)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { _ = client.Close(context.TODO()) })
+	return New(client)
+}
+
+// offsets 返回模拟服务收到的分页参数
+func offsets(s *fakeserver.Server, endpoint string) []string {
+	var list []string
+	for _, r := range s.Requests(endpoint) {
+		list = append(list, r.Param("offset"))
+	}
+	return list
+}
+
+func TestArtistSongsAll(t *testing.T) {
+	s := fakeserver.New()
+	defer s.Close()
+	// 每页返回两首歌曲,共五首
+	s.Handle("/api/v1/artist/songs", func(req *fakeserver.Request) (interface{}, error) {
+		offset, _ := strconv.Atoi(req.Param("offset"))
+		var songs []map[string]interface{}
+		for id := offset + 1; id <= min(offset+2, 5); id++ {
+			songs = append(songs, map[string]interface{}{"id": id, "name": fmt.Sprintf("song-%d", id)})
+		}
+		return map[string]interface{}{"code": 200, "more": offset+2 < 5, "songs": songs}, nil
+	})
+
+	var ids []int64
+	for song, err := range newPaginateApi(t, s).ArtistSongsAll(context.TODO(), &ArtistSongsReq{Id: 1, Limit: 2}) {
+		assert.NoError(t, err)
+		ids = append(ids, song.Id)
+	}
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, ids)
+	assert.Equal(t, []string{"0", "2", "4"}, offsets(s, "/api/v1/artist/songs"))
+}
+
+func TestCloudListAll(t *testing.T) {
+	s := fakeserver.New()
+	defer s.Close()
+	s.Handle("/api/v1/cloud/get", func(req *fakeserver.Request) (interface{}, error) {
+		offset, _ := strconv.Atoi(req.Param("offset"))
+		return map[string]interface{}{"code": 200, "hasMore": offset == 0, "data": []map[string]interface{}{{"songId": offset + 1}}}, nil
+	})
+
+	var ids []int64
+	for v, err := range newPaginateApi(t, s).CloudListAll(context.TODO(), &CloudListReq{Limit: 1, Offset: 10}) {
+		assert.NoError(t, err)
+		ids = append(ids, v.SongId)
+	}
+	assert.Equal(t, []int64{1, 2}, ids)
+	// offset为0时请求参数省略
+	assert.Equal(t, []string{"", "1"}, offsets(s, "/api/v1/cloud/get"))
+
+	// 接口返回错误码
+	s.Reply("/api/v1/cloud/get", map[string]interface{}{"code": 301, "message": "需要登录"})
+	var count int
+	for _, err := range newPaginateApi(t, s).CloudListAll(context.TODO(), &CloudListReq{}) {
+		count++
+		assert.ErrorContains(t, err, "CloudList code: 301")
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestPlaylistAll(t *testing.T) {
+	s := fakeserver.New()
+	defer s.Close()
+	s.Handle("/api/user/playlist/", func(req *fakeserver.Request) (interface{}, error) {
+		offset, _ := strconv.Atoi(req.Param("offset"))
+		return map[string]interface{}{"code": 200, "more": offset < 2, "playlist": []map[string]interface{}{{"id": offset + 1}}}, nil
+	})
+
+	var ids []int64
+	for v, err := range newPaginateApi(t, s).PlaylistAll(context.TODO(), &PlaylistReq{Uid: "1", Limit: "1"}) {
+		assert.NoError(t, err)
+		ids = append(ids, v.Id)
+	}
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+	assert.Equal(t, []string{"0", "1", "2"}, offsets(s, "/api/user/playlist/"))
+}
+
+func TestCommentsAll(t *testing.T) {
+	s := fakeserver.New()
+	defer s.Close()
+	// 第一页返回commentsCursorOffset条评论,第二页需要使用最后一条评论时间作为游标
+	s.Handle("/api/v1/resource/comments/", func(req *fakeserver.Request) (interface{}, error) {
+		if req.Param("offset") != "0" {
+			return map[string]interface{}{"code": 200, "more": false, "comments": []map[string]interface{}{{"commentId": 0, "time": 0}}}, nil
+		}
+		var comments = make([]map[string]interface{}, 0, commentsCursorOffset)
+		for i := commentsCursorOffset; i > 0; i-- {
+			comments = append(comments, map[string]interface{}{"commentId": i, "time": i * 10})
+		}
+		return map[string]interface{}{"code": 200, "more": true, "comments": comments}, nil
+	})
+
+	var count int
+	for _, err := range newPaginateApi(t, s).CommentsAll(context.TODO(), &CommentsReq{ThreadId: "R_SO_4_1", Limit: "5000", BeforeTime: "1"}) {
+		assert.NoError(t, err)
+		count++
+	}
+	assert.Equal(t, commentsCursorOffset+1, count)
+	reqs := s.Requests("/api/v1/resource/comments/R_SO_4_1")
+	if assert.Len(t, reqs, 2) {
+		assert.Equal(t, "0", reqs[0].Param("beforeTime"))
+		assert.Equal(t, "5000", reqs[1].Param("offset"))
+		assert.Equal(t, "10", reqs[1].Param("beforeTime"))
+	}
+}
@@ -34,37 +34,48 @@ import (
 func TestPartnerWeek(t *testing.T) {
 	resp, err := cli.PartnerWeek(ctx, &PartnerWeekReq{Period: "MMD-1617552000000-37-1"})
 	assert.NoError(t, err)
-	t.Logf("resp: %+v\n", resp)
+	assert.Equal(t, int64(200), resp.Code)
+	assert.Equal(t, "MMD-1617552000000-37-1", resp.Data.SectionPeriod)
 }
 
 func TestPartnerPeriod(t *testing.T) {
 	resp, err := cli.PartnerPeriod(ctx, &PartnerPeriodReq{})
 	assert.NoError(t, err)
-	t.Logf("resp: %+v\n", resp)
+	assert.Equal(t, int64(200), resp.Code)
+	assert.NotZero(t, resp.Data.Period)
 }
 
 func TestPartnerPeriodUserinfo(t *testing.T) {
 	resp, err := cli.PartnerUserinfo(ctx, &PartnerUserinfoReq{})
 	assert.NoError(t, err)
-	t.Logf("resp: %+v\n", resp)
+	assert.Equal(t, int64(200), resp.Code)
+	assert.NotZero(t, resp.Data.UserId)
+	assert.NotEmpty(t, resp.Data.Status)
 }
 
 func TestPartnerLatest(t *testing.T) {
 	resp, err := cli.PartnerLatest(ctx, &PartnerLatestReq{})
 	assert.NoError(t, err)
-	t.Logf("resp: %+v\n", resp)
+	assert.Equal(t, int64(200), resp.Code)
+	assert.NotEmpty(t, resp.Data.Periods)
+	assert.NotZero(t, resp.Data.NextPeriodStartTime)
 }
 
 func TestPartnerHome(t *testing.T) {
 	resp, err := cli.PartnerHome(ctx, &PartnerHomeReq{})
 	assert.NoError(t, err)
-	t.Logf("resp: %+v\n", resp)
+	assert.Equal(t, int64(200), resp.Code)
+	assert.NotZero(t, resp.Data.StartDate)
 }
 
 func TestPartnerTask(t *testing.T) {
 	resp, err := cli.PartnerDailyTask(ctx, &PartnerTaskReq{})
 	assert.NoError(t, err)
-	t.Logf("resp: %+v\n", resp)
+	assert.Equal(t, int64(200), resp.Code)
+	assert.LessOrEqual(t, int64(len(resp.Data.Works)), resp.Data.Count)
+	for _, w := range resp.Data.Works {
+		assert.NotZero(t, w.Work.ResourceId)
+	}
 }
 
 func TestPartnerEvaluate(t *testing.T) {
@@ -81,5 +92,5 @@ func TestPartnerEvaluate(t *testing.T) {
 		Source:        "mp-music-partner",
 	})
 	assert.NoError(t, err)
-	t.Logf("resp: %+v\n", resp)
+	assert.Equal(t, int64(200), resp.Code)
 }
@@ -26,6 +26,7 @@ package weapi
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/chaunsin/netease-cloud-music/api"
 	"github.com/chaunsin/netease-cloud-music/api/types"
@@ -330,7 +331,7 @@ type PlaylistDetailResp struct {
 // needLogin: 不需要认证
 func (a *Api) PlaylistDetail(ctx context.Context, req *PlaylistDetailReq) (*PlaylistDetailResp, error) {
 	var (
-		url   = "https://music.163.com/api/v6/playlist/detail?id=" + req.Id
+		url   = "https://music.163.com/api/v6/playlist/detail"
 		reply PlaylistDetailResp
 		opts  = api.NewOptions()
 	)
@@ -342,6 +343,7 @@ func (a *Api) PlaylistDetail(ctx context.Context, req *PlaylistDetailReq) (*Play
 		req.S = "8"
 	}
 
+	opts.Method = http.MethodGet
 	opts.CryptoMode = api.CryptoModeAPI
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -37,5 +37,7 @@ func TestPlaylist(t *testing.T) {
 	}
 	got, err := cli.Playlist(ctx, &req)
 	assert.NoError(t, err)
-	t.Logf("Playlist: %+v\n", got)
+	assert.Equal(t, int64(200), got.Code)
+	assert.NotEmpty(t, got.Playlist)
+	assert.Equal(t, int64(1289504343), got.Playlist[0].Creator.UserId)
 }
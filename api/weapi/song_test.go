@@ -34,5 +34,8 @@ import (
 func TestSongPlayer(t *testing.T) {
 	got, err := cli.SongPlayer(ctx, &SongPlayerReq{Ids: types.IntsString{2115747785}, Br: "128000"})
 	assert.NoError(t, err)
-	t.Logf("resp:%+v\n", got)
+	assert.Equal(t, int64(200), got.Code)
+	assert.Len(t, got.Data, 1)
+	assert.Equal(t, int64(2115747785), got.Data[0].Id)
+	assert.NotEmpty(t, got.Data[0].Url)
 }
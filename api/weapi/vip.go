@@ -26,6 +26,7 @@ package weapi
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/chaunsin/netease-cloud-music/api"
@@ -953,11 +954,11 @@ type VipDetailListData struct {
 // needLogin: 未知
 func (a *Api) VipDetailList(ctx context.Context, req *VipDetailListReq) (*VipDetailListResp, error) {
 	var (
-		url = fmt.Sprintf("https://interface3.music.163.com/weapi/vipnewcenter/app/level/auth/new/detail/list?isSupportHistoryGift=%v",
-			req.IsSupportHistoryGift)
+		url   = "https://interface3.music.163.com/weapi/vipnewcenter/app/level/auth/new/detail/list"
 		reply VipDetailListResp
 		opts  = api.NewOptions()
 	)
+	opts.SetQuery("isSupportHistoryGift", strconv.FormatBool(req.IsSupportHistoryGift))
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -1301,7 +1302,7 @@ func (a *Api) VipTaskSign(ctx context.Context, req *VipTaskSignReq) (*VipTaskSig
 		opts  = api.NewOptions()
 	)
 	if req.IsNew != "" {
-		url = url + "?isNew=" + req.IsNew
+		opts.SetQuery("isNew", req.IsNew)
 	}
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
@@ -52,6 +52,7 @@ func (a *Api) NeedLogin(ctx context.Context) bool {
 			}
 			log.Debug("NeedLogin: %+v", reply)
 			if reply.Code != 200 || reply.Account == nil || reply.Profile == nil {
+				a.client.Session().Expire("music.163.com/weapi/w/nuser/account/get", reply.Code)
 				return true
 			}
 			return false
@@ -26,9 +26,11 @@ package weapi
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/recorder"
 	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
 	"github.com/chaunsin/netease-cloud-music/pkg/log"
 )
@@ -39,6 +41,16 @@ var (
 )
 
 func TestMain(t *testing.M) {
+	// cookie保存到临时目录,避免测试运行时生成的cookie文件被提交. 录制需要登录的接口时通过NCM_TEST_COOKIE指定已登录的cookie文件
+	dir, err := os.MkdirTemp("", "ncm-test-")
+	if err != nil {
+		panic(err)
+	}
+	var cookieFile = filepath.Join(dir, "cookie.json")
+	if file := os.Getenv("NCM_TEST_COOKIE"); file != "" {
+		cookieFile = file
+	}
+
 	log.Default = log.New(&log.Config{
 		Level:  "debug",
 		Stdout: true,
@@ -50,11 +62,16 @@ func TestMain(t *testing.M) {
 		Retry:   0,
 		Cookie: cookie.Config{
 			Options:  nil,
-			Filepath: "../../testdata/cookie.json",
+			Filepath: cookieFile,
 			Interval: 0,
 		},
 	}
 	client := api.New(&cfg)
+	// 默认离线回放fixture,录制时访问真实服务,例如:
+	// NCM_TEST_MODE=record NCM_TEST_COOKIE=~/.ncmctl/cookie.json go test ./api/weapi/
+	recorder.New("../../testdata/fixtures/weapi", recorder.ModeFromEnv("NCM_TEST_MODE")).Install(client)
 	cli = New(client)
-	os.Exit(t.Run())
+	code := t.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
 }
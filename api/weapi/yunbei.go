@@ -27,7 +27,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	neturl "net/url"
 
 	"github.com/chaunsin/netease-cloud-music/api"
 	"github.com/chaunsin/netease-cloud-music/api/types"
@@ -1088,7 +1087,7 @@ type YunBeiTaskRecommendV2RespData struct {
 // needLogin: 未知
 func (a *Api) YunBeiTaskRecommendV2(ctx context.Context, req *YunBeiTaskRecommendV2Req) (*YunBeiTaskRecommendV2Resp, error) {
 	var (
-		url   = "https://interface3.music.163.com/weapi/usertool/task/recommend/v2?adExtJson="
+		url   = "https://interface3.music.163.com/weapi/usertool/task/recommend/v2"
 		reply YunBeiTaskRecommendV2Resp
 		opts  = api.NewOptions()
 	)
@@ -1096,8 +1095,7 @@ func (a *Api) YunBeiTaskRecommendV2(ctx context.Context, req *YunBeiTaskRecommen
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("data: %+v\n", string(data))
-	url += neturl.QueryEscape(string(data))
+	opts.SetQuery("adExtJson", string(data))
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
 	if err != nil {
@@ -1499,7 +1497,7 @@ func (a *Api) YunBeiPositionResource(ctx context.Context, req *YunBeiPositionRes
 		opts  = api.NewOptions()
 	)
 	if req.PositionCode != "" {
-		url = url + "?positionCode=" + req.PositionCode
+		opts.SetQuery("positionCode", req.PositionCode)
 	}
 
 	resp, err := a.client.Request(ctx, url, req, &reply, opts)
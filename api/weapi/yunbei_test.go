@@ -33,5 +33,6 @@ func TestYunBeiSign(t *testing.T) {
 	var req = YunBeiSignInReq{}
 	got, err := cli.YunBeiSignIn(ctx, &req)
 	assert.NoError(t, err)
-	t.Logf("YunBeiSignIn: %+v\n", got)
+	assert.Equal(t, int64(200), got.Code)
+	assert.True(t, got.Data.Sign)
 }
@@ -50,8 +50,13 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// GetDefault 返回默认配置,每次调用都会返回新的实例,避免替换魔法变量等修改影响其他调用方
 func GetDefault() *Config {
-	return defaultConfig
+	var c *Config
+	if err := yaml.Unmarshal(defaultConfigByte, &c); err != nil {
+		panic(fmt.Sprintf("defaultConfig.Unmarshal: %s", err))
+	}
+	return c
 }
 
 func New(cfgPath ...string) (*Config, error) {
@@ -103,6 +108,8 @@ func (c *Config) ReplaceMagicVariables(name, value string) (*Config, bool) {
 
 	c.Log.Rotate.Filename = os.Expand(c.Log.Rotate.Filename, mapping)
 	c.Network.Cookie.Filepath = os.Expand(c.Network.Cookie.Filepath, mapping)
+	c.Network.Cookie.Crypto.PassphraseFile = os.Expand(c.Network.Cookie.Crypto.PassphraseFile, mapping)
+	c.Network.Cookie.Database.Path = os.Expand(c.Network.Cookie.Database.Path, mapping)
 	c.Database.Path = os.Expand(c.Database.Path, mapping)
 	return c, isset
 }
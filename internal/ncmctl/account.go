@@ -0,0 +1,127 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type Account struct {
+	root *Root
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func NewAccount(root *Root, l *log.Logger) *Account {
+	c := &Account{
+		root: root,
+		l:    l,
+		cmd: &cobra.Command{
+			Use:     "account",
+			Short:   "Manage multiple account profiles",
+			Example: "  ncmctl account list\n  ncmctl account switch alice\n  ncmctl account remove alice\n  ncmctl --profile alice login qrcode",
+		},
+	}
+	c.addFlags()
+	c.Add(c.list(), c.switchTo(), c.remove())
+	return c
+}
+
+func (c *Account) addFlags() {}
+
+func (c *Account) Add(command ...*cobra.Command) {
+	c.cmd.AddCommand(command...)
+}
+
+func (c *Account) Command() *cobra.Command {
+	return c.cmd
+}
+
+func (c *Account) list() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "List account profiles",
+		Example: "  ncmctl account list",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, name := range c.root.profiles.Names() {
+				var mark = " "
+				if name == c.root.profiles.Current {
+					mark = "*"
+				}
+				cmd.Printf("%s %-32s %s\n", mark, name, c.root.WithProfile(name).Cfg.Network.Cookie.Filepath)
+			}
+			return nil
+		},
+	}
+}
+
+func (c *Account) switchTo() *cobra.Command {
+	return &cobra.Command{
+		Use:     "switch <name>",
+		Short:   "Switch the current account profile",
+		Example: "  ncmctl account switch alice",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var name = args[0]
+			if !c.root.profiles.Exists(name) {
+				return fmt.Errorf("profile %s not found", name)
+			}
+			c.root.profiles.Current = name
+			if err := c.root.profiles.Save(); err != nil {
+				return fmt.Errorf("save profiles: %w", err)
+			}
+			cmd.Printf("switch to %s\n", name)
+			return nil
+		},
+	}
+}
+
+func (c *Account) remove() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <name>",
+		Short:   "Remove account profile and its cookie, database",
+		Example: "  ncmctl account remove alice",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var name = args[0]
+			if err := c.root.profiles.Remove(name); err != nil {
+				return err
+			}
+			if err := os.RemoveAll(profileDir(c.root.home, name)); err != nil {
+				return fmt.Errorf("RemoveAll: %w", err)
+			}
+			if err := c.root.profiles.Save(); err != nil {
+				return fmt.Errorf("save profiles: %w", err)
+			}
+			cmd.Printf("remove %s success\n", name)
+			return nil
+		},
+	}
+}
@@ -69,7 +69,7 @@ func NewCloud(root *Root, l *log.Logger) *Cloud {
 		cmd: &cobra.Command{
 			Use:     "cloud",
 			Short:   "[need login] Used to upload music files to netease cloud disk",
-			Example: "  ncmctl cloud -h\n  ncmctl cloud ./mymusic.mp3\n  ncmctl cloud ./my/music/ (Use directory)",
+			Example: "  ncmctl cloud -h\n  ncmctl cloud ./mymusic.mp3\n  ncmctl cloud ./my/music/ (Use directory)\n  ncmctl cloud list",
 			Args:    cobra.RangeArgs(0, 1),
 		},
 	}
@@ -77,6 +77,7 @@ func NewCloud(root *Root, l *log.Logger) *Cloud {
 	c.cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		return c.execute(cmd.Context(), args)
 	}
+	c.Add(cloudList(c, l))
 
 	return c
 }
@@ -254,9 +255,8 @@ func (c *Cloud) execute(ctx context.Context, input []string) error {
 
 	// 刷新token过期时间
 	defer func() {
-		refresh, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{})
-		if err != nil || refresh.Code != 200 {
-			log.Warn("TokenRefresh resp:%+v err: %s", refresh, err)
+		if _, err := cli.Session().RefreshIfNeeded(ctx); err != nil {
+			log.Warn("RefreshIfNeeded: %s", err)
 		}
 	}()
 
@@ -0,0 +1,88 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+type cloudListCmd struct {
+	root *Cloud
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func cloudList(root *Cloud, l *log.Logger) *cobra.Command {
+	c := &cloudListCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:     "list",
+		Short:   "[need login] list all music files in netease cloud disk",
+		Example: "  ncmctl cloud list",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context())
+		},
+	}
+	return c.cmd
+}
+
+func (c *cloudListCmd) execute(ctx context.Context) error {
+	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	request := weapi.New(cli)
+
+	// 判断是否需要登录
+	if request.NeedLogin(ctx) {
+		return fmt.Errorf("need login")
+	}
+
+	var (
+		count int64
+		size  int64
+	)
+	for v, err := range request.CloudListAll(ctx, &weapi.CloudListReq{Limit: 200}) {
+		if err != nil {
+			return fmt.Errorf("CloudList: %w", err)
+		}
+		count++
+		size += v.FileSize
+		c.cmd.Printf("%d\t%.2fM\t%s\n", v.SongId, float64(v.FileSize)/float64(utils.MB), v.FileName)
+	}
+	c.cmd.Printf("total: %d size: %.2fM\n", count, float64(size)/float64(utils.MB))
+	return nil
+}
@@ -25,16 +25,16 @@ package ncmctl
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/hex"
+	"errors"
 	"fmt"
-	"io"
 	"net/http/httputil"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
-	"sync/atomic"
+	"sync"
+	"time"
 
 	"github.com/chaunsin/netease-cloud-music/api"
 	"github.com/chaunsin/netease-cloud-music/api/types"
@@ -66,13 +66,26 @@ type DownloadOpts struct {
 	ImmerseType string // 沉浸式类型
 	Strict      bool   // 严格模式。当开起时指定的歌曲品质不符合要求,则不进行下载
 	Tag         bool
+	Segments    int64    // 单首歌曲分段并发下载数量,1为不分段
+	Upgrade     bool     // 已下载的歌曲存在更高音质时重新下载并替换
+	Filename    string   // 文件名模板
+	Export      []string // 导出歌单、专辑播放列表文件格式 m3u8/xspf/csv
+	Report      string   // 下载报告格式 json/table
+	ReportFile  string   // 下载报告输出文件,为空时输出到终端
 }
 
+// errDownloadSkipped 歌曲已下载过,无需重复下载
+var errDownloadSkipped = errors.New("already downloaded")
+
 type Download struct {
 	root *Root
 	cmd  *cobra.Command
 	opts DownloadOpts
 	l    *log.Logger
+	// albums 专辑信息缓存,写入tag时使用
+	albums sync.Map
+	// names 文件名模板
+	names *FilenameTemplate
 }
 
 func NewDownload(root *Root, l *log.Logger) *Download {
@@ -82,7 +95,7 @@ func NewDownload(root *Root, l *log.Logger) *Download {
 		cmd: &cobra.Command{
 			Use:     "download",
 			Short:   "[need login] Download songs",
-			Example: `  ncmctl download 2161154646`,
+			Example: "  ncmctl download 2161154646\n  ncmctl download 'https://music.163.com/user/home?id=1' (All playlists of the user)",
 		},
 	}
 	c.addFlags()
@@ -103,12 +116,41 @@ func (c *Download) addFlags() {
 	c.cmd.PersistentFlags().StringVarP(&c.opts.ImmerseType, "immerse-type", "", "c51", "song immerse type")
 	c.cmd.PersistentFlags().BoolVar(&c.opts.Strict, "strict", false, "strict mode. when the downloaded song does not find the corresponding quality, it will not be downloaded.")
 	c.cmd.PersistentFlags().BoolVar(&c.opts.Tag, "tag", true, "whether to set song tag information, default enable")
+	c.cmd.PersistentFlags().BoolVar(&c.opts.Upgrade, "upgrade", false, "re-download and replace songs in the output directory when a higher quality level is available")
+	c.cmd.PersistentFlags().StringVar(&c.opts.Filename, "filename", defaultDownloadFilename, filenameUsage)
+	c.cmd.PersistentFlags().StringSliceVar(&c.opts.Export, "export", nil, "export playlist/album track list to output dir in original order. support: m3u8,xspf,csv")
+	c.cmd.PersistentFlags().StringVar(&c.opts.Report, "report", "", "print download report of every song. support: json,table")
+	c.cmd.PersistentFlags().StringVar(&c.opts.ReportFile, "report-file", "", "write download report to file instead of stdout")
+	c.cmd.PersistentFlags().Int64VarP(&c.opts.Segments, "segments", "s", 1, "number of concurrent connections per song, files are split into byte ranges. 1 means disable")
 }
 
 func (c *Download) validate() error {
 	if c.opts.Parallel <= 0 || c.opts.Parallel > 20 {
 		return fmt.Errorf("parallel <= 0 or > 10")
 	}
+	if c.opts.Segments <= 0 || c.opts.Segments > 16 {
+		return fmt.Errorf("segments <= 0 or > 16")
+	}
+	names, err := NewFilenameTemplate(c.opts.Filename)
+	if err != nil {
+		return fmt.Errorf("filename template: %w", err)
+	}
+	c.names = names
+	switch c.opts.Report {
+	case "", reportJSON, reportTable:
+	default:
+		return fmt.Errorf("[%s] report format is not support", c.opts.Report)
+	}
+	if c.opts.ReportFile != "" && c.opts.Report == "" {
+		c.opts.Report = reportJSON
+	}
+	for _, format := range c.opts.Export {
+		switch strings.ToLower(format) {
+		case exportM3U8, exportXSPF, exportCSV:
+		default:
+			return fmt.Errorf("[%s] export format is not support", format)
+		}
+	}
 
 	lv, err := strconv.ParseInt(c.opts.Level, 10, 64)
 	if err == nil {
@@ -178,9 +220,8 @@ func (c *Download) execute(ctx context.Context, args []string) error {
 
 	// 刷新token过期时间
 	defer func() {
-		refresh, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{})
-		if err != nil || refresh.Code != 200 {
-			log.Warn("TokenRefresh resp:%+v err: %s", refresh, err)
+		if _, err := cli.Session().RefreshIfNeeded(ctx); err != nil {
+			log.Warn("RefreshIfNeeded: %s", err)
 		}
 	}()
 
@@ -188,57 +229,93 @@ func (c *Download) execute(ctx context.Context, args []string) error {
 		return fmt.Errorf("MkdirIfNotExist: %w", err)
 	}
 
+	// 读取输出目录中的下载清单,用于跳过已下载的歌曲
+	manifest, err := LoadManifest(c.opts.Output)
+	if err != nil {
+		return fmt.Errorf("LoadManifest: %w", err)
+	}
+
 	// 解析处理输入的资源类型
-	songs, err := c.inputParse(ctx, args, request)
+	songs, collections, err := c.inputParse(ctx, args, request)
 	if err != nil {
 		return fmt.Errorf("inputParse: %w", err)
 	}
 
 	var (
-		total  = int64(len(songs))
-		failed atomic.Int64
+		report = newDownloadReport(songs, c.opts.Level)
 		sema   = semaphore.NewWeighted(c.opts.Parallel)
 	)
+	// 非终端环境(例如定时任务)无法开启进度条,此时不显示下载进度
 	pool, err := pb.StartPool()
 	if err != nil {
-		return fmt.Errorf("StartPool: %w", err)
-	}
-	defer func() {
-		pool.Stop()
-		c.cmd.Printf("report total: %v success: %v failed: %v\n", total, total-failed.Load(), failed.Load())
-	}()
-
-	for _, song := range songs {
-		var song = song
+		log.Debug("StartPool: %s", err)
+		pool = nil
+	} else {
+		defer pool.Stop()
+	}
+
+	for i, song := range songs {
+		var (
+			song   = song
+			record = report.Songs[i]
+		)
+		if entry, ok := manifest.Get(song.Id); ok && !c.opts.Upgrade {
+			log.Debug("download %s skip, already downloaded: %s", song.String(), entry.Path)
+			record.Status = DownloadStatusSkipped
+			record.Level = entry.Level
+			record.Br = entry.Br
+			record.Size = entry.Size
+			record.Path = manifest.Abs(entry.Path)
+			continue
+		}
 		if err := sema.Acquire(ctx, 1); err != nil {
 			return fmt.Errorf("acquire: %w", err)
 		}
 		go func() {
 			defer sema.Release(1)
-			if err := c.download(ctx, cli, request, &song, pool); err != nil {
-				failed.Add(1)
+			err := c.download(ctx, cli, request, &song, pool, manifest, record)
+			record.finish(err)
+			if err != nil && !errors.Is(err, errDownloadSkipped) {
 				log.Error("download %s err: %v", song.String(), err)
-				// c.cmd.Printf("download %s err: %v\n", song.String(), err)
-				return
 			}
 		}()
 	}
 	if err := sema.Acquire(ctx, c.opts.Parallel); err != nil {
 		return fmt.Errorf("wait: %w", err)
 	}
-	return nil
+	if pool != nil {
+		_ = pool.Stop()
+	}
+
+	report.finish()
+	c.cmd.Printf("report total: %v success: %v failed: %v skip: %v\n", report.Total, report.Success, report.Failed, report.Skipped)
+	if err := c.writeReport(report); err != nil {
+		return fmt.Errorf("writeReport: %w", err)
+	}
+
+	// 按照歌单、专辑原始顺序导出播放列表文件
+	if len(c.opts.Export) > 0 {
+		if err := c.export(collections, songs, manifest); err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+	}
+
+	// 部分歌曲下载失败时不需要输出命令使用说明,通过退出码区分
+	c.cmd.SilenceUsage = true
+	return report.Err()
 }
 
-func (c *Download) inputParse(ctx context.Context, args []string, request *weapi.Api) ([]Music, error) {
+func (c *Download) inputParse(ctx context.Context, args []string, request *weapi.Api) ([]Music, []*Collection, error) {
 	var (
-		source = make(map[string][]int64)
-		set    = make(map[int64]struct{})
-		list   []Music
+		source      = make(map[string][]int64)
+		set         = make(map[int64]struct{})
+		list        []Music
+		collections []*Collection
 	)
 	for _, arg := range args {
 		kind, id, err := Parse(arg)
 		if err != nil {
-			return nil, fmt.Errorf("Parse: %w", err)
+			return nil, nil, fmt.Errorf("Parse: %w", err)
 		}
 		if v, ok := source[kind]; ok {
 			source[kind] = append(v, id)
@@ -247,6 +324,24 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 		}
 	}
 
+	// 用户创建以及收藏的歌单按照歌单下载
+	for _, uid := range source["user"] {
+		var count int
+		for v, err := range request.PlaylistAll(ctx, &weapi.PlaylistReq{Uid: fmt.Sprintf("%d", uid)}) {
+			if err != nil {
+				return nil, nil, fmt.Errorf("Playlist(%v): %w", uid, err)
+			}
+			count++
+			if !slices.Contains(source["playlist"], v.Id) {
+				source["playlist"] = append(source["playlist"], v.Id)
+			}
+		}
+		if count <= 0 {
+			log.Warn("Playlist(%v) playlist is empty", uid)
+		}
+	}
+	delete(source, "user")
+
 	for k, ids := range source {
 		switch k {
 		case "song":
@@ -269,10 +364,10 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 					}
 					resp, err := request.SongDetail(ctx, &weapi.SongDetailReq{C: c})
 					if err != nil {
-						return nil, fmt.Errorf("SongDetail: %w", err)
+						return nil, nil, fmt.Errorf("SongDetail: %w", err)
 					}
 					if resp.Code != 200 {
-						return nil, fmt.Errorf("SongDetail err: %+v", resp)
+						return nil, nil, fmt.Errorf("SongDetail err: %+v", resp)
 					}
 					if len(resp.Songs) <= 0 {
 						log.Warn("SongDetail() Songs is empty")
@@ -280,12 +375,15 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 					}
 					for _, v := range resp.Songs {
 						list = append(list, Music{
-							Id:      v.Id,
-							Name:    v.Name,
-							Artist:  v.Ar,
-							Album:   v.Al,
-							AlbumId: v.Al.Id,
-							Time:    v.Dt,
+							Id:          v.Id,
+							Name:        v.Name,
+							Artist:      v.Ar,
+							Album:       v.Al,
+							AlbumId:     v.Al.Id,
+							Time:        v.Dt,
+							Cd:          v.Cd,
+							No:          v.No,
+							PublishTime: v.PublishTime,
 						})
 					}
 					// todo: 处理版权,状态等有效性校验
@@ -293,70 +391,71 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 			}
 		case "artist":
 			for _, id := range ids {
-				for i := 1; ; i++ {
-					artist, err := request.ArtistSongs(ctx, &weapi.ArtistSongsReq{
-						Id:           id,
-						PrivateCloud: "true",
-						WorkType:     1,
-						Order:        "hot",
-						Offset:       int64(i),
-						Limit:        500,
-					})
+				var count int
+				for v, err := range request.ArtistSongsAll(ctx, &weapi.ArtistSongsReq{
+					Id:           id,
+					PrivateCloud: "true",
+					WorkType:     1,
+					Order:        "hot",
+					Limit:        500,
+				}) {
 					if err != nil {
-						return nil, fmt.Errorf("ArtistSongs(%v): %w", id, err)
-					}
-					if artist.Code != 200 {
-						return nil, fmt.Errorf("ArtistSongs(%v) err: %+v", id, artist)
-					}
-					if len(artist.Songs) <= 0 {
-						log.Warn("ArtistSongs(%v) songs is empty", id)
-						break
-					}
-					if !artist.More {
-						break
+						return nil, nil, fmt.Errorf("ArtistSongs(%v): %w", id, err)
 					}
-					for _, v := range artist.Songs {
-						if _, ok := set[v.Id]; ok {
-							continue
-						}
-						set[id] = struct{}{}
-						list = append(list, Music{
-							Id:      v.Id,
-							Name:    v.Name,
-							Artist:  v.Ar,
-							Album:   v.Al,
-							AlbumId: v.Al.Id,
-							Time:    v.Dt,
-						})
+					count++
+					if _, ok := set[v.Id]; ok {
+						continue
 					}
+					set[v.Id] = struct{}{}
+					list = append(list, Music{
+						Id:      v.Id,
+						Name:    v.Name,
+						Artist:  v.Ar,
+						Album:   v.Al,
+						AlbumId: v.Al.Id,
+						Time:    v.Dt,
+						Cd:      v.Cd,
+						No:      v.No,
+					})
 					// todo: 处理版权,状态等有效性校验
 				}
+				if count <= 0 {
+					log.Warn("ArtistSongs(%v) songs is empty", id)
+				}
 			}
 		case "album":
 			for _, id := range ids {
 				album, err := request.Album(ctx, &weapi.AlbumReq{Id: fmt.Sprintf("%d", id)})
 				if err != nil {
-					return nil, fmt.Errorf("Album(%v): %w", id, err)
+					return nil, nil, fmt.Errorf("Album(%v): %w", id, err)
 				}
 				if album.Code != 200 {
-					return nil, fmt.Errorf("Album(%v) err: %+v", id, album)
+					return nil, nil, fmt.Errorf("Album(%v) err: %+v", id, album)
 				}
 				if len(album.Songs) <= 0 {
 					log.Warn("Album(%v) Songs is empty", id)
 					continue
 				}
+				var collection = Collection{Kind: "album", Id: id, Name: album.Album.Name}
+				for _, v := range album.Songs {
+					collection.Ids = append(collection.Ids, v.Id)
+				}
+				collections = append(collections, &collection)
 				for _, v := range album.Songs {
 					if _, ok := set[v.Id]; ok {
 						continue
 					}
 					set[id] = struct{}{}
 					list = append(list, Music{
-						Id:      v.Id,
-						Name:    v.Name,
-						Artist:  v.Ar,
-						Album:   v.Al,
-						AlbumId: v.Al.Id,
-						Time:    v.Dt,
+						Id:          v.Id,
+						Name:        v.Name,
+						Artist:      v.Ar,
+						Album:       v.Al,
+						AlbumId:     v.Al.Id,
+						Time:        v.Dt,
+						Cd:          v.Cd,
+						No:          v.No,
+						PublishTime: album.Album.PublishTime,
 					})
 				}
 				// todo: 处理版权,状态等有效性校验
@@ -365,23 +464,28 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 			for _, id := range ids {
 				playlist, err := request.PlaylistDetail(ctx, &weapi.PlaylistDetailReq{Id: fmt.Sprintf("%d", id)})
 				if err != nil {
-					return nil, fmt.Errorf("PlaylistDetail(%v): %w", id, err)
+					return nil, nil, fmt.Errorf("PlaylistDetail(%v): %w", id, err)
 				}
 				if playlist.Code != 200 {
-					return nil, fmt.Errorf("PlaylistDetail(%v) err: %+v", id, playlist)
+					return nil, nil, fmt.Errorf("PlaylistDetail(%v) err: %+v", id, playlist)
 				}
 				if playlist.Playlist.TrackIds == nil {
 					log.Warn("PlaylistDetail(%v) Tracks is nil", id)
 					continue
 				}
-				var tmp = make([]int64, 0, len(playlist.Playlist.TrackIds))
+				var (
+					tmp        = make([]int64, 0, len(playlist.Playlist.TrackIds))
+					collection = Collection{Kind: "playlist", Id: id, Name: playlist.Playlist.Name}
+				)
 				for _, v := range playlist.Playlist.TrackIds {
+					collection.Ids = append(collection.Ids, v.Id)
 					if _, ok := set[v.Id]; ok {
 						continue
 					}
 					set[v.Id] = struct{}{}
 					tmp = append(tmp, v.Id)
 				}
+				collections = append(collections, &collection)
 
 				// 分页处理
 				pages, _ := utils.SplitSlice(tmp, 500)
@@ -392,10 +496,10 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 					}
 					resp, err := request.SongDetail(ctx, &weapi.SongDetailReq{C: c})
 					if err != nil {
-						return nil, fmt.Errorf("SongDetail: %w", err)
+						return nil, nil, fmt.Errorf("SongDetail: %w", err)
 					}
 					if resp.Code != 200 {
-						return nil, fmt.Errorf("SongDetail err: %+v", resp)
+						return nil, nil, fmt.Errorf("SongDetail err: %+v", resp)
 					}
 					if len(resp.Songs) <= 0 {
 						log.Warn("SongDetail Songs is empty")
@@ -403,31 +507,36 @@ func (c *Download) inputParse(ctx context.Context, args []string, request *weapi
 					}
 					for _, v := range resp.Songs {
 						list = append(list, Music{
-							Id:      v.Id,
-							Name:    v.Name,
-							Artist:  v.Ar,
-							Album:   v.Al,
-							AlbumId: v.Al.Id,
-							Time:    v.Dt,
+							Id:          v.Id,
+							Name:        v.Name,
+							Artist:      v.Ar,
+							Album:       v.Al,
+							AlbumId:     v.Al.Id,
+							Time:        v.Dt,
+							Cd:          v.Cd,
+							No:          v.No,
+							PublishTime: v.PublishTime,
+							Playlist:    playlist.Playlist.Name,
 						})
 					}
 					// todo: 处理版权,状态等有效性校验
 				}
 			}
 		default:
-			return nil, fmt.Errorf("[%s] is not support", k)
+			return nil, nil, fmt.Errorf("[%s] is not support", k)
 		}
 	}
 	if len(list) <= 0 {
-		return nil, fmt.Errorf("input resource is empty or the song is copyrighted")
+		return nil, nil, fmt.Errorf("input resource is empty or the song is copyrighted")
 	}
-	return list, nil
+	return list, collections, nil
 }
 
-func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi.Api, music *Music, pool *pb.Pool) error {
+func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi.Api, music *Music, pool *pb.Pool, manifest *Manifest, record *DownloadRecord) error {
 	var (
 		songId    = music.Id
 		songIdStr = fmt.Sprintf("%d", songId)
+		old, _    = manifest.Get(songId)
 	)
 
 	// 下载进度条
@@ -435,21 +544,29 @@ func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi
 		Set(pb.Bytes, true).
 		Set("prefix", fixedWidthName(fmt.Sprintf("%s - %s", music.ArtistString(), music.NameString()), barNameWidth)).
 		SetTemplateString(barTemplate)
-	pool.Add(bar)
+	if pool != nil {
+		pool.Add(bar)
+	}
 	defer bar.Finish()
 
 	// 查询音乐支持哪些音质
 	qualityResp, err := request.SongMusicQuality(ctx, &weapi.SongMusicQualityReq{SongId: songIdStr})
 	if err != nil {
-		return fmt.Errorf("SongMusicQuality(%v): %w", songId, err)
+		return failure(FailureApi, fmt.Errorf("SongMusicQuality(%v): %w", songId, err))
 	}
 	if qualityResp.Code != 200 {
-		return fmt.Errorf("SongMusicQuality(%v) err: %+v", songId, qualityResp)
+		return failure(FailureApi, fmt.Errorf("SongMusicQuality(%v) err: %+v", songId, qualityResp))
 	}
 	quality, level, ok := qualityResp.Data.Qualities.FindBetter(types.Level(c.opts.Level))
 	log.Debug("SongMusicQuality(%v) quality level=%s info=%+v", songId, types.LevelString[level], quality)
 	if !ok && c.opts.Strict {
-		return fmt.Errorf("SongMusicQuality(%v) not support %v", songId, types.Level(c.opts.Level))
+		return failure(FailureQualityUnavailable, fmt.Errorf("SongMusicQuality(%v) not support %v", songId, types.Level(c.opts.Level)))
+	}
+	// 升级模式下没有更高的音质则跳过
+	if old != nil && !level.Higher(types.Level(old.Level)) {
+		log.Debug("download %s skip, no higher level than %s", music.String(), old.Level)
+		record.Level, record.Br, record.Size, record.Path = old.Level, old.Br, old.Size, manifest.Abs(old.Path)
+		return errDownloadSkipped
 	}
 
 	// // 获取下载链接地址
@@ -517,24 +634,25 @@ func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi
 	}
 	downResp, err := request.SongPlayerV1(ctx, downReq)
 	if err != nil {
-		return fmt.Errorf("SongPlayerV1(%v): %w", songId, err)
+		return failure(FailureApi, fmt.Errorf("SongPlayerV1(%v): %w", songId, err))
 	}
 	if downResp.Code != 200 {
-		return fmt.Errorf("SongPlayerV1(%v) err: %+v", songId, downResp)
+		return failure(FailureApi, fmt.Errorf("SongPlayerV1(%v) err: %+v", songId, downResp))
 	}
 	if len(downResp.Data) <= 0 {
-		return fmt.Errorf("SongPlayerV1(%v) is empty: %+v", songId, downResp)
+		return failure(FailureApi, fmt.Errorf("SongPlayerV1(%v) is empty: %+v", songId, downResp))
 	}
 	// 歌曲变灰则不能下载
 	if ret := downResp.Data[0]; ret.Code != 200 || ret.Url == "" {
 		var msg error
-		switch ret.Code {
-		case -110:
-			msg = fmt.Errorf("无音源(%v) br: %v code: %v", songId, quality.Br, ret.Code)
-		case -105: // todo: 待确定完善,目前测试发现,当用户没有会员权益时,会返回-105，其他情况可能也会返回此值
-			fallthrough
+		record.setFee(ret.Fee)
+		switch {
+		case types.Matches(ret.Code, types.ErrNoSource):
+			msg = failure(FailureNoSource, fmt.Errorf("无音源(%v) br: %v code: %v", songId, quality.Br, ret.Code))
+		case types.Matches(ret.Code, types.ErrNoPrivilege): // todo: 待确定完善,目前测试发现,当用户没有会员权益时,会返回-105，其他情况可能也会返回此值
+			msg = failure(FailureNoPrivilege, fmt.Errorf("无下载权益(%v) br: %v code: %v", songId, quality.Br, ret.Code))
 		default:
-			msg = fmt.Errorf("资源已下架或无版权(%v) br: %v code: %v", songId, quality.Br, ret.Code)
+			msg = failure(FailureNoCopyright, fmt.Errorf("资源已下架或无版权(%v) br: %v code: %v", songId, quality.Br, ret.Code))
 		}
 		log.Warn("资源已下架或无版权(%v) detail: %+v", songId, downResp)
 		return msg
@@ -542,74 +660,156 @@ func (c *Download) download(ctx context.Context, cli *api.Client, request *weapi
 
 	var (
 		// drd      = downResp.Data
-		drd      = downResp.Data[0]
-		dest     = filepath.Join(c.opts.Output, fmt.Sprintf("%s - %s.%s", music.ArtistString(), music.NameString(), strings.ToLower(drd.Type)))
-		tempName = fmt.Sprintf("download-*-%s.tmp", music.NameString())
+		drd = downResp.Data[0]
+		ext = strings.ToLower(drd.Type)
+		// replace 升级音质时需要被替换的旧文件
+		replace string
 	)
-
-	// 创建临时文件
-	file, err := os.CreateTemp(c.opts.Output, tempName)
+	name, err := c.names.Execute(c.filenameData(ctx, request, music, &drd))
 	if err != nil {
-		return fmt.Errorf("CreateTemp: %w", err)
+		return failure(FailureFilesystem, fmt.Errorf("filename: %w", err))
+	}
+	var (
+		base = filepath.Join(c.opts.Output, name)
+		dest = base + "." + ext
+	)
+	record.Level = drd.Level
+	record.Br = drd.Br
+	record.Size = drd.Size
+	record.setFee(drd.Fee)
+	record.Downgraded = types.Level(c.opts.Level).Higher(types.Level(drd.Level))
+	if record.Downgraded && c.opts.Strict {
+		return failure(FailureDowngraded, fmt.Errorf("SongPlayerV1(%v) got level %v lower than %v", songId, drd.Level, c.opts.Level))
+	}
+	if old != nil {
+		// 实际返回的音质可能会被降级(比如没有会员权益),此时不进行替换
+		if !types.Level(drd.Level).Higher(types.Level(old.Level)) || strings.EqualFold(drd.Md5, old.Md5) {
+			log.Debug("download %s skip, got level %s not higher than %s", music.String(), drd.Level, old.Level)
+			record.Level, record.Br, record.Size, record.Path = old.Level, old.Br, old.Size, manifest.Abs(old.Path)
+			return errDownloadSkipped
+		}
+		replace = manifest.Abs(old.Path)
+		if filepath.Ext(replace) == "."+ext {
+			dest = replace
+		}
+	}
+
+	// 避免文件重名,若存在同名的未完成下载文件则继续下载
+	for i := 1; dest != replace; i++ {
+		if !c.names.Reserve(dest, songIdStr) {
+			// 本次下载的其他歌曲渲染出了相同的路径
+			log.Warn("download %s filename conflict: %s", music.String(), dest)
+		} else {
+			if !utils.FileExists(dest) || utils.FileExists(dest+api.PartSuffix) {
+				break
+			}
+			// 清单中没有记录的同名文件(比如之前版本下载的文件)内容一致时直接记录到清单中
+			if sum, err := utils.MD5HexFile(dest); err == nil && strings.EqualFold(sum, drd.Md5) {
+				if err := manifest.Put(newManifestEntry(music, &drd, dest)); err != nil {
+					log.Warn("manifest put %s err: %s", dest, err)
+				}
+				record.Path = dest
+				return errDownloadSkipped
+			}
+		}
+		dest = fmt.Sprintf("%s(%d).%s", base, i, ext)
+	}
+	if err := utils.MkdirIfNotExist(filepath.Dir(dest), 0755); err != nil {
+		return failure(FailureFilesystem, fmt.Errorf("MkdirIfNotExist: %w", err))
 	}
-	defer file.Close()
 
 	bar.SetTotal(drd.Size)
 
-	// 下载
-	resp, err := cli.Download(ctx, drd.Url, nil, nil, file, bar)
+	// 下载,中断后再次执行会从.part文件继续下载,下载完成后会校验md5,开启分段时使用多连接并发下载
+	var downloadReq = &api.DownloadFileReq{
+		Url:      drd.Url,
+		Filename: dest,
+		Md5:      drd.Md5,
+		Size:     drd.Size,
+		Segments: int(c.opts.Segments),
+	}
+	result, err := cli.DownloadFile(ctx, downloadReq, bar)
 	if err != nil {
-		_ = os.Remove(file.Name())
-		return fmt.Errorf("download: %w", err)
+		return failure(FailureNetwork, fmt.Errorf("download: %w", err))
 	}
-	if c.root.Opts.Debug {
-		dump, err := httputil.DumpResponse(resp, false)
+	if c.root.Opts.Debug && result.Response != nil {
+		dump, err := httputil.DumpResponse(result.Response, false)
 		if err != nil {
 			log.Debug("DumpResponse err: %s", err)
 		} else {
 			log.Debug("Download DumpResponse: %s", dump)
 		}
 	}
-
-	size, _ := strconv.ParseFloat(resp.Header.Get("Content-Length"), 10)
-	log.Debug("id=%v downloadUrl=%v wantLevel=%v-%v realLevel=%v-%v encodeType=%v type=%v size=%0.2fM,%vKB free=%v tempFile=%s outDir=%s",
-		drd.Id, drd.Url, c.opts.Level, quality.Br, drd.Level, drd.Br, drd.EncodeType, drd.Type, size/float64(utils.MB), int64(size), types.Free(drd.Fee), file.Name(), dest)
-
-	// 校验md5文件完整性
-	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		_ = os.Remove(file.Name())
-		return fmt.Errorf("Seek: %w", err)
-	}
-	var m = md5.New()
-	if _, err := io.Copy(m, file); err != nil {
-		_ = os.Remove(file.Name())
-		return err
-	}
-	if m := hex.EncodeToString(m.Sum(nil)); m != drd.Md5 {
-		_ = os.Remove(file.Name())
-		return fmt.Errorf("file %v md5 not match, want=%s, got=%s", file.Name(), drd.Md5, m)
-	}
+	log.Debug("id=%v downloadUrl=%v wantLevel=%v-%v realLevel=%v-%v encodeType=%v type=%v size=%0.2fM,%vKB free=%v resumed=%v offset=%v outFile=%s",
+		drd.Id, drd.Url, c.opts.Level, quality.Br, drd.Level, drd.Br, drd.EncodeType, drd.Type, float64(drd.Size)/float64(utils.MB), drd.Size, types.Free(drd.Fee), result.Resumed, result.Offset, dest)
 
 	// 设置歌曲tag值
 	if c.opts.Tag {
-		// todo:
+		if err := c.setTag(ctx, cli, request, music, dest, ext); err != nil {
+			log.Warn("set %s tag err: %s", dest, err)
+		}
 	}
 
-	// 避免文件重名
-	for i := 1; utils.FileExists(dest); i++ {
-		dest = filepath.Join(c.opts.Output, fmt.Sprintf("%s - %s(%d).%s", music.ArtistString(), music.NameString(), i, strings.ToLower(drd.Type)))
-	}
-	// 显示关闭文件避免Windows系统无法重命名错误: The process cannot access the file because it is being used by another process
-	if err := file.Close(); err != nil {
-		log.Error("close %s file err: %s", file.Name(), err)
-		_ = os.Remove(file.Name())
+	if err := os.Chmod(dest, 0644); err != nil {
+		return failure(FailureFilesystem, fmt.Errorf("chmod: %w", err))
 	}
-	if err := os.Rename(file.Name(), dest); err != nil {
-		_ = os.Remove(file.Name())
-		return fmt.Errorf("rename: %w", err)
+
+	record.Path = dest
+
+	// 记录到下载清单,升级音质时删除旧文件
+	if err := manifest.Put(newManifestEntry(music, &drd, dest)); err != nil {
+		return failure(FailureFilesystem, fmt.Errorf("manifest: %w", err))
 	}
-	if err := os.Chmod(dest, 0644); err != nil {
-		return fmt.Errorf("chmod: %w", err)
+	if replace != "" && replace != dest {
+		if err := os.Remove(replace); err != nil {
+			log.Warn("remove old file %s err: %s", replace, err)
+		}
 	}
 	return nil
 }
+
+// filenameData 生成文件名模板数据
+func (c *Download) filenameData(ctx context.Context, request *weapi.Api, music *Music, drd *weapi.SongPlayerRespV1Data) *FilenameData {
+	var data = FilenameData{
+		Id:       music.Id,
+		Name:     music.Name,
+		Album:    music.Album.Name,
+		Track:    int(music.No),
+		Level:    drd.Level,
+		Br:       drd.Br,
+		Playlist: music.Playlist,
+	}
+	for _, ar := range music.Artist {
+		data.Artists = append(data.Artists, ar.Name)
+	}
+	data.Artist = strings.Join(data.Artists, ",")
+	if len(data.Artists) > 0 {
+		data.AlbumArtist = data.Artists[0]
+	}
+	data.Disc, _ = parseDisc(music.Cd)
+	if music.PublishTime > 0 {
+		data.Year = time.UnixMilli(music.PublishTime).Year()
+	}
+	// 只有模板中使用专辑歌手时才查询专辑信息
+	if c.names.Uses("AlbumArtist") {
+		if album, err := c.album(ctx, request, music.AlbumId); err != nil {
+			log.Warn("filename %s album(%v) err: %s", music.String(), music.AlbumId, err)
+		} else if album != nil && album.Album.Artist.Name != "" {
+			data.AlbumArtist = album.Album.Artist.Name
+		}
+	}
+	return &data
+}
+
+// newManifestEntry 生成下载清单记录,其中md5为服务端返回的原始文件md5,写入tag后本地文件md5会发生变化
+func newManifestEntry(music *Music, drd *weapi.SongPlayerRespV1Data, path string) ManifestEntry {
+	return ManifestEntry{
+		Id:    music.Id,
+		Name:  music.Name,
+		Level: drd.Level,
+		Br:    drd.Br,
+		Md5:   drd.Md5,
+		Size:  drd.Size,
+		Path:  path,
+	}
+}
@@ -0,0 +1,141 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+)
+
+const (
+	// manifestName 下载清单文件名,保存在下载输出目录中
+	manifestName    = ".ncmctl-manifest.json"
+	manifestVersion = 1
+)
+
+// ManifestEntry 已下载歌曲记录
+type ManifestEntry struct {
+	Id        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Level     string    `json:"level"`
+	Br        int64     `json:"br"`
+	Md5       string    `json:"md5"`
+	Size      int64     `json:"size"`
+	Path      string    `json:"path"` // 相对于输出目录的路径
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Manifest 下载清单,记录输出目录中已经下载过的歌曲,用于再次下载时跳过已下载或者升级音质
+type Manifest struct {
+	mu       sync.Mutex
+	dir      string
+	Version  int                       `json:"version"`
+	Songs    map[string]*ManifestEntry `json:"songs"`
+	Modified time.Time                 `json:"modified"`
+}
+
+// LoadManifest 读取输出目录中的下载清单,不存在时返回空清单
+func LoadManifest(dir string) (*Manifest, error) {
+	var m = Manifest{
+		dir:     dir,
+		Version: manifestVersion,
+		Songs:   make(map[string]*ManifestEntry),
+	}
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &m, nil
+		}
+		return nil, fmt.Errorf("ReadFile: %w", err)
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("manifest %s: %w", manifestName, err)
+	}
+	if m.Songs == nil {
+		m.Songs = make(map[string]*ManifestEntry)
+	}
+	return &m, nil
+}
+
+// Get 获取歌曲下载记录,当记录中的文件已经不存在时视为未下载
+func (m *Manifest) Get(id int64) (*ManifestEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.Songs[strconv.FormatInt(id, 10)]
+	if !ok || !utils.FileExists(m.Abs(entry.Path)) {
+		return nil, false
+	}
+	var cp = *entry
+	return &cp, true
+}
+
+// Put 保存歌曲下载记录并写入磁盘
+func (m *Manifest) Put(entry ManifestEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rel, err := filepath.Rel(m.dir, entry.Path); err == nil {
+		entry.Path = rel
+	}
+	entry.Path = filepath.ToSlash(entry.Path)
+	entry.UpdatedAt = time.Now()
+	m.Songs[strconv.FormatInt(entry.Id, 10)] = &entry
+	return m.save()
+}
+
+// Abs 返回记录中文件的完整路径
+func (m *Manifest) Abs(path string) string {
+	if path = filepath.FromSlash(path); filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(m.dir, path)
+}
+
+// save 先写临时文件再重命名,避免程序中断导致清单文件损坏
+func (m *Manifest) save() error {
+	m.Modified = time.Now()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	var (
+		filename = filepath.Join(m.dir, manifestName)
+		tmp      = filename + ".tmp"
+	)
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/fakeserver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifest(t *testing.T) {
+	var dir = t.TempDir()
+	m, err := LoadManifest(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, m.Songs)
+	_, ok := m.Get(1001)
+	assert.False(t, ok)
+
+	// 记录中保存相对于输出目录的路径
+	var path = filepath.Join(dir, "artist", "song.mp3")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte("song"), 0644))
+	assert.NoError(t, m.Put(ManifestEntry{Id: 1001, Name: "song", Level: "exhigh", Path: path}))
+	assert.NoFileExists(t, filepath.Join(dir, manifestName+".tmp"))
+
+	m, err = LoadManifest(dir)
+	assert.NoError(t, err)
+	entry, ok := m.Get(1001)
+	if assert.True(t, ok) {
+		assert.Equal(t, "artist/song.mp3", entry.Path)
+		assert.Equal(t, "exhigh", entry.Level)
+		assert.Equal(t, path, m.Abs(entry.Path))
+	}
+
+	// 文件被删除后视为未下载
+	assert.NoError(t, os.Remove(path))
+	_, ok = m.Get(1001)
+	assert.False(t, ok)
+
+	// 清单文件损坏
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, manifestName), []byte("{"), 0644))
+	_, err = LoadManifest(dir)
+	assert.Error(t, err)
+}
+
+func TestDownloadManifest(t *testing.T) {
+	var tests = []struct {
+		name string
+		// entry 下载前清单中的记录,file为记录中的文件内容,为空时文件不存在
+		entry   ManifestEntry
+		file    string
+		upgrade bool
+		report  string
+		// want 下载后记录中的文件内容以及音质
+		want      string
+		wantLevel string
+	}{
+		{
+			name:      "skip",
+			entry:     ManifestEntry{Id: 1002, Level: "standard", Path: "old.mp3"},
+			file:      "old",
+			report:    "report total: 1 success: 0 failed: 0 skip: 1",
+			want:      "old",
+			wantLevel: "standard",
+		},
+		{
+			name:      "file deleted",
+			entry:     ManifestEntry{Id: 1002, Level: "exhigh", Path: "old.mp3"},
+			report:    "report total: 1 success: 1 failed: 0 skip: 0",
+			want:      "fakeserver song 1002",
+			wantLevel: "exhigh",
+		},
+		{
+			name:      "upgrade",
+			entry:     ManifestEntry{Id: 1002, Level: "standard", Path: "old.mp3"},
+			file:      "old",
+			upgrade:   true,
+			report:    "report total: 1 success: 1 failed: 0 skip: 0",
+			want:      "fakeserver song 1002",
+			wantLevel: "exhigh",
+		},
+		{
+			name:      "upgrade not higher",
+			entry:     ManifestEntry{Id: 1002, Level: "lossless", Path: "old.mp3"},
+			file:      "old",
+			upgrade:   true,
+			report:    "report total: 1 success: 0 failed: 0 skip: 1",
+			want:      "old",
+			wantLevel: "lossless",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				s      = newServer(t)
+				home   = t.TempDir()
+				output = t.TempDir()
+			)
+			s.AddSong(fakeserver.Song{Id: 1002, Name: "song2", Artist: "artist", Data: []byte("fakeserver song 1002")})
+			login(t, s, home)
+
+			m, err := LoadManifest(output)
+			assert.NoError(t, err)
+			if tt.file != "" {
+				assert.NoError(t, os.WriteFile(filepath.Join(output, tt.entry.Path), []byte(tt.file), 0644))
+			}
+			assert.NoError(t, m.Put(tt.entry))
+
+			var args = []string{"download", "--tag=false", "-o", output, "1002"}
+			if tt.upgrade {
+				args = append(args, "--upgrade")
+			}
+			out, err := execute(t, s, home, args...)
+			assert.NoError(t, err)
+			assert.Contains(t, out, tt.report)
+
+			m, err = LoadManifest(output)
+			assert.NoError(t, err)
+			entry, ok := m.Get(1002)
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, tt.wantLevel, entry.Level)
+			data, err := os.ReadFile(m.Abs(entry.Path))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+			// 升级音质后不会残留旧文件
+			if entry.Path != tt.entry.Path {
+				assert.NoFileExists(t, filepath.Join(output, tt.entry.Path))
+			}
+		})
+	}
+}
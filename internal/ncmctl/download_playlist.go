@@ -0,0 +1,260 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+)
+
+const (
+	exportM3U8 = "m3u8"
+	exportXSPF = "xspf"
+	exportCSV  = "csv"
+)
+
+// Collection 歌单或专辑,保留歌曲的原始顺序用于导出播放列表
+type Collection struct {
+	Kind string // playlist/album
+	Id   int64
+	Name string
+	Ids  []int64 // 原始顺序的歌曲id,包含无版权等无法获取到详情的歌曲
+}
+
+// exportTrack 播放列表中的一首歌曲
+type exportTrack struct {
+	Music
+	Path string // 相对于输出目录的文件路径,为空表示下载失败或者歌曲不可用
+}
+
+func (t *exportTrack) title() string {
+	var artists = make([]string, 0, len(t.Artist))
+	for _, ar := range t.Artist {
+		artists = append(artists, ar.Name)
+	}
+	var title = t.Name
+	if len(artists) > 0 {
+		title = strings.Join(artists, ",") + " - " + t.Name
+	}
+	// 避免换行符破坏文件格式
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// export 在输出目录中为每个歌单、专辑导出播放列表文件,文件路径来自下载清单
+func (c *Download) export(collections []*Collection, songs []Music, manifest *Manifest) error {
+	var (
+		detail = make(map[int64]Music, len(songs))
+		names  = make(map[string]struct{})
+	)
+	for _, v := range songs {
+		detail[v.Id] = v
+	}
+
+	for _, col := range collections {
+		var tracks = make([]exportTrack, 0, len(col.Ids))
+		for _, id := range col.Ids {
+			var track = exportTrack{Music: Music{Id: id}}
+			if m, ok := detail[id]; ok {
+				track.Music = m
+			}
+			if entry, ok := manifest.Get(id); ok {
+				track.Path = filepath.ToSlash(entry.Path)
+			}
+			tracks = append(tracks, track)
+		}
+
+		// 同名的歌单使用id区分
+		var name = strings.TrimRight(utils.Filename(col.Name, "_"), " .")
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", col.Kind, col.Id)
+		}
+		if _, ok := names[strings.ToLower(name)]; ok {
+			name = fmt.Sprintf("%s(%d)", name, col.Id)
+		}
+		names[strings.ToLower(name)] = struct{}{}
+
+		for _, format := range c.opts.Export {
+			var (
+				buf    bytes.Buffer
+				err    error
+				format = strings.ToLower(format)
+			)
+			switch format {
+			case exportM3U8:
+				err = writeM3U8(&buf, col, tracks)
+			case exportXSPF:
+				err = writeXSPF(&buf, col, tracks)
+			case exportCSV:
+				err = writeCSV(&buf, tracks)
+			}
+			if err != nil {
+				return fmt.Errorf("%s(%v) %s: %w", col.Kind, col.Id, format, err)
+			}
+			var filename = filepath.Join(c.opts.Output, name+"."+format)
+			if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+				return fmt.Errorf("WriteFile: %w", err)
+			}
+			log.Debug("export %s(%v) to %s", col.Kind, col.Id, filename)
+		}
+	}
+	return nil
+}
+
+// writeM3U8 不可用的歌曲以注释的形式保留在原来的位置
+func writeM3U8(w io.Writer, col *Collection, tracks []exportTrack) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#PLAYLIST:" + strings.Join(strings.Fields(col.Name), " ") + "\n")
+	for _, t := range tracks {
+		var info = fmt.Sprintf("#EXTINF:%d,%s", t.Time/1000, t.title())
+		if t.Path == "" {
+			b.WriteString(fmt.Sprintf("# unavailable id=%d %s\n", t.Id, info))
+			continue
+		}
+		b.WriteString(info + "\n")
+		b.WriteString(t.Path + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type xspfTrack struct {
+	Location   string `xml:"location"`
+	Identifier string `xml:"identifier"`
+	Title      string `xml:"title,omitempty"`
+	Creator    string `xml:"creator,omitempty"`
+	Album      string `xml:"album,omitempty"`
+	Duration   int64  `xml:"duration,omitempty"` // 毫秒
+}
+
+// writeXSPF see: https://www.xspf.org/spec
+func writeXSPF(w io.Writer, col *Collection, tracks []exportTrack) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	var (
+		enc      = xml.NewEncoder(w)
+		playlist = xml.StartElement{
+			Name: xml.Name{Local: "playlist"},
+			Attr: []xml.Attr{
+				{Name: xml.Name{Local: "version"}, Value: "1"},
+				{Name: xml.Name{Local: "xmlns"}, Value: "http://xspf.org/ns/0/"},
+			},
+		}
+		trackList = xml.StartElement{Name: xml.Name{Local: "trackList"}}
+	)
+	enc.Indent("", "  ")
+	if err := enc.EncodeToken(playlist); err != nil {
+		return err
+	}
+	if err := enc.EncodeElement(col.Name, xml.StartElement{Name: xml.Name{Local: "title"}}); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(trackList); err != nil {
+		return err
+	}
+	for _, t := range tracks {
+		if t.Path == "" {
+			// 注释中不能包含"--"
+			var comment = strings.ReplaceAll(fmt.Sprintf(" unavailable id=%d %s ", t.Id, t.title()), "--", "- -")
+			if err := enc.EncodeToken(xml.Comment(comment)); err != nil {
+				return err
+			}
+			continue
+		}
+		var track = xspfTrack{
+			Location:   (&url.URL{Path: t.Path}).String(),
+			Identifier: fmt.Sprintf("https://music.163.com/song?id=%d", t.Id),
+			Title:      t.Name,
+			Album:      t.Album.Name,
+			Duration:   t.Time,
+		}
+		for i, ar := range t.Artist {
+			if i > 0 {
+				track.Creator += ","
+			}
+			track.Creator += ar.Name
+		}
+		if err := enc.EncodeElement(track, xml.StartElement{Name: xml.Name{Local: "track"}}); err != nil {
+			return err
+		}
+	}
+	if err := enc.EncodeToken(trackList.End()); err != nil {
+		return err
+	}
+	if err := enc.EncodeToken(playlist.End()); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeCSV 不可用的歌曲status为unavailable
+func writeCSV(w io.Writer, tracks []exportTrack) error {
+	var cw = csv.NewWriter(w)
+	if err := cw.Write([]string{"no", "id", "name", "artist", "album", "duration", "status", "path"}); err != nil {
+		return err
+	}
+	for i, t := range tracks {
+		var (
+			artists = make([]string, 0, len(t.Artist))
+			status  = "ok"
+		)
+		for _, ar := range t.Artist {
+			artists = append(artists, ar.Name)
+		}
+		if t.Path == "" {
+			status = "unavailable"
+		}
+		var record = []string{
+			strconv.Itoa(i + 1),
+			strconv.FormatInt(t.Id, 10),
+			t.Name,
+			strings.Join(artists, ","),
+			t.Album.Name,
+			strconv.FormatInt(t.Time/1000, 10),
+			status,
+			t.Path,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,109 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/api/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// golden 与testdata中的文件内容比较,指定-update时使用实际内容更新文件
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	var filename = filepath.Join("testdata", name)
+	if *update {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		assert.NoError(t, os.WriteFile(filename, got, 0644))
+	}
+	want, err := os.ReadFile(filename)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestExport(t *testing.T) {
+	var (
+		output = t.TempDir()
+		songs  = []Music{
+			{
+				Id:     1001,
+				Name:   `Song <1> & "2"`,
+				Artist: []types.Artist{{Name: "A&B"}, {Name: "C,D"}},
+				Album:  types.Album{Name: "Album <X>"},
+				Time:   180500,
+			},
+			{
+				Id:     1002,
+				Name:   "Gone -- song",
+				Artist: []types.Artist{{Name: "E"}},
+				Time:   200000,
+			},
+			{
+				Id:     1003,
+				Name:   "Multi\nLine",
+				Artist: []types.Artist{{Name: "F"}},
+				Album:  types.Album{Name: "Album, \"Y\""},
+				Time:   60000,
+			},
+		}
+		collections = []*Collection{
+			// 1004为无版权等无法获取到详情的歌曲
+			{Kind: "playlist", Id: 1, Name: "My <List>/1", Ids: []int64{1003, 1002, 1001, 1004}},
+			{Kind: "album", Id: 2, Name: "my <list>/1", Ids: []int64{1001}},
+		}
+	)
+	manifest, err := LoadManifest(output)
+	assert.NoError(t, err)
+	for id, path := range map[int64]string{
+		1001: "A&B,C,D/Song _1_ & _2_.flac",
+		1003: "F - Multi Line 100%.mp3",
+	} {
+		var abs = filepath.Join(output, filepath.FromSlash(path))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(abs), 0755))
+		assert.NoError(t, os.WriteFile(abs, []byte("song"), 0644))
+		assert.NoError(t, manifest.Put(ManifestEntry{Id: id, Path: abs}))
+	}
+
+	var c = Download{opts: DownloadOpts{Output: output, Export: []string{"m3u8", "XSPF", "csv"}}}
+	assert.NoError(t, c.export(collections, songs, manifest))
+
+	for _, format := range []string{"m3u8", "xspf", "csv"} {
+		// 歌单路径相对于输出目录
+		got, err := os.ReadFile(filepath.Join(output, "My _List__1."+format))
+		if assert.NoError(t, err) {
+			golden(t, "export/playlist."+format, got)
+		}
+		// 同名的歌单使用id区分
+		assert.FileExists(t, filepath.Join(output, "my _list__1(2)."+format))
+	}
+}
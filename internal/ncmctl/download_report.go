@@ -0,0 +1,251 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	reportJSON  = "json"
+	reportTable = "table"
+)
+
+// DownloadStatus 歌曲下载结果
+type DownloadStatus string
+
+const (
+	DownloadStatusSuccess DownloadStatus = "success"
+	DownloadStatusSkipped DownloadStatus = "skipped"
+	DownloadStatusFailed  DownloadStatus = "failed"
+)
+
+// FailureCategory 下载失败原因分类,用于自动化程序判断是否需要重试或者上传云盘
+type FailureCategory string
+
+const (
+	FailureNoSource           FailureCategory = "no_source"           // -110 无音源
+	FailureNoPrivilege        FailureCategory = "no_privilege"        // -105 没有会员权益
+	FailureNoCopyright        FailureCategory = "no_copyright"        // 资源已下架或无版权
+	FailureQualityUnavailable FailureCategory = "quality_unavailable" // 严格模式下没有指定的音质
+	FailureDowngraded         FailureCategory = "downgraded"          // 严格模式下实际返回的音质低于指定音质
+	FailureMd5Mismatch        FailureCategory = "md5_mismatch"        // 文件md5校验失败
+	FailureSizeMismatch       FailureCategory = "size_mismatch"       // 文件大小与服务端返回的不一致
+	FailureApi                FailureCategory = "api"                 // 接口请求失败
+	FailureNetwork            FailureCategory = "network"             // 下载文件失败
+	FailureFilesystem         FailureCategory = "filesystem"          // 本地文件操作失败
+	FailureCanceled           FailureCategory = "canceled"            // 下载被取消
+	FailureUnknown            FailureCategory = "unknown"
+)
+
+// downloadError 带有失败原因分类的错误
+type downloadError struct {
+	category FailureCategory
+	err      error
+}
+
+func (e *downloadError) Error() string {
+	return e.err.Error()
+}
+
+func (e *downloadError) Unwrap() error {
+	return e.err
+}
+
+func failure(category FailureCategory, err error) error {
+	return &downloadError{category: category, err: err}
+}
+
+// categoryOf 获取错误的失败原因分类
+func categoryOf(err error) FailureCategory {
+	var de *downloadError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return FailureCanceled
+	case errors.Is(err, api.ErrMd5Mismatch):
+		return FailureMd5Mismatch
+	case errors.Is(err, api.ErrSizeMismatch):
+		return FailureSizeMismatch
+	case errors.As(err, &de):
+		return de.category
+	default:
+		return FailureUnknown
+	}
+}
+
+// DownloadRecord 单首歌曲的下载结果
+type DownloadRecord struct {
+	Id           int64           `json:"id"`
+	Name         string          `json:"name"`
+	Artist       string          `json:"artist"`
+	RequestLevel string          `json:"requestLevel"` // 指定的音质
+	Level        string          `json:"level"`        // 实际下载的音质
+	Br           int64           `json:"br"`
+	Size         int64           `json:"size"`
+	Fee          int64           `json:"fee"`
+	FeeType      string          `json:"feeType"`
+	Downgraded   bool            `json:"downgraded"` // 实际音质是否低于指定音质
+	Path         string          `json:"path"`
+	Status       DownloadStatus  `json:"status"`
+	Category     FailureCategory `json:"category,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// setFee 设置付费类型
+func (r *DownloadRecord) setFee(fee int64) {
+	r.Fee = fee
+	r.FeeType = types.Free(fee).String()
+}
+
+// finish 根据下载返回的错误设置结果
+func (r *DownloadRecord) finish(err error) {
+	switch {
+	case err == nil:
+		r.Status = DownloadStatusSuccess
+	case errors.Is(err, errDownloadSkipped):
+		r.Status = DownloadStatusSkipped
+	default:
+		r.Status = DownloadStatusFailed
+		r.Category = categoryOf(err)
+		r.Error = err.Error()
+	}
+}
+
+// DownloadReport 下载报告
+type DownloadReport struct {
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt"`
+	Total      int               `json:"total"`
+	Success    int               `json:"success"`
+	Failed     int               `json:"failed"`
+	Skipped    int               `json:"skipped"`
+	Songs      []*DownloadRecord `json:"songs"`
+}
+
+func newDownloadReport(songs []Music, level string) *DownloadReport {
+	var report = DownloadReport{
+		StartedAt: time.Now(),
+		Total:     len(songs),
+		Songs:     make([]*DownloadRecord, 0, len(songs)),
+	}
+	for _, song := range songs {
+		report.Songs = append(report.Songs, &DownloadRecord{
+			Id:           song.Id,
+			Name:         song.Name,
+			Artist:       song.ArtistString(),
+			RequestLevel: level,
+		})
+	}
+	return &report
+}
+
+// finish 统计下载结果
+func (r *DownloadReport) finish() {
+	r.FinishedAt = time.Now()
+	r.Success, r.Failed, r.Skipped = 0, 0, 0
+	for _, song := range r.Songs {
+		switch song.Status {
+		case DownloadStatusSuccess:
+			r.Success++
+		case DownloadStatusSkipped:
+			r.Skipped++
+		default:
+			// 未执行的歌曲(比如被取消)视为失败
+			if song.Status == "" {
+				song.Status = DownloadStatusFailed
+				song.Category = FailureCanceled
+			}
+			r.Failed++
+		}
+	}
+}
+
+// Err 存在下载失败的歌曲时返回带有退出码的错误
+func (r *DownloadReport) Err() error {
+	switch {
+	case r.Failed == 0:
+		return nil
+	case r.Failed == r.Total:
+		return &ExitError{Code: ExitCodeFailure, Err: fmt.Errorf("all %d songs failed to download", r.Failed)}
+	default:
+		return &ExitError{Code: ExitCodePartialFailure, Err: fmt.Errorf("%d of %d songs failed to download", r.Failed, r.Total)}
+	}
+}
+
+func (r *DownloadReport) WriteJSON(w io.Writer) error {
+	var enc = json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func (r *DownloadReport) WriteTable(w io.Writer) error {
+	var tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tNAME\tARTIST\tREQUEST\tLEVEL\tBR\tSIZE\tFEE\tSTATUS\tCATEGORY\tPATH")
+	for _, s := range r.Songs {
+		var level = s.Level
+		if s.Downgraded {
+			level += "(downgraded)"
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			s.Id, runewidth.Truncate(s.Name, 30, "..."), runewidth.Truncate(s.Artist, 20, "..."), s.RequestLevel, level,
+			strconv.FormatInt(s.Br/1000, 10)+"k", fmt.Sprintf("%.2fM", float64(s.Size)/float64(utils.MB)), s.Fee,
+			s.Status, s.Category, s.Path)
+	}
+	_, _ = fmt.Fprintf(tw, "total: %d success: %d failed: %d skip: %d\n", r.Total, r.Success, r.Failed, r.Skipped)
+	return tw.Flush()
+}
+
+// writeReport 按照指定格式输出下载报告,指定文件时写入到文件中
+func (c *Download) writeReport(report *DownloadReport) error {
+	var w io.Writer = c.cmd.OutOrStdout()
+	if c.opts.ReportFile != "" {
+		file, err := os.Create(c.opts.ReportFile)
+		if err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	switch c.opts.Report {
+	case reportJSON:
+		return report.WriteJSON(w)
+	case reportTable:
+		return report.WriteTable(w)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/pkg/fakeserver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryOf(t *testing.T) {
+	var e = errors.New("err")
+	var tests = []struct {
+		name string
+		err  error
+		want FailureCategory
+	}{
+		{name: "no source", err: failure(FailureNoSource, e), want: FailureNoSource},
+		{name: "no privilege", err: failure(FailureNoPrivilege, e), want: FailureNoPrivilege},
+		{name: "no copyright", err: failure(FailureNoCopyright, e), want: FailureNoCopyright},
+		{name: "quality unavailable", err: failure(FailureQualityUnavailable, e), want: FailureQualityUnavailable},
+		{name: "downgraded", err: failure(FailureDowngraded, e), want: FailureDowngraded},
+		{name: "api", err: failure(FailureApi, e), want: FailureApi},
+		{name: "network", err: failure(FailureNetwork, e), want: FailureNetwork},
+		{name: "filesystem", err: failure(FailureFilesystem, e), want: FailureFilesystem},
+		{name: "wrapped", err: fmt.Errorf("download: %w", failure(FailureApi, e)), want: FailureApi},
+		// 下载文件时的校验错误比外层的分类更具体
+		{name: "md5 mismatch", err: failure(FailureNetwork, fmt.Errorf("download: %w", api.ErrMd5Mismatch)), want: FailureMd5Mismatch},
+		{name: "size mismatch", err: failure(FailureNetwork, fmt.Errorf("download: %w", api.ErrSizeMismatch)), want: FailureSizeMismatch},
+		{name: "canceled", err: failure(FailureNetwork, context.Canceled), want: FailureCanceled},
+		{name: "deadline exceeded", err: failure(FailureApi, context.DeadlineExceeded), want: FailureCanceled},
+		{name: "unknown", err: e, want: FailureUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, categoryOf(tt.err))
+		})
+	}
+}
+
+func TestDownloadRecordFinish(t *testing.T) {
+	var tests = []struct {
+		name     string
+		err      error
+		status   DownloadStatus
+		category FailureCategory
+	}{
+		{name: "success", status: DownloadStatusSuccess},
+		{name: "skipped", err: errDownloadSkipped, status: DownloadStatusSkipped},
+		{name: "failed", err: failure(FailureNoSource, errors.New("无音源")), status: DownloadStatusFailed, category: FailureNoSource},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r DownloadRecord
+			r.finish(tt.err)
+			assert.Equal(t, tt.status, r.Status)
+			assert.Equal(t, tt.category, r.Category)
+			if tt.category != "" {
+				assert.Equal(t, tt.err.Error(), r.Error)
+			} else {
+				assert.Empty(t, r.Error)
+			}
+		})
+	}
+}
+
+func TestDownloadReportErr(t *testing.T) {
+	var tests = []struct {
+		name    string
+		status  []DownloadStatus
+		success int
+		failed  int
+		skipped int
+		code    int // 0表示没有错误
+	}{
+		{name: "all success", status: []DownloadStatus{DownloadStatusSuccess, DownloadStatusSkipped}, success: 1, skipped: 1, code: ExitCodeOK},
+		{name: "partial failure", status: []DownloadStatus{DownloadStatusSuccess, DownloadStatusFailed}, success: 1, failed: 1, code: ExitCodePartialFailure},
+		{name: "partial failure with skipped", status: []DownloadStatus{DownloadStatusSkipped, DownloadStatusFailed}, skipped: 1, failed: 1, code: ExitCodePartialFailure},
+		{name: "all failed", status: []DownloadStatus{DownloadStatusFailed, DownloadStatusFailed}, failed: 2, code: ExitCodeFailure},
+		// 未执行的歌曲视为取消
+		{name: "not finished", status: []DownloadStatus{DownloadStatusSuccess, ""}, success: 1, failed: 1, code: ExitCodePartialFailure},
+		{name: "empty", code: ExitCodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r = DownloadReport{Total: len(tt.status)}
+			for _, s := range tt.status {
+				r.Songs = append(r.Songs, &DownloadRecord{Status: s})
+			}
+			r.finish()
+			assert.Equal(t, []int{tt.success, tt.failed, tt.skipped}, []int{r.Success, r.Failed, r.Skipped})
+			for _, s := range r.Songs {
+				assert.NotEmpty(t, s.Status)
+				if s.Category != "" {
+					assert.Equal(t, FailureCanceled, s.Category)
+				}
+			}
+
+			err := r.Err()
+			if tt.code == ExitCodeOK {
+				assert.NoError(t, err)
+				return
+			}
+			var e *ExitError
+			if assert.ErrorAs(t, err, &e) {
+				assert.Equal(t, tt.code, e.Code)
+			}
+		})
+	}
+}
+
+func TestDownloadReportWrite(t *testing.T) {
+	var r = newDownloadReport([]Music{{Id: 1001, Name: "song1"}, {Id: 1002, Name: "song2"}}, "lossless")
+	r.Songs[0].Level, r.Songs[0].Downgraded, r.Songs[0].Br = "exhigh", true, 320000
+	r.Songs[0].finish(nil)
+	r.Songs[1].finish(failure(FailureNoCopyright, errors.New("无版权")))
+	r.finish()
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.WriteJSON(&buf))
+	var got DownloadReport
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, 1, got.Failed)
+	if assert.Len(t, got.Songs, 2) {
+		assert.Equal(t, *r.Songs[0], *got.Songs[0])
+		assert.Equal(t, FailureNoCopyright, got.Songs[1].Category)
+		assert.Equal(t, "无版权", got.Songs[1].Error)
+	}
+
+	buf.Reset()
+	assert.NoError(t, r.WriteTable(&buf))
+	var lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 4) {
+		assert.True(t, strings.HasPrefix(lines[0], "ID"))
+		assert.Contains(t, lines[1], "exhigh(downgraded)")
+		assert.Contains(t, lines[1], "320k")
+		assert.Contains(t, lines[2], "no_copyright")
+		assert.Equal(t, "total: 2 success: 1 failed: 1 skip: 0", strings.TrimSpace(lines[3]))
+	}
+}
+
+func TestDownloadExitCode(t *testing.T) {
+	var tests = []struct {
+		name string
+		// available 可以下载的歌曲,其余歌曲返回-110无音源
+		available []int64
+		code      int
+	}{
+		{name: "success", available: []int64{1001, 1002}, code: ExitCodeOK},
+		{name: "partial failure", available: []int64{1001}, code: ExitCodePartialFailure},
+		{name: "all failed", code: ExitCodeFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				s      = newServer(t)
+				home   = t.TempDir()
+				output = t.TempDir()
+				report = filepath.Join(t.TempDir(), "report.json")
+				data   = map[int64][]byte{1001: []byte("fakeserver song 1001"), 1002: []byte("fakeserver song 1002")}
+			)
+			for id, v := range data {
+				s.AddSong(fakeserver.Song{Id: id, Name: fmt.Sprintf("song%d", id), Artist: "artist", Data: v})
+			}
+			s.Handle("/api/song/enhance/player/url/v1", func(req *fakeserver.Request) (interface{}, error) {
+				var list []map[string]interface{}
+				for id, v := range data {
+					if !strings.Contains(req.Param("ids"), fmt.Sprintf("%d", id)) {
+						continue
+					}
+					if !slices.Contains(tt.available, id) {
+						list = append(list, map[string]interface{}{"id": id, "code": -110, "url": nil})
+						continue
+					}
+					sum := md5.Sum(v)
+					list = append(list, map[string]interface{}{
+						"id":    id,
+						"code":  200,
+						"url":   fmt.Sprintf("%s/fakeserver/song/%d.mp3", s.URL, id),
+						"md5":   hex.EncodeToString(sum[:]),
+						"size":  len(v),
+						"type":  "mp3",
+						"level": "exhigh",
+						"br":    320000,
+					})
+				}
+				return map[string]interface{}{"code": 200, "data": list}, nil
+			})
+			login(t, s, home)
+
+			_, err := execute(t, s, home, "download", "--tag=false", "-o", output, "--report", "json", "--report-file", report, "1001", "1002")
+			if tt.code == ExitCodeOK {
+				assert.NoError(t, err)
+			} else {
+				var e *ExitError
+				if assert.ErrorAs(t, err, &e) {
+					assert.Equal(t, tt.code, e.Code)
+				}
+			}
+
+			content, err := os.ReadFile(report)
+			assert.NoError(t, err)
+			var got DownloadReport
+			assert.NoError(t, json.Unmarshal(content, &got))
+			assert.Equal(t, 2, got.Total)
+			assert.Equal(t, len(tt.available), got.Success)
+			for _, song := range got.Songs {
+				if slices.Contains(tt.available, song.Id) {
+					assert.Equal(t, DownloadStatusSuccess, song.Status)
+				} else {
+					assert.Equal(t, DownloadStatusFailed, song.Status)
+					assert.Equal(t, FailureNoSource, song.Category)
+				}
+			}
+		})
+	}
+}
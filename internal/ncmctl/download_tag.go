@@ -0,0 +1,150 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/ncm/tag"
+)
+
+// setTag 写入歌曲tag信息,包括标题、专辑、歌手、专辑歌手、音轨号、碟号、年份、封面以及歌词。
+// 专辑、歌词、封面获取失败时仅跳过对应的信息
+func (c *Download) setTag(ctx context.Context, cli *api.Client, request *weapi.Api, music *Music, dest, format string) error {
+	tagger, err := tag.New(dest, format)
+	if err != nil {
+		log.Warn("tag %s skip: %s", dest, err)
+		return nil
+	}
+
+	var song = tag.Song{
+		Title:    music.Name,
+		Album:    music.Album.Name,
+		CoverUrl: music.Album.PicUrl,
+	}
+	for _, ar := range music.Artist {
+		song.Artists = append(song.Artists, ar.Name)
+	}
+	song.DiscNumber, song.DiscTotal = parseDisc(music.Cd)
+	song.TrackNumber = int(music.No)
+	if music.PublishTime > 0 {
+		song.Year = time.UnixMilli(music.PublishTime).Year()
+	}
+
+	// 专辑信息
+	if album, err := c.album(ctx, request, music.AlbumId); err != nil {
+		log.Warn("tag %s album(%v) err: %s", music.String(), music.AlbumId, err)
+	} else if album != nil {
+		for _, ar := range album.Album.Artists {
+			song.AlbumArtists = append(song.AlbumArtists, ar.Name)
+		}
+		if song.Album == "" {
+			song.Album = album.Album.Name
+		}
+		if song.CoverUrl == "" {
+			song.CoverUrl = album.Album.PicUrl
+		}
+		if song.Year <= 0 && album.Album.PublishTime > 0 {
+			song.Year = time.UnixMilli(album.Album.PublishTime).Year()
+		}
+		// 统计碟数以及当前碟中的曲目数
+		var tracks = make(map[int]int)
+		for i, v := range album.Songs {
+			disc, _ := parseDisc(v.Cd)
+			tracks[disc]++
+			if disc > song.DiscTotal {
+				song.DiscTotal = disc
+			}
+			if v.Id == music.Id && song.TrackNumber <= 0 {
+				song.TrackNumber = i + 1
+			}
+		}
+		song.TrackTotal = tracks[song.DiscNumber]
+	}
+
+	// 封面
+	if song.CoverUrl != "" {
+		var buf bytes.Buffer
+		if _, err := cli.Download(ctx, song.CoverUrl, nil, nil, &buf, nil); err != nil {
+			log.Warn("tag %s download cover %s err: %s", music.String(), song.CoverUrl, err)
+		} else {
+			song.Cover = buf.Bytes()
+		}
+	}
+
+	// 歌词
+	lyric, err := request.LyricV1(ctx, &weapi.LyricV1Req{Id: music.Id, TV: -1, LV: -1, RV: -1, KV: -1})
+	if err != nil {
+		log.Warn("tag %s LyricV1 err: %s", music.String(), err)
+	} else if lyric.Code != 200 {
+		log.Warn("tag %s LyricV1 err: %+v", music.String(), lyric)
+	} else if !lyric.PureMusic {
+		song.Lyrics = lyric.Lrc.Lyric
+	}
+
+	if err := tag.SetSong(tagger, &song); err != nil {
+		return fmt.Errorf("SetSong: %w", err)
+	}
+	return nil
+}
+
+// album 获取专辑信息,同一专辑的歌曲只请求一次
+func (c *Download) album(ctx context.Context, request *weapi.Api, id int64) (*weapi.AlbumResp, error) {
+	if id <= 0 {
+		return nil, nil
+	}
+	if v, ok := c.albums.Load(id); ok {
+		return v.(*weapi.AlbumResp), nil
+	}
+	album, err := request.Album(ctx, &weapi.AlbumReq{Id: fmt.Sprintf("%d", id)})
+	if err != nil {
+		return nil, fmt.Errorf("Album: %w", err)
+	}
+	if album.Code != 200 {
+		return nil, fmt.Errorf("Album err: %+v", album)
+	}
+	c.albums.Store(id, album)
+	return album, nil
+}
+
+// parseDisc 解析碟号,eg: "1" "01" "1/2",无法解析时返回0
+func parseDisc(cd string) (int, int) {
+	var (
+		list      = strings.SplitN(strings.TrimSpace(cd), "/", 2)
+		number, _ = strconv.Atoi(list[0])
+		total     int
+	)
+	if len(list) > 1 {
+		total, _ = strconv.Atoi(list[1])
+	}
+	return number, total
+}
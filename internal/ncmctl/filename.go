@@ -0,0 +1,219 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+)
+
+const (
+	// defaultDownloadFilename 下载歌曲默认文件名模板
+	defaultDownloadFilename = "{{.Artist}} - {{.Name}}"
+	// defaultNCMFilename ncm转换默认文件名模板,与源文件同名
+	defaultNCMFilename = "{{.Filename}}"
+
+	// filenameUsage 文件名模板使用说明
+	filenameUsage = `output filename template without extension, "/" creates sub directories. see text/template.
+fields: .Id .Name .Artist .Artists .Album .AlbumArtist .Track .Disc .Year .Level .Br .Playlist .Filename(ncm source file name)
+funcs: default, eg: {{default "Unknown" .Album}}
+example: '{{.Artist}}/{{.Album}}/{{printf "%02d" .Track}} - {{.Name}}'`
+)
+
+// FilenameData 文件名模板可以使用的字段,字符串字段在渲染前已经去除了文件名中的非法字符
+type FilenameData struct {
+	Id          int64    // 歌曲id
+	Name        string   // 歌曲名
+	Artist      string   // 歌手,多个歌手使用","分隔
+	Artists     []string // 歌手列表
+	Album       string   // 专辑名
+	AlbumArtist string   // 专辑歌手,通常为专辑的第一个歌手
+	Track       int      // 音轨号
+	Disc        int      // 碟号
+	Year        int      // 发行年份
+	Level       string   // 实际下载的音质,eg: lossless
+	Br          int64    // 码率
+	Playlist    string   // 歌单名,非歌单下载时为空
+	Filename    string   // 源文件名(不包含扩展名),仅ncm转换时有值
+}
+
+// FilenameTemplate 文件名模板,同时记录已分配的路径用于检测不同歌曲渲染出相同路径的冲突
+type FilenameTemplate struct {
+	tpl    *template.Template
+	fields map[string]bool // 模板中引用的字段
+	mu     sync.Mutex
+	used   map[string]string // 路径 -> 占用者
+}
+
+// NewFilenameTemplate 解析文件名模板,并使用示例数据进行校验
+func NewFilenameTemplate(text string) (*FilenameTemplate, error) {
+	tpl, err := template.New("filename").
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"default": defaultValue}).
+		Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	var t = FilenameTemplate{tpl: tpl, fields: make(map[string]bool), used: make(map[string]string)}
+	for _, v := range tpl.Templates() {
+		if v.Tree != nil {
+			walkFields(v.Tree.Root, t.fields)
+		}
+	}
+	if _, err := t.Execute(&FilenameData{Id: 1, Name: "name", Artist: "artist", Filename: "filename"}); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Execute 渲染得到不包含扩展名的相对路径,模板中的"/"会作为目录分隔符,每一级目录名都会去除非法字符
+func (t *FilenameTemplate) Execute(data *FilenameData) (string, error) {
+	var (
+		d   = data.sanitize()
+		buf strings.Builder
+	)
+	if err := t.tpl.Execute(&buf, &d); err != nil {
+		return "", fmt.Errorf("execute: %w", err)
+	}
+
+	var list = make([]string, 0)
+	for _, name := range strings.FieldsFunc(buf.String(), func(r rune) bool { return r == '/' || r == '\\' }) {
+		// Windows中文件名不能以空格或者点结尾
+		name = strings.TrimRight(utils.Filename(name, "_"), " .")
+		switch name {
+		case "":
+			continue
+		case ".", "..":
+			name = "_"
+		}
+		list = append(list, name)
+	}
+	if len(list) == 0 {
+		return "", errors.New("filename template result is empty")
+	}
+	return filepath.Join(list...), nil
+}
+
+// Uses 模板中是否引用了指定字段,用于只在需要时查询额外的信息
+func (t *FilenameTemplate) Uses(field string) bool {
+	return t.fields[field]
+}
+
+// walkFields 遍历模板语法树,收集所有引用的字段名,包括 {{with}} {{range}} 作用域以及变量中的字段
+func walkFields(node parse.Node, fields map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, v := range n.Nodes {
+			walkFields(v, fields)
+		}
+	case *parse.ActionNode:
+		walkFields(n.Pipe, fields)
+	case *parse.IfNode:
+		walkFields(&n.BranchNode, fields)
+	case *parse.RangeNode:
+		walkFields(&n.BranchNode, fields)
+	case *parse.WithNode:
+		walkFields(&n.BranchNode, fields)
+	case *parse.BranchNode:
+		walkFields(n.Pipe, fields)
+		walkFields(n.List, fields)
+		walkFields(n.ElseList, fields)
+	case *parse.TemplateNode:
+		walkFields(n.Pipe, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, v := range n.Cmds {
+			walkFields(v, fields)
+		}
+	case *parse.CommandNode:
+		for _, v := range n.Args {
+			walkFields(v, fields)
+		}
+	case *parse.ChainNode:
+		walkFields(n.Node, fields)
+		for _, v := range n.Field {
+			fields[v] = true
+		}
+	case *parse.FieldNode:
+		for _, v := range n.Ident {
+			fields[v] = true
+		}
+	case *parse.VariableNode:
+		for _, v := range n.Ident[1:] {
+			fields[v] = true
+		}
+	}
+}
+
+// Reserve 占用路径,当路径已经被其他占用者使用时返回false。路径比较时忽略大小写以兼容大小写不敏感的文件系统
+func (t *FilenameTemplate) Reserve(path, owner string) bool {
+	var key = strings.ToLower(filepath.Clean(path))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if v, ok := t.used[key]; ok && v != owner {
+		return false
+	}
+	t.used[key] = owner
+	return true
+}
+
+func (d *FilenameData) sanitize() FilenameData {
+	var (
+		cp    = *d
+		clean = func(s string) string { return utils.Filename(s, "_") }
+	)
+	cp.Name = clean(d.Name)
+	cp.Artist = clean(d.Artist)
+	cp.Album = clean(d.Album)
+	cp.AlbumArtist = clean(d.AlbumArtist)
+	cp.Level = clean(d.Level)
+	cp.Playlist = clean(d.Playlist)
+	cp.Filename = clean(d.Filename)
+	cp.Artists = make([]string, 0, len(d.Artists))
+	for _, v := range d.Artists {
+		cp.Artists = append(cp.Artists, clean(v))
+	}
+	return cp
+}
+
+// defaultValue 值为空时返回默认值
+func defaultValue(def string, value any) any {
+	if v := reflect.ValueOf(value); !v.IsValid() || v.IsZero() {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,171 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilenameTemplateExecute(t *testing.T) {
+	var data = FilenameData{
+		Id:          1001,
+		Name:        `Song: "Live"?`,
+		Artist:      "AC/DC",
+		Artists:     []string{"AC/DC", "a|b"},
+		Album:       "Album",
+		AlbumArtist: "AC/DC",
+		Track:       3,
+		Disc:        1,
+		Level:       "lossless",
+	}
+	var tests = []struct {
+		name    string
+		text    string
+		data    FilenameData
+		want    string
+		wantErr bool
+	}{
+		{name: "default", text: defaultDownloadFilename, data: data, want: `AC_DC - Song_ _Live__`},
+		{name: "sub directory", text: `{{.AlbumArtist}}/{{.Album}}/{{printf "%02d" .Track}} - {{.Name}}`, data: data, want: filepath.Join("AC_DC", "Album", `03 - Song_ _Live__`)},
+		{name: "backslash directory", text: `{{.Album}}\{{.Id}}`, data: data, want: filepath.Join("Album", "1001")},
+		{name: "artists", text: `{{range .Artists}}{{.}} {{end}}{{.Id}}`, data: data, want: "AC_DC a_b 1001"},
+		{name: "default func", text: `{{default "Unknown" .Playlist}}/{{default "0" .Year}}/{{.Name}}`, data: FilenameData{Name: "song"}, want: filepath.Join("Unknown", "0", "song")},
+		{name: "default func with value", text: `{{default "Unknown" .Album}}`, data: data, want: "Album"},
+		{name: "empty directory", text: `{{.Playlist}}/{{.Name}}`, data: FilenameData{Name: "song"}, want: "song"},
+		{name: "parent directory", text: `../{{.Name}}`, data: FilenameData{Name: "song"}, want: "song"},
+		{name: "trailing dot and space", text: `{{.Name}}`, data: FilenameData{Name: "song. "}, want: "song"},
+		{name: "empty", text: `{{.Filename}}`, data: FilenameData{Name: "song"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := NewFilenameTemplate(tt.text)
+			if !assert.NoError(t, err) {
+				return
+			}
+			got, err := tpl.Execute(&tt.data)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewFilenameTemplate(t *testing.T) {
+	var tests = []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{name: "default", text: defaultDownloadFilename},
+		{name: "ncm", text: defaultNCMFilename},
+		{name: "syntax", text: `{{.Name`, wantErr: true},
+		{name: "unknown field", text: `{{.Unknown}}`, wantErr: true},
+		{name: "unknown func", text: `{{upper .Name}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFilenameTemplate(tt.text)
+			assert.Equal(t, tt.wantErr, err != nil, "err: %v", err)
+		})
+	}
+}
+
+func TestFilenameDataSanitize(t *testing.T) {
+	var data = FilenameData{
+		Name:        "a/b",
+		Artist:      `a\b`,
+		Artists:     []string{"a:b", "a*b"},
+		Album:       "a?b",
+		AlbumArtist: `a"b`,
+		Level:       "a<b>",
+		Playlist:    "a|b",
+		Filename:    " a/b ",
+	}
+	var got = data.sanitize()
+	assert.Equal(t, FilenameData{
+		Name:        "a_b",
+		Artist:      "a_b",
+		Artists:     []string{"a_b", "a_b"},
+		Album:       "a_b",
+		AlbumArtist: "a_b",
+		Level:       "a_b_",
+		Playlist:    "a_b",
+		Filename:    "a_b",
+	}, got)
+	// 不修改原始数据
+	assert.Equal(t, "a/b", data.Name)
+	assert.Equal(t, "a:b", data.Artists[0])
+}
+
+func TestFilenameTemplateReserve(t *testing.T) {
+	tpl, err := NewFilenameTemplate(defaultDownloadFilename)
+	assert.NoError(t, err)
+
+	var tests = []struct {
+		path  string
+		owner string
+		want  bool
+	}{
+		{path: filepath.Join("out", "a.mp3"), owner: "1001", want: true},
+		{path: filepath.Join("out", "a.mp3"), owner: "1001", want: true},
+		{path: filepath.Join("out", "a.mp3"), owner: "1002", want: false},
+		{path: filepath.Join("out", "A.MP3"), owner: "1002", want: false},
+		{path: filepath.Join("out", "sub", "..", "a.mp3"), owner: "1002", want: false},
+		{path: filepath.Join("out", "a(1).mp3"), owner: "1002", want: true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tpl.Reserve(tt.path, tt.owner), "Reserve(%s, %s)", tt.path, tt.owner)
+	}
+}
+
+func TestFilenameTemplateUses(t *testing.T) {
+	var tests = []struct {
+		text string
+		want bool
+	}{
+		{text: defaultDownloadFilename, want: false},
+		{text: `{{.AlbumArtist}}/{{.Name}}`, want: true},
+		{text: `{{default "Unknown" .AlbumArtist}}`, want: true},
+		{text: `{{with .AlbumArtist}}{{.}}/{{end}}{{.Name}}`, want: true},
+		{text: `{{with .}}{{.AlbumArtist}}{{end}}{{.Name}}`, want: true},
+		{text: `{{with $d := .}}{{$d.AlbumArtist}}{{end}}{{.Name}}`, want: true},
+		{text: `{{if .Album}}{{.Album}}{{else}}{{.AlbumArtist}}{{end}}/{{.Name}}`, want: true},
+		{text: `{{.Name}} .AlbumArtist`, want: false},
+		{text: `{{/* .AlbumArtist */}}{{.Name}}`, want: false},
+		{text: `{{print ".AlbumArtist"}}{{.Name}}`, want: false},
+	}
+	for _, tt := range tests {
+		tpl, err := NewFilenameTemplate(tt.text)
+		if !assert.NoError(t, err, tt.text) {
+			continue
+		}
+		assert.Equal(t, tt.want, tpl.Uses("AlbumArtist"), tt.text)
+	}
+}
@@ -24,14 +24,21 @@
 package ncmctl
 
 import (
+	"github.com/chaunsin/netease-cloud-music/api"
 	"github.com/chaunsin/netease-cloud-music/pkg/log"
 
 	"github.com/spf13/cobra"
 )
 
+type LoginOpts struct {
+	Crypto         string // cookie文件加密方式
+	PassphraseFile string // cookie文件加密口令文件
+}
+
 type Login struct {
 	root *Root
 	cmd  *cobra.Command
+	opts LoginOpts
 	l    *log.Logger
 }
 
@@ -42,7 +49,7 @@ func NewLogin(root *Root, l *log.Logger) *Login {
 		cmd: &cobra.Command{
 			Use:     "login",
 			Short:   "Login netease cloud music",
-			Example: "  ncmctl login -h\n  ncmctl login qrcode\n  ncmctl login phone\n  ncmctl login cookiecloud\n  ncmctl login cookie",
+			Example: "  ncmctl login -h\n  ncmctl login qrcode\n  ncmctl login phone\n  ncmctl login cookiecloud\n  ncmctl login cookie\n  ncmctl login status",
 		},
 	}
 	c.addFlags()
@@ -50,11 +57,27 @@ func NewLogin(root *Root, l *log.Logger) *Login {
 	c.Add(phone(c, l))
 	c.Add(cookieCloud(c, l))
 	c.Add(cookie(c, l))
+	c.Add(status(c, l))
 
 	return c
 }
 
-func (c *Login) addFlags() {}
+func (c *Login) addFlags() {
+	c.cmd.PersistentFlags().StringVar(&c.opts.Crypto, "cookie.crypto", "", "cookie file encryption backend [none|passphrase|keyring], default use the config file setting. passphrase read from env NCM_COOKIE_PASSPHRASE")
+	c.cmd.PersistentFlags().StringVar(&c.opts.PassphraseFile, "cookie.passphraseFile", "", "cookie file encryption passphrase file")
+}
+
+// network 返回应用了命令行cookie加密参数的网络配置
+func (c *Login) network() *api.Config {
+	var cfg = *c.root.Cfg.Network
+	if c.opts.Crypto != "" {
+		cfg.Cookie.Crypto.Backend = c.opts.Crypto
+	}
+	if c.opts.PassphraseFile != "" {
+		cfg.Cookie.Crypto.PassphraseFile = c.opts.PassphraseFile
+	}
+	return &cfg
+}
 
 func (c *Login) Add(command ...*cobra.Command) {
 	c.cmd.AddCommand(command...)
@@ -281,7 +281,7 @@ func (c *loginCookieCmd) execute(ctx context.Context, args []string) error {
 		return fmt.Errorf("failed to parse domain URL: %v", err)
 	}
 
-	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	cli, err := api.NewClient(c.root.network(), c.l)
 	if err != nil {
 		return fmt.Errorf("NewClient: %w", err)
 	}
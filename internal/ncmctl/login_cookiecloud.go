@@ -101,17 +101,18 @@ func (c *loginCookieCloudCmd) execute(_ctx context.Context, _ []string) error {
 	ctx, cancel := context.WithTimeout(_ctx, c.timeout)
 	defer cancel()
 
-	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	cli, err := api.NewClient(c.root.network(), c.l)
 	if err != nil {
 		return fmt.Errorf("NewClient: %w", err)
 	}
 	defer cli.Close(ctx)
 
 	var cfg = cookiecloud.Config{
-		ApiUrl:  c.server,
-		Timeout: c.timeout,
-		Retry:   3,
-		Debug:   c.root.root.Opts.Debug,
+		ApiUrl:    c.server,
+		Timeout:   c.timeout,
+		Retry:     3,
+		Debug:     c.root.root.Opts.Debug,
+		Transport: c.root.root.Cfg.Network.Transport,
 	}
 	cc, err := cookiecloud.NewClient(&cfg)
 	if err != nil {
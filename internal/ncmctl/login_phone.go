@@ -24,18 +24,54 @@
 package ncmctl
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
 	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/database"
 	"github.com/chaunsin/netease-cloud-music/pkg/log"
 
 	"github.com/spf13/cobra"
 )
 
+const (
+	smsResendInterval = time.Minute    // 短信重发间隔
+	smsDailyLimit     = 5              // 24小时内最多发送短信次数
+	smsMaxAttempts    = 5              // 验证码最多输入次数
+	smsLimitPeriod    = time.Hour * 24 // 发送次数统计周期
+)
+
+var (
+	cellphoneCNRegexp = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	cellphoneRegexp   = regexp.MustCompile(`^\d{5,15}$`)
+	captchaRegexp     = regexp.MustCompile(`^\d{4,8}$`)
+)
+
+// phoneState 手机号登录状态
+type phoneState int
+
+const (
+	phoneStatePassword phoneState = iota // 密码登录
+	phoneStateSend                       // 发送短信验证码
+	phoneStateInput                      // 等待输入验证码
+	phoneStateVerify                     // 校验验证码
+	phoneStateLogin                      // 验证码登录
+	phoneStateDone                       // 登录成功
+)
+
+// errSendOnly 只发送验证码,不进行登录
+var errSendOnly = errors.New("send only")
+
 type loginPhoneCmd struct {
 	root *Login
 	cmd  *cobra.Command
@@ -44,6 +80,8 @@ type loginPhoneCmd struct {
 	timeout     time.Duration // 登录超时时间
 	countrycode int64
 	password    string
+	code        string // 短信验证码,为-时从标准输入读取
+	send        bool   // 只发送验证码
 }
 
 func phone(root *Login, l *log.Logger) *cobra.Command {
@@ -52,9 +90,17 @@ func phone(root *Login, l *log.Logger) *cobra.Command {
 		l:    l,
 	}
 	c.cmd = &cobra.Command{
-		Use:     "phone",
-		Short:   "use phone login",
-		Example: "  ncmctl login phone 188xxxx8888\n  ncmctl login phone 188xxxx8888 -p password",
+		Use:   "phone",
+		Short: "use phone login",
+		Long: "Login with phone number and sms captcha or password.\n" +
+			"  interactive: send sms captcha and wait for input, input r to resend.\n" +
+			"  non-interactive: run with --send to send sms captcha first, then run with --code <captcha> or --code - (read from stdin) to login.\n" +
+			"  piped stdin is read as the received sms captcha like --code -, eg: echo 1234 | ncmctl login phone 188xxxx8888",
+		Example: "  ncmctl login phone 188xxxx8888\n" +
+			"  ncmctl login phone 188xxxx8888 -p password\n" +
+			"  ncmctl login phone 188xxxx8888 --send\n" +
+			"  ncmctl login phone 188xxxx8888 --code 1234\n" +
+			"  ncmctl login phone 9xxxxxx8 --countrycode 852",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return c.execute(cmd.Context(), args)
 		},
@@ -65,99 +111,344 @@ func phone(root *Login, l *log.Logger) *cobra.Command {
 
 func (c *loginPhoneCmd) addFlags() {
 	c.cmd.Flags().DurationVarP(&c.timeout, "timeout", "t", time.Minute*10, "login timeout, eg: 1s、1m")
-	c.cmd.Flags().Int64Var(&c.countrycode, "countrycode", 86, "country code")
+	c.cmd.Flags().Int64Var(&c.countrycode, "countrycode", 86, "country code, eg: 86、852、1")
 	c.cmd.Flags().StringVarP(&c.password, "password", "p", "", "use when logging in with a password.")
+	c.cmd.Flags().StringVar(&c.code, "code", "", "sms captcha which has been sent, use - to read from stdin")
+	c.cmd.Flags().BoolVar(&c.send, "send", false, "only send sms captcha and exit, then login with --code")
+	c.cmd.MarkFlagsMutuallyExclusive("password", "code", "send")
+}
+
+// validPhone 校验国家码以及手机号
+func validPhone(countrycode int64, phone string) error {
+	if countrycode <= 0 || countrycode > 9999 {
+		return fmt.Errorf("invalid country code: %d", countrycode)
+	}
+	if countrycode == 86 {
+		if !cellphoneCNRegexp.MatchString(phone) {
+			return fmt.Errorf("invalid phone number: %s", phone)
+		}
+		return nil
+	}
+	if !cellphoneRegexp.MatchString(phone) || len(strconv.FormatInt(countrycode, 10))+len(phone) > 15 {
+		return fmt.Errorf("invalid phone number: +%d %s", countrycode, phone)
+	}
+	return nil
+}
+
+// respCode 获取接口返回的code以及信息,开启checkCode时code不为200会以错误形式返回
+func respCode(resp types.Coder, err error) (int64, string, error) {
+	if err != nil {
+		var e *types.Error
+		if errors.As(err, &e) {
+			return e.Code, e.Message, nil
+		}
+		return 0, "", err
+	}
+	return resp.GetCode(), resp.GetMessage(), nil
 }
 
 func (c *loginPhoneCmd) execute(ctx context.Context, args []string) error {
 	if len(args) <= 0 {
 		return fmt.Errorf("requrid phone number")
 	}
-	var cellphone = args[0]
-	if len(cellphone) < 5 {
-		return fmt.Errorf("phone number is too short")
+	var cellphone = strings.TrimPrefix(strings.TrimSpace(args[0]), fmt.Sprintf("+%d", c.countrycode))
+	if err := validPhone(c.countrycode, cellphone); err != nil {
+		return err
 	}
-	if _, err := strconv.ParseInt(cellphone, 10, 64); err != nil {
-		return fmt.Errorf("invalid phone number: %s", cellphone)
+	if c.code != "" && c.code != "-" && !captchaRegexp.MatchString(c.code) {
+		return fmt.Errorf("invalid sms captcha: %s", c.code)
 	}
 
-	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	cli, err := api.NewClient(c.root.network(), c.l)
 	if err != nil {
 		return fmt.Errorf("NewClient: %w", err)
 	}
 	defer cli.Close(ctx)
-	request := weapi.New(cli)
 
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
-	// 如果密码为空则走短信验证登录逻辑
-	var captcha string
-	if c.password == "" {
-		sms, err := request.SendSMS(ctx, &weapi.SendSMSReq{
-			Cellphone: cellphone,
-			CtCode:    c.countrycode,
-		})
+	f := &phoneFlow{
+		cmd:       c,
+		request:   weapi.New(cli),
+		phone:     cellphone,
+		input:     bufio.NewReader(c.cmd.InOrStdin()),
+		terminal:  isTerminal(c.cmd.InOrStdin()),
+		captcha:   c.code,
+		attempts:  smsMaxAttempts,
+		countCode: c.countrycode,
+	}
+	if err := f.run(ctx); err != nil {
+		if errors.Is(err, errSendOnly) {
+			return nil
+		}
+		return err
+	}
+
+	// 查询登录信息是否成功
+	user, err := f.request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %s", err)
+	}
+	c.cmd.Printf("login success: %+v\n", user)
+	return nil
+}
+
+// isTerminal 标准输入是否为终端
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// phoneFlow 手机号登录状态机
+type phoneFlow struct {
+	cmd       *loginPhoneCmd
+	request   *weapi.Api
+	phone     string
+	countCode int64
+	input     *bufio.Reader
+	terminal  bool   // 是否可以交互输入
+	captcha   string // 验证码
+	attempts  int    // 剩余输入验证码次数
+}
+
+func (f *phoneFlow) run(ctx context.Context) error {
+	var (
+		state phoneState
+		err   error
+	)
+	switch {
+	case f.cmd.password != "":
+		state = phoneStatePassword
+	case f.captcha == "-", f.captcha == "" && !f.terminal && !f.cmd.send:
+		// 非终端(例如管道)时标准输入为已经收到的验证码,需要先通过--send发送
+		f.captcha = ""
+		state = phoneStateInput
+	case f.captcha != "":
+		state = phoneStateVerify
+	default:
+		state = phoneStateSend
+	}
+
+	for state != phoneStateDone {
+		log.Debug("login phone state: %d", state)
+		switch state {
+		case phoneStatePassword:
+			state, err = f.password(ctx)
+		case phoneStateSend:
+			state, err = f.sendSMS(ctx)
+		case phoneStateInput:
+			state, err = f.readCaptcha()
+		case phoneStateVerify:
+			state, err = f.verify(ctx)
+		case phoneStateLogin:
+			state, err = f.login(ctx)
+		default:
+			return fmt.Errorf("unknown login state: %d", state)
+		}
 		if err != nil {
-			return fmt.Errorf("SendSMS: %s", err)
+			return err
 		}
-		if sms.Code == 200 && sms.Data {
-			c.cmd.Println("send sms success")
-		} else {
-			return fmt.Errorf("send sms failed, code: %d, msg: %s\n", sms.Code, sms.Msg)
+	}
+	return nil
+}
+
+// password 密码登录,触发风控需要二次验证时转为短信验证码登录
+func (f *phoneFlow) password(ctx context.Context) (phoneState, error) {
+	resp, err := f.request.LoginCellphone(ctx, &weapi.LoginCellphoneReq{
+		Phone:       f.phone,
+		Countrycode: f.countCode,
+		Remember:    true,
+		Password:    f.cmd.password,
+	})
+	code, msg, err := respCode(resp, err)
+	if err != nil {
+		return 0, fmt.Errorf("LoginCellphone: %w", err)
+	}
+	switch {
+	case code == 200:
+		return phoneStateDone, nil
+	case types.Matches(code, types.ErrCaptcha):
+		if !f.terminal {
+			return 0, fmt.Errorf("password login requires secondary verification(%d), please login with --send and --code", code)
 		}
+		f.cmd.cmd.Printf("password login requires secondary verification(%d), switch to sms captcha login\n", code)
+		return phoneStateSend, nil
+	default:
+		return 0, loginError(code, msg)
+	}
+}
 
-		// 等待用户在终端输入验证码
-		var fail int
-	retry:
-		if fail > 5 {
-			return fmt.Errorf("too many failed attempts")
+// sendSMS 发送短信验证码,发送间隔以及每日次数超过限制时不再发送
+func (f *phoneFlow) sendSMS(ctx context.Context) (phoneState, error) {
+	db, err := database.New(f.cmd.root.root.Cfg.Database)
+	if err != nil {
+		// 数据库被其他进程占用等情况下忽略本地限制,由服务端判断是否限流
+		log.Warn("login phone database: %s", err)
+	} else {
+		defer db.Close(ctx)
+		if err := f.checkLimit(ctx, db); err != nil {
+			return f.retryInput(err)
 		}
-		c.cmd.Printf("please input sms captcha: ")
-		if _, err := fmt.Scanln(&captcha); err != nil {
-			return fmt.Errorf("input sms captcha: %s", err)
+	}
+
+	resp, err := f.request.SendSMS(ctx, &weapi.SendSMSReq{Cellphone: f.phone, CtCode: f.countCode})
+	code, msg, err := respCode(resp, err)
+	if err != nil {
+		return 0, fmt.Errorf("SendSMS: %w", err)
+	}
+	switch {
+	case code == 200 && resp.Data:
+	case types.Matches(code, types.ErrRateLimited):
+		return f.retryInput(fmt.Errorf("send sms too frequently(%d), please retry later", code))
+	case types.Matches(code, types.ErrCaptcha):
+		return 0, fmt.Errorf("send sms blocked by risk control(%d), please use `ncmctl login qrcode` instead", code)
+	default:
+		return 0, fmt.Errorf("send sms failed, code: %d, msg: %s", code, msg)
+	}
+
+	if db != nil {
+		if err := db.Set(ctx, smsSendKey(f.countCode, f.phone), strconv.FormatInt(time.Now().Unix(), 10), smsResendInterval); err != nil {
+			log.Warn("set sms send time: %s", err)
 		}
-		if captcha == "" || len(captcha) < 4 {
-			c.cmd.Println("invalid captcha, please retry")
-			goto retry
+		if _, err := db.Increment(ctx, smsCountKey(f.countCode, f.phone), 1, smsLimitPeriod); err != nil {
+			log.Warn("increment sms send count: %s", err)
 		}
+	}
+	f.cmd.cmd.Println("send sms success")
+	if f.cmd.send {
+		f.cmd.cmd.Printf("please login with: ncmctl login phone %s --countrycode %d --code <captcha>\n", f.phone, f.countCode)
+		return 0, errSendOnly
+	}
+	return phoneStateInput, nil
+}
 
-		verify, err := request.SMSVerify(ctx, &weapi.SMSVerifyReq{
-			Cellphone: cellphone,
-			Captcha:   captcha,
-			CtCode:    c.countrycode,
-		})
-		if err != nil {
-			return fmt.Errorf("SMSVerify: %s", err)
+// checkLimit 检查短信发送间隔以及24小时内发送次数
+func (f *phoneFlow) checkLimit(ctx context.Context, db database.Database) error {
+	if last, err := db.Get(ctx, smsSendKey(f.countCode, f.phone)); err == nil {
+		if sec, err := strconv.ParseInt(last, 10, 64); err == nil {
+			if wait := smsResendInterval - time.Since(time.Unix(sec, 0)); wait > 0 {
+				return fmt.Errorf("sms has been sent recently, please retry after %s", wait.Round(time.Second))
+			}
 		}
-		if verify.Code == 200 && verify.Data {
-			c.cmd.Println("verify sms success")
-		} else {
-			fail++
-			c.cmd.Printf("verify sms failed, code: %d, msg: %s\n", verify.Code, verify.Msg)
-			goto retry
+	}
+	if count, err := db.Get(ctx, smsCountKey(f.countCode, f.phone)); err == nil {
+		if n, err := strconv.ParseInt(count, 10, 64); err == nil && n >= smsDailyLimit {
+			return fmt.Errorf("sms has been sent %d times in 24 hours, please retry later or use `ncmctl login qrcode`", n)
 		}
 	}
+	return nil
+}
+
+// retryInput 无法发送短信时,交互模式下可以继续输入之前收到的验证码
+func (f *phoneFlow) retryInput(err error) (phoneState, error) {
+	if !f.terminal || f.cmd.send {
+		return 0, err
+	}
+	f.cmd.cmd.Println(err)
+	return phoneStateInput, nil
+}
 
-	login, err := request.LoginCellphone(ctx, &weapi.LoginCellphoneReq{
-		Phone:       cellphone,
-		Countrycode: c.countrycode,
-		Remember:    true,
-		Password:    c.password,
-		Captcha:     captcha,
+// readCaptcha 从标准输入读取验证码,交互模式下输入r重新发送
+func (f *phoneFlow) readCaptcha() (phoneState, error) {
+	if f.attempts <= 0 {
+		return 0, fmt.Errorf("too many failed attempts")
+	}
+	if f.terminal {
+		f.cmd.cmd.Printf("please input sms captcha (input r to resend): ")
+	}
+	line, err := f.input.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		if !f.terminal {
+			return 0, fmt.Errorf("input sms captcha: %w, please send sms captcha with --send first", err)
+		}
+		return 0, fmt.Errorf("input sms captcha: %w", err)
+	}
+	f.captcha = strings.TrimSpace(line)
+	if f.terminal && strings.EqualFold(f.captcha, "r") {
+		return phoneStateSend, nil
+	}
+	if !captchaRegexp.MatchString(f.captcha) {
+		if !f.terminal {
+			return 0, fmt.Errorf("invalid sms captcha: %s", f.captcha)
+		}
+		f.cmd.cmd.Println("invalid captcha, please retry")
+		return phoneStateInput, nil
+	}
+	return phoneStateVerify, nil
+}
+
+// verify 校验验证码,交互模式下验证码错误可以重新输入
+func (f *phoneFlow) verify(ctx context.Context) (phoneState, error) {
+	f.attempts--
+	resp, err := f.request.SMSVerify(ctx, &weapi.SMSVerifyReq{
+		Cellphone: f.phone,
+		Captcha:   f.captcha,
+		CtCode:    f.countCode,
 	})
+	code, msg, err := respCode(resp, err)
 	if err != nil {
-		return fmt.Errorf("LoginCellphone: %s", err)
+		return 0, fmt.Errorf("SMSVerify: %w", err)
+	}
+	if code == 200 && resp.Data {
+		f.cmd.cmd.Println("verify sms success")
+		return phoneStateLogin, nil
 	}
-	if login.Code != 200 {
-		return fmt.Errorf("login failed, code: %d, msg: %s, message: %s", login.Code, login.Msg, login.Message)
+	err = fmt.Errorf("verify sms failed, code: %d, msg: %s", code, msg)
+	if !f.terminal {
+		return 0, err
 	}
+	f.cmd.cmd.Println(err)
+	return phoneStateInput, nil
+}
 
-	// 查询登录信息是否成功
-	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+// login 验证码登录
+func (f *phoneFlow) login(ctx context.Context) (phoneState, error) {
+	resp, err := f.request.LoginCellphone(ctx, &weapi.LoginCellphoneReq{
+		Phone:       f.phone,
+		Countrycode: f.countCode,
+		Remember:    true,
+		Captcha:     f.captcha,
+	})
+	code, msg, err := respCode(resp, err)
 	if err != nil {
-		return fmt.Errorf("GetUserInfo: %s", err)
+		return 0, fmt.Errorf("LoginCellphone: %w", err)
 	}
-	c.cmd.Printf("login success: %+v\n", user)
-	return nil
+	if code == 200 {
+		return phoneStateDone, nil
+	}
+	return 0, loginError(code, msg)
+}
+
+// loginError 登录失败原因
+func loginError(code int64, msg string) error {
+	switch {
+	case types.Matches(code, types.ErrCaptcha):
+		return fmt.Errorf("login blocked by risk control(%d), behavior captcha is not supported, please use `ncmctl login qrcode` instead", code)
+	case code == 501:
+		return fmt.Errorf("login failed, account not exist(%d)", code)
+	case code == 502:
+		return fmt.Errorf("login failed, wrong password(%d)", code)
+	case code == 503:
+		return fmt.Errorf("login failed, wrong sms captcha(%d)", code)
+	case code == 509:
+		return fmt.Errorf("login failed, too many wrong password attempts(%d), please retry later", code)
+	default:
+		return fmt.Errorf("login failed, code: %d, msg: %s", code, msg)
+	}
+}
+
+func smsSendKey(countrycode int64, phone string) string {
+	return fmt.Sprintf("sms:send:%v:%v", countrycode, phone)
+}
+
+func smsCountKey(countrycode int64, phone string) string {
+	return fmt.Sprintf("sms:count:%v:%v", countrycode, phone)
 }
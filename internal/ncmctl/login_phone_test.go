@@ -0,0 +1,228 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/database"
+	"github.com/chaunsin/netease-cloud-music/pkg/fakeserver"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidPhone(t *testing.T) {
+	tests := []struct {
+		countrycode int64
+		phone       string
+		wantErr     bool
+	}{
+		{countrycode: 86, phone: "18800000000"},
+		{countrycode: 86, phone: "13912345678"},
+		{countrycode: 86, phone: "12800000000", wantErr: true},
+		{countrycode: 86, phone: "1880000000", wantErr: true},
+		{countrycode: 86, phone: "188000000001", wantErr: true},
+		{countrycode: 86, phone: "188a0000000", wantErr: true},
+		{countrycode: 852, phone: "91234567"},
+		{countrycode: 1, phone: "2025550123"},
+		{countrycode: 852, phone: "1234", wantErr: true},
+		{countrycode: 852, phone: "9123456789012", wantErr: true},
+		{countrycode: 0, phone: "91234567", wantErr: true},
+		{countrycode: 10000, phone: "91234567", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.FormatInt(tt.countrycode, 10)+"-"+tt.phone, func(t *testing.T) {
+			if err := validPhone(tt.countrycode, tt.phone); tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRespCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		resp     types.Coder
+		err      error
+		wantCode int64
+		wantMsg  string
+		wantErr  bool
+	}{
+		{name: "成功", resp: &types.RespCommon[any]{Code: 200}, wantCode: 200},
+		{name: "业务码", resp: &types.RespCommon[any]{Code: 503, Message: "验证码错误"}, wantCode: 503, wantMsg: "验证码错误"},
+		{name: "业务错误", resp: (*types.RespCommon[any])(nil), err: types.NewError(8821, "需要行为验证码验证", ""), wantCode: 8821, wantMsg: "需要行为验证码验证"},
+		{name: "包装的业务错误", resp: (*types.RespCommon[any])(nil), err: errors.Join(errors.New("request"), types.NewError(405, "操作频繁", "")), wantCode: 405, wantMsg: "操作频繁"},
+		{name: "其他错误", resp: (*types.RespCommon[any])(nil), err: errors.New("network"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, msg, err := respCode(tt.resp, tt.err)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, code)
+			assert.Equal(t, tt.wantMsg, msg)
+		})
+	}
+}
+
+func TestPhoneFlow(t *testing.T) {
+	const phone = "18800000000"
+	var (
+		risk       = map[string]interface{}{"code": 8821, "message": "需要行为验证码验证"}
+		security   = map[string]interface{}{"code": -462, "message": "需要安全验证"}
+		rateLimit  = map[string]interface{}{"code": 405, "message": "操作频繁", "data": false}
+		sentRecent = map[string]string{smsSendKey(86, phone): strconv.FormatInt(time.Now().Unix(), 10)}
+		sentDaily  = map[string]string{smsCountKey(86, phone): strconv.Itoa(smsDailyLimit)}
+	)
+	tests := []struct {
+		name     string
+		password string
+		code     string
+		send     bool
+		terminal bool
+		input    string
+		replies  map[string]interface{} // 覆盖模拟服务的接口返回
+		records  map[string]string      // 预先写入数据库的短信发送记录
+		wantErr  string
+		wantOut  []string
+		// 接口调用次数 发送短信、校验验证码、登录
+		sent, verify, login int
+	}{
+		{name: "交互发送验证码后登录", terminal: true, input: "1234\n", wantOut: []string{"send sms success", "verify sms success"}, sent: 1, verify: 1, login: 1},
+		{name: "交互输入无效验证码后重新输入", terminal: true, input: "abc\n1234\n", wantOut: []string{"invalid captcha, please retry"}, sent: 1, verify: 1, login: 1},
+		{name: "交互重发时本地限流继续输入", terminal: true, input: "r\n1234\n", wantOut: []string{"sms has been sent recently"}, sent: 1, verify: 1, login: 1},
+		{name: "交互验证码错误后重新输入", terminal: true, input: "1111\n1234\n", wantOut: []string{"verify sms failed, code: 503"}, sent: 1, verify: 2, login: 1},
+		{name: "交互验证码错误次数过多", terminal: true, input: strings.Repeat("1111\n", smsMaxAttempts+1), wantErr: "too many failed attempts", sent: 1, verify: smsMaxAttempts},
+		{name: "管道输入验证码", input: "1234\n", wantOut: []string{"verify sms success"}, verify: 1, login: 1},
+		{name: "管道没有输入", wantErr: "please send sms captcha with --send first"},
+		{name: "管道输入无效验证码", input: "abc\n", wantErr: "invalid sms captcha: abc"},
+		{name: "非交互验证码错误", input: "1111\n", wantErr: "verify sms failed, code: 503", verify: 1},
+		{name: "--code -", code: "-", terminal: true, input: "1234\n", verify: 1, login: 1},
+		{name: "--code", code: "1234", verify: 1, login: 1},
+		{name: "--send", send: true, wantOut: []string{"send sms success", "please login with"}, sent: 1},
+		{name: "--send本地限流", send: true, records: sentRecent, wantErr: "sms has been sent recently"},
+		{name: "--send每日次数限制", send: true, records: sentDaily, wantErr: "sms has been sent 5 times in 24 hours"},
+		{name: "--send服务端限流", send: true, replies: map[string]interface{}{"/api/sms/captcha/sent": rateLimit}, wantErr: "send sms too frequently(405)", sent: 1},
+		{name: "交互服务端限流继续输入", terminal: true, input: "1234\n", replies: map[string]interface{}{"/api/sms/captcha/sent": rateLimit}, wantOut: []string{"send sms too frequently(405)"}, sent: 1, verify: 1, login: 1},
+		{name: "发送短信触发风控", send: true, replies: map[string]interface{}{"/api/sms/captcha/sent": risk}, wantErr: "send sms blocked by risk control(8821)", sent: 1},
+		{name: "密码登录", password: "password", login: 1},
+		{name: "密码登录非交互需要二次验证", password: "password", replies: map[string]interface{}{"/api/w/login/cellphone": risk}, wantErr: "please login with --send and --code", login: 1},
+		{name: "密码登录非交互需要安全验证", password: "password", replies: map[string]interface{}{"/api/w/login/cellphone": security}, wantErr: "requires secondary verification(-462)", login: 1},
+		{name: "密码登录交互需要二次验证", password: "password", terminal: true, input: "1234\n", replies: map[string]interface{}{"/api/w/login/cellphone": risk}, wantOut: []string{"switch to sms captcha login"}, wantErr: "login blocked by risk control(8821)", sent: 1, verify: 1, login: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				ctx  = context.TODO()
+				s    = newServer(t)
+				home = t.TempDir()
+				root = newRoot(t, s, home, "")
+				out  bytes.Buffer
+				cmd  = &loginPhoneCmd{root: &Login{root: root}, cmd: &cobra.Command{}, password: tt.password, code: tt.code, send: tt.send}
+			)
+			// 只有验证码1234可以通过校验
+			s.Handle("/api/sms/captcha/verify", func(req *fakeserver.Request) (interface{}, error) {
+				if req.Param("captcha") != "1234" {
+					return map[string]interface{}{"code": 503, "message": "验证码错误", "data": false}, nil
+				}
+				return map[string]interface{}{"code": 200, "data": true}, nil
+			})
+			for endpoint, reply := range tt.replies {
+				s.Reply(endpoint, reply)
+			}
+			if len(tt.records) > 0 {
+				db, err := database.New(root.Cfg.Database)
+				assert.NoError(t, err)
+				for k, v := range tt.records {
+					assert.NoError(t, db.Set(ctx, k, v, time.Hour))
+				}
+				assert.NoError(t, db.Close(ctx))
+			}
+			cmd.cmd.SetOut(&out)
+			cli, err := api.NewClient(root.Cfg.Network, log.Default)
+			assert.NoError(t, err)
+			defer cli.Close(ctx)
+
+			f := &phoneFlow{
+				cmd:       cmd,
+				request:   weapi.New(cli),
+				phone:     phone,
+				countCode: 86,
+				input:     bufio.NewReader(strings.NewReader(tt.input)),
+				terminal:  tt.terminal,
+				captcha:   tt.code,
+				attempts:  smsMaxAttempts,
+			}
+			err = f.run(ctx)
+			switch {
+			case tt.send && tt.wantErr == "":
+				assert.ErrorIs(t, err, errSendOnly)
+			case tt.wantErr != "":
+				assert.ErrorContains(t, err, tt.wantErr)
+			default:
+				assert.NoError(t, err)
+				assert.False(t, weapi.New(cli).NeedLogin(ctx), "login success")
+			}
+			for _, v := range tt.wantOut {
+				assert.Contains(t, out.String(), v)
+			}
+
+			var (
+				sent   = s.Requests("/api/sms/captcha/sent")
+				verify = s.Requests("/api/sms/captcha/verify")
+				login  = s.Requests("/api/w/login/cellphone")
+			)
+			assert.Len(t, sent, tt.sent, "send sms")
+			assert.Len(t, verify, tt.verify, "verify sms")
+			assert.Len(t, login, tt.login, "login")
+			for _, r := range sent {
+				assert.Equal(t, phone, r.Param("cellphone"))
+			}
+			// 最后一次校验以及验证码登录使用输入的验证码
+			if len(verify) > 0 && tt.wantErr == "" {
+				assert.Equal(t, "1234", verify[len(verify)-1].Param("captcha"))
+			}
+			if len(login) > 0 && tt.password == "" {
+				assert.Equal(t, "1234", login[len(login)-1].Param("captcha"))
+			}
+		})
+	}
+}
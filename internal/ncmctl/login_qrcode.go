@@ -76,7 +76,7 @@ func (c *loginQrcodeCmd) execute(ctx context.Context, _ []string) error {
 		return fmt.Errorf("qrcode level must be 0-3")
 	}
 
-	cli, err := api.NewClient(c.root.root.Cfg.Network, c.l)
+	cli, err := api.NewClient(c.root.network(), c.l)
 	if err != nil {
 		return fmt.Errorf("NewClient: %w", err)
 	}
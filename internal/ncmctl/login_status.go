@@ -0,0 +1,122 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/spf13/cobra"
+)
+
+type loginStatusCmd struct {
+	root *Login
+	cmd  *cobra.Command
+	l    *log.Logger
+}
+
+func status(root *Login, l *log.Logger) *cobra.Command {
+	c := &loginStatusCmd{
+		root: root,
+		l:    l,
+	}
+	c.cmd = &cobra.Command{
+		Use:     "status",
+		Short:   "show login status, account, vip and cookie expiry",
+		Example: "  ncmctl login status\n  ncmctl --profile alice login status",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.execute(cmd.Context(), args)
+		},
+	}
+	return c.cmd
+}
+
+func (c *loginStatusCmd) execute(ctx context.Context, _ []string) error {
+	cli, err := api.NewClient(c.root.network(), c.l)
+	if err != nil {
+		return fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+
+	var (
+		session = cli.Session()
+		request = weapi.New(cli)
+		format  = func(t time.Time, ok bool) string {
+			if !ok {
+				return "-"
+			}
+			return t.Local().Format(time.DateTime)
+		}
+	)
+	c.cmd.Printf("profile:      %s\n", c.root.root.Profile)
+	if _, ok := session.Token(); !ok {
+		c.cmd.Println("status:       not logged in")
+		return nil
+	}
+
+	user, err := request.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	if err != nil {
+		return fmt.Errorf("GetUserInfo: %w", err)
+	}
+	if user.Code != 200 || user.Account == nil || user.Profile == nil {
+		session.Expire("music.163.com/weapi/w/nuser/account/get", user.Code)
+		c.cmd.Println("status:       expired, please login again")
+		return nil
+	}
+	c.cmd.Println("status:       logged in")
+	c.cmd.Printf("userId:       %d\n", user.Profile.UserId)
+	c.cmd.Printf("nickname:     %s\n", user.Profile.Nickname)
+	c.cmd.Printf("vip:          %s\n", c.vip(ctx, request, user.Account.VipType))
+
+	expires, ok := session.Expires()
+	c.cmd.Printf("expires:      %s\n", format(expires, ok))
+	last, ok := session.LastRefresh()
+	c.cmd.Printf("last refresh: %s\n", format(last, ok))
+	return nil
+}
+
+// vip 会员状态,获取会员信息失败时只显示vipType
+func (c *loginStatusCmd) vip(ctx context.Context, request *weapi.Api, vipType int64) string {
+	if vipType == 0 {
+		return "none"
+	}
+	info, err := request.VipInfo(ctx, &weapi.VipInfoReq{})
+	if err != nil || info.Code != 200 {
+		log.Debug("VipInfo resp: %+v err: %v", info, err)
+		return fmt.Sprintf("vipType=%d", vipType)
+	}
+	var expire = info.Data.Associator.ExpireTime
+	if info.Data.MusicPackage.ExpireTime > expire {
+		expire = info.Data.MusicPackage.ExpireTime
+	}
+	if expire <= 0 {
+		return fmt.Sprintf("vipType=%d level=%d", vipType, info.Data.RedVipLevel)
+	}
+	return fmt.Sprintf("vipType=%d level=%d expire=%s", vipType, info.Data.RedVipLevel, time.UnixMilli(expire).Local().Format(time.DateTime))
+}
@@ -88,9 +88,11 @@ func (c *Logout) execute(ctx context.Context, _ []string) error {
 		return fmt.Errorf("layout: %+v", resp)
 	}
 
-	// 只清理默认目录下得文件
-	if err := os.Remove(c.root.Opts.Home + "/.ncmctl/cookie.json"); err != nil {
-		log.Debug("remove cookie.json: %s", err)
+	// 只清理当前账号的cookie文件
+	if file := c.root.Cfg.Network.Cookie.Filepath; file != "" {
+		if err := os.Remove(file); err != nil {
+			log.Debug("remove %s: %s", file, err)
+		}
 	}
 	c.cmd.Println("Logout success")
 	return nil
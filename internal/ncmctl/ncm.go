@@ -48,13 +48,15 @@ type NCMOpts struct {
 	Output   string // 生成文件路径
 	Parallel int64
 	Tag      bool
+	Filename string // 文件名模板
 }
 
 type NCM struct {
-	root *Root
-	cmd  *cobra.Command
-	opts NCMOpts
-	l    *log.Logger
+	root  *Root
+	cmd   *cobra.Command
+	opts  NCMOpts
+	l     *log.Logger
+	names *FilenameTemplate
 }
 
 func NewNCM(root *Root, l *log.Logger) *NCM {
@@ -78,12 +80,18 @@ func (c *NCM) addFlags() {
 	c.cmd.PersistentFlags().StringVarP(&c.opts.Output, "output", "o", "./ncm", "output music dir")
 	c.cmd.PersistentFlags().Int64VarP(&c.opts.Parallel, "parallel", "p", 10, "concurrent decrypt count")
 	c.cmd.PersistentFlags().BoolVar(&c.opts.Tag, "tag", false, "disable set a music tag info")
+	c.cmd.PersistentFlags().StringVar(&c.opts.Filename, "filename", defaultNCMFilename, filenameUsage)
 }
 
 func (c *NCM) validate() error {
 	if c.opts.Parallel > 50 || c.opts.Parallel < 1 {
 		return fmt.Errorf("parallel must be between 1 and 50")
 	}
+	names, err := NewFilenameTemplate(c.opts.Filename)
+	if err != nil {
+		return fmt.Errorf("filename template: %w", err)
+	}
+	c.names = names
 	return nil
 }
 
@@ -205,28 +213,52 @@ func (c *NCM) decode(filename string) error {
 	defer _ncm.Close()
 
 	var (
-		meta   = _ncm.Metadata()
-		format string
+		meta      = _ncm.Metadata()
+		music     *ncm.MetadataMusic
+		_filename = filepath.Base(filename)
+		ext       = filepath.Ext(_filename)
+		data      = FilenameData{Filename: strings.TrimSuffix(_filename, ext)}
 	)
-	switch meta.GetType() {
-	case ncm.MetadataTypeMusic:
-		format = meta.GetMusic().Format
-	case ncm.MetadataTypeDJ:
-		format = meta.GetDJ().MainMusic.Format
+	if meta != nil {
+		switch meta.GetType() {
+		case ncm.MetadataTypeMusic:
+			music = meta.GetMusic()
+		case ncm.MetadataTypeDJ:
+			music = &meta.GetDJ().MainMusic
+		}
+	}
+	if music != nil {
+		data.Id = music.Id
+		data.Name = music.Name
+		data.Album = music.Album
+		data.Br = music.BitRate
+		for _, ar := range music.Artists {
+			data.Artists = append(data.Artists, ar.Name)
+		}
+		data.Artist = strings.Join(data.Artists, ",")
+		if len(data.Artists) > 0 {
+			data.AlbumArtist = data.Artists[0]
+		}
+	}
+	name, err := c.names.Execute(&data)
+	if err != nil {
+		return fmt.Errorf("filename: %w", err)
 	}
 
+	var format string
+	if music != nil {
+		format = music.Format
+	}
 	var (
-		_filename = filepath.Base(filename)
-		ext       = filepath.Ext(_filename)
-		name      = utils.Filename(strings.TrimSuffix(_filename, ext), "_")
-		extend    = utils.Ternary(format == "", strings.TrimPrefix(ext, "."), format)
-		dest      = filepath.Join(c.opts.Output, name+"."+extend)
+		extend = utils.Ternary(format == "", strings.TrimPrefix(ext, "."), format)
+		base   = filepath.Join(c.opts.Output, name)
+		dest   = base + "." + extend
 	)
 
 	if err := utils.MkdirIfNotExist(c.opts.Output, 0755); err != nil {
 		return fmt.Errorf("MkdirIfNotExist: %w", err)
 	}
-	tmp, err := os.CreateTemp(c.opts.Output, fmt.Sprintf("ncm-*-%s.%s.tmp", name, extend))
+	tmp, err := os.CreateTemp(c.opts.Output, fmt.Sprintf("ncm-*-%s.%s.tmp", filepath.Base(name), extend))
 	if err != nil {
 		return fmt.Errorf("CreateTemp: %w", err)
 	}
@@ -246,8 +278,13 @@ func (c *NCM) decode(filename string) error {
 		}
 	}
 
-	for i := 1; utils.FileExists(dest); i++ {
-		dest = filepath.Join(c.opts.Output, fmt.Sprintf("%s(%d).%s", name, i, extend))
+	// 避免文件重名,包括本次转换的其他文件渲染出了相同的路径
+	for i := 1; !c.names.Reserve(dest, filename) || utils.FileExists(dest); i++ {
+		dest = fmt.Sprintf("%s(%d).%s", base, i, extend)
+	}
+	if err := utils.MkdirIfNotExist(filepath.Dir(dest), 0755); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("MkdirIfNotExist: %w", err)
 	}
 	// 显示关闭文件避免Windows系统无法重命名错误:The process cannot access the file because it is being used by another process
 	if err := tmp.Close(); err != nil {
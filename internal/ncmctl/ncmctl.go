@@ -24,6 +24,7 @@
 package ncmctl
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -36,12 +37,34 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// 命令退出码
+const (
+	ExitCodeOK             = 0
+	ExitCodeFailure        = 1 // 执行失败
+	ExitCodePartialFailure = 2 // 部分失败,比如批量下载时部分歌曲下载失败
+)
+
+// ExitError 指定退出码的错误
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
 const title = "                       _    _\n ___  ___  _____  ___ | |_ | |\n|   ||  _||     ||  _||  _|| |\n|_|_||___||_|_|_||___||_|  |_|\n"
 
 type RootOpts struct {
-	Debug  bool   // 是否开启命令行debug模式
-	Config string // 配置文件路径
-	Home   string
+	Debug   bool   // 是否开启命令行debug模式
+	Config  string // 配置文件路径
+	Home    string
+	Profile string // 使用的账号,为空时使用当前切换的账号
 }
 
 type Root struct {
@@ -49,6 +72,12 @@ type Root struct {
 	Opts RootOpts
 	cmd  *cobra.Command
 	l    *log.Logger
+	// Profile 当前使用的账号名称
+	Profile  string
+	profiles *Profiles
+	home     string
+	// base 未应用账号前的配置
+	base *config.Config
 }
 
 func New() *Root {
@@ -57,7 +86,7 @@ func New() *Root {
 			Use:     "ncmctl",
 			Short:   "ncmctl command",
 			Long:    "ncmctl is a toolbox for netease cloud music\n\nMIT License Copyright (c) 2024 chaunsin\nhttps://github.com/chaunsin/netease-cloud-music\n" + title,
-			Example: "  ncmctl cloud\n  ncmctl crypto\n  ncmctl login\n  ncmctl curl\n  ncmctl partner\n  ncmctl scrobble\n  ncmctl sign\n  ncmctl task",
+			Example: "  ncmctl account\n  ncmctl cloud\n  ncmctl crypto\n  ncmctl login\n  ncmctl curl\n  ncmctl partner\n  ncmctl scrobble\n  ncmctl sign\n  ncmctl task",
 		},
 	}
 	c.cmd.SetVersionTemplate(`{{printf "%s\n" .Version}}`)
@@ -97,10 +126,34 @@ func New() *Root {
 		// init logger
 		c.l = log.New(c.Cfg.Log)
 		log.Default = c.l
-		log.Debug("[config] init home=%s path=%s log=%+v network=%+v", home, cfgPath, c.Cfg.Log, c.Cfg.Network)
+
+		// 账号
+		profiles, err := LoadProfiles(filepath.Join(home, ".ncmctl", "profiles.json"))
+		if err != nil {
+			return fmt.Errorf("LoadProfiles: %w", err)
+		}
+		c.home, c.profiles, c.base = home, profiles, c.Cfg
+		c.Profile = utils.Ternary(c.Opts.Profile != "", c.Opts.Profile, profiles.Current)
+		if err := validateProfile(c.Profile); err != nil {
+			return err
+		}
+		// 登录时自动添加账号,其他命令要求账号已存在
+		if !profiles.Exists(c.Profile) && !isLoginCommand(cmd) {
+			return fmt.Errorf("profile %s not found, login with: ncmctl --profile %s login", c.Profile, c.Profile)
+		}
+		c.Cfg = profileConfig(c.base, home, c.Profile)
+		log.Debug("[config] init home=%s path=%s profile=%s log=%+v network=%+v", home, cfgPath, c.Profile, c.Cfg.Log, c.Cfg.Network)
 		return nil
 	}
 	c.cmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
+		if isLoginCommand(cmd) && !c.profiles.Exists(c.Profile) {
+			if err := c.profiles.Add(c.Profile); err != nil {
+				return err
+			}
+			if err := c.profiles.Save(); err != nil {
+				return fmt.Errorf("save profiles: %w", err)
+			}
+		}
 		return c.l.Close()
 	}
 
@@ -110,6 +163,7 @@ func New() *Root {
 	c.Add(NewCrypto(c, c.l).Command())
 	c.Add(NewLogin(c, c.l).Command())
 	c.Add(NewLogout(c, c.l).Command())
+	c.Add(NewAccount(c, c.l).Command())
 	c.Add(NewPartner(c, c.l).Command())
 	c.Add(NewCurl(c, c.l).Command())
 	c.Add(NewCloud(c, c.l).Command())
@@ -125,6 +179,28 @@ func (c *Root) addFlags() {
 	c.cmd.PersistentFlags().BoolVar(&c.Opts.Debug, "debug", false, "run in debug mode")
 	c.cmd.PersistentFlags().StringVarP(&c.Opts.Config, "config", "c", "", "configuration file path")
 	c.cmd.PersistentFlags().StringVar(&c.Opts.Home, "home", config.HomeDir, "configuration home path. the home path is used to store running information")
+	c.cmd.PersistentFlags().StringVar(&c.Opts.Profile, "profile", "", "account profile name, default is the profile selected by 'ncmctl account switch'")
+}
+
+// WithProfile 返回使用指定账号配置的Root,用于同一进程中为多个账号执行命令
+func (c *Root) WithProfile(name string) *Root {
+	var root = *c
+	root.Profile = name
+	root.Cfg = profileConfig(c.base, c.home, name)
+	return &root
+}
+
+// isLoginCommand 是否为login及其子命令,login status 只查看状态不包含在内
+func isLoginCommand(cmd *cobra.Command) bool {
+	if cmd.Name() == "status" {
+		return false
+	}
+	for ; cmd != nil; cmd = cmd.Parent() {
+		if cmd.Name() == "login" && cmd.HasParent() && !cmd.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *Root) Version(version, buildTime, commitHash string) {
@@ -139,6 +215,10 @@ func (c *Root) Add(command ...*cobra.Command) {
 func (c *Root) Execute() {
 	if err := c.cmd.Execute(); err != nil {
 		c.cmd.PrintErrln(err)
-		os.Exit(1)
+		var e *ExitError
+		if errors.As(err, &e) {
+			os.Exit(e.Code)
+		}
+		os.Exit(ExitCodeFailure)
 	}
 }
@@ -0,0 +1,284 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/config"
+	"github.com/chaunsin/netease-cloud-music/pkg/fakeserver"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+	"github.com/chaunsin/netease-cloud-music/pkg/utils"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	log.Default = log.New(&log.Config{
+		Level:  "debug",
+		Stdout: true,
+	})
+	os.Exit(m.Run())
+}
+
+// execute 执行ncmctl命令,所有请求转发到模拟服务,home目录用于隔离cookie以及数据库
+func execute(t *testing.T, s *fakeserver.Server, home string, args ...string) (string, error) {
+	t.Helper()
+	// 命令执行时会替换并关闭全局日志
+	defer func(l *log.Logger) { log.Default = l }(log.Default)
+	var (
+		root   = New()
+		out    bytes.Buffer
+		preRun = root.cmd.PersistentPreRunE
+	)
+	root.cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := preRun(cmd, args); err != nil {
+			return err
+		}
+		root.base.Network.BaseURL = s.URL
+		root.Cfg.Network.BaseURL = s.URL
+		return nil
+	}
+	root.cmd.SetOut(&out)
+	root.cmd.SetErr(&out)
+	root.cmd.SetArgs(append([]string{"--home", home}, args...))
+	err := root.cmd.Execute()
+	return out.String(), err
+}
+
+// newRoot 创建请求转发到模拟服务的Root,与执行命令时的初始化过程一致,profile为空时使用当前切换的账号
+func newRoot(t *testing.T, s *fakeserver.Server, home, profile string) *Root {
+	var cfg = config.GetDefault()
+	cfg.ReplaceMagicVariables("HOME", home)
+	cfg.Network.BaseURL = s.URL
+	cfg.Network.RetryPolicy.Attempts = 0
+	profiles, err := LoadProfiles(filepath.Join(home, ".ncmctl", "profiles.json"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var root = &Root{
+		Opts:     RootOpts{Profile: profile},
+		Profile:  utils.Ternary(profile != "", profile, profiles.Current),
+		profiles: profiles,
+		home:     home,
+		base:     cfg,
+	}
+	root.Cfg = profileConfig(cfg, home, root.Profile)
+	return root
+}
+
+// login 使用模拟服务的账号登录
+func login(t *testing.T, s *fakeserver.Server, home string) {
+	t.Helper()
+	_, err := execute(t, s, home, "login", "cookie", "--format", "header", "MUSIC_U="+s.Account.Token)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+}
+
+func newServer(t *testing.T) *fakeserver.Server {
+	s := fakeserver.New()
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestSign(t *testing.T) {
+	var (
+		s    = newServer(t)
+		home = t.TempDir()
+	)
+	_, err := execute(t, s, home, "sign")
+	assert.ErrorContains(t, err, "need login")
+
+	login(t, s, home)
+	out, err := execute(t, s, home, "sign")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "云贝签到成功")
+	assert.Len(t, s.Requests("/api/batch"), 1)
+	assert.Len(t, s.Requests("/api/pointmall/user/sign"), 1)
+	assert.Len(t, s.Requests("/api/vipnewcenter/app/level/growhpoint/basic"), 1)
+
+	out, err = execute(t, s, home, "sign")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "云贝已签到")
+
+	// 领取签到奖励后再查询云贝任务
+	s.Reply("/api/pointmall/user/sign/config", map[string]interface{}{"code": 200, "data": map[string]interface{}{
+		"lotteryConfig": []map[string]interface{}{{"signDay": 7, "baseLotteryId": 1}},
+	}})
+	s.Reply("/api/pointmall/user/sign/lottery/get", map[string]interface{}{"code": 200, "data": true})
+	out, err = execute(t, s, home, "sign", "-a")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "领取成功")
+	var endpoints []string
+	for _, r := range s.Requests("") {
+		endpoints = append(endpoints, r.Endpoint)
+	}
+	lottery := slices.Index(endpoints, "/api/pointmall/user/sign/lottery/get")
+	assert.NotEqual(t, -1, lottery)
+	assert.Greater(t, slices.Index(endpoints, "/api/usertool/task/todo/query"), lottery)
+}
+
+func TestScrobble(t *testing.T) {
+	var (
+		s    = newServer(t)
+		home = t.TempDir()
+	)
+	s.AddSong(fakeserver.Song{Id: 1001, Name: "song1", Data: []byte("fakeserver song 1001")})
+	s.AddSong(fakeserver.Song{Id: 1002, Name: "song2", Data: []byte("fakeserver song 1002")})
+	login(t, s, home)
+
+	out, err := execute(t, s, home, "scrobble", "-n", "2")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "账号已满级")
+	assert.Empty(t, s.Requests("/api/feedback/weblog"))
+
+	s.Reply("/api/w/v1/user/detail/", map[string]interface{}{"code": 200, "level": 5})
+	_, err = execute(t, s, home, "scrobble", "-n", "2")
+	assert.NoError(t, err)
+	var ids []string
+	for _, r := range s.Requests("/api/feedback/weblog") {
+		ids = append(ids, r.Param("logs"))
+	}
+	if assert.Len(t, ids, 2) {
+		assert.Contains(t, ids[0], `"id":"1001"`)
+		assert.Contains(t, ids[1], `"id":"1002"`)
+	}
+
+	// 听过的歌曲不会重复刷
+	_, err = execute(t, s, home, "scrobble", "-n", "2")
+	assert.NoError(t, err)
+	assert.Len(t, s.Requests("/api/feedback/weblog"), 2)
+}
+
+func TestPartner(t *testing.T) {
+	var (
+		s    = newServer(t)
+		home = t.TempDir()
+	)
+	login(t, s, home)
+	s.Reply("/api/music/partner/daily/task/get", map[string]interface{}{"code": 200, "data": map[string]interface{}{
+		"id": 1, "count": 1, "completedCount": 1,
+		"works": []map[string]interface{}{{"work": map[string]interface{}{"id": 1, "resourceId": 1001}, "completed": true}},
+	}})
+	out, err := execute(t, s, home, "partner", "-n", "0")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "report: 基础歌曲完成数量(1) 扩展歌曲完成数量(0/0)")
+	assert.Empty(t, s.Requests("/api/music/partner/work/evaluate"))
+
+	s.Reply("/api/music/partner/user/info/get", map[string]interface{}{"code": 200, "data": map[string]interface{}{"status": "ELIMINATED"}})
+	out, err = execute(t, s, home, "partner", "-n", "0")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "失去测评资格")
+}
+
+func TestDownload(t *testing.T) {
+	var (
+		s      = newServer(t)
+		home   = t.TempDir()
+		output = t.TempDir()
+	)
+	s.AddSong(fakeserver.Song{Id: 1001, Name: "song1", Artist: "artist", Data: []byte("fakeserver song 1001")})
+	s.AddSong(fakeserver.Song{Id: 1002, Name: "song2", Artist: "artist", Data: []byte("fakeserver song 1002")})
+	login(t, s, home)
+
+	out, err := execute(t, s, home, "download", "--tag=false", "-o", output, "1002")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "report total: 1 success: 1 failed: 0 skip: 0")
+
+	data, err := os.ReadFile(filepath.Join(output, "artist - song2.mp3"))
+	assert.NoError(t, err)
+	assert.Equal(t, "fakeserver song 1002", string(data))
+	assert.NoFileExists(t, filepath.Join(output, "artist - song1.mp3"))
+
+	// 已下载的歌曲跳过
+	out, err = execute(t, s, home, "download", "--tag=false", "-o", output, "1002")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "report total: 1 success: 0 failed: 0 skip: 1")
+
+	// 下载用户所有歌单
+	s.Reply("/api/user/playlist/", map[string]interface{}{"code": 200, "more": false, "playlist": []map[string]interface{}{{"id": 1}}})
+	out, err = execute(t, s, home, "download", "--tag=false", "-o", output, "https://music.163.com/#/user/home?id=1")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "report total: 2 success: 1 failed: 0 skip: 1")
+	if reqs := s.Requests("/api/user/playlist/"); assert.Len(t, reqs, 1) {
+		assert.Equal(t, "1", reqs[0].Param("uid"))
+	}
+}
+
+// id3 返回只包含标题的ID3v2.3标签
+func id3(title string) []byte {
+	var frame = append([]byte{0}, title...)
+	var data = []byte("TIT2")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(frame)))
+	data = append(append(data, 0, 0), frame...)
+	var size = len(data)
+	return append([]byte{'I', 'D', '3', 3, 0, 0, byte(size >> 21 & 0x7f), byte(size >> 14 & 0x7f), byte(size >> 7 & 0x7f), byte(size & 0x7f)}, data...)
+}
+
+func TestCloud(t *testing.T) {
+	var (
+		s       = newServer(t)
+		home    = t.TempDir()
+		file    = filepath.Join(t.TempDir(), "song.mp3")
+		content = append(id3("song"), bytes.Repeat([]byte("fakeserver"), 1024)...)
+	)
+	assert.NoError(t, os.WriteFile(file, content, 0644))
+	login(t, s, home)
+
+	out, err := execute(t, s, home, "cloud", file)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "success: 1")
+	data, ok := s.Upload("fakeserver/obj/fakeserver/2001.mp3")
+	assert.True(t, ok)
+	assert.True(t, bytes.Equal(content, data), "upload content mismatch")
+	assert.Len(t, s.Requests("/api/cloud/pub/v2"), 1)
+}
+
+func TestCloudList(t *testing.T) {
+	var (
+		s    = newServer(t)
+		home = t.TempDir()
+	)
+	login(t, s, home)
+	s.Handle("/api/v1/cloud/get", func(req *fakeserver.Request) (interface{}, error) {
+		var more = req.Param("offset") == ""
+		return map[string]interface{}{"code": 200, "hasMore": more, "data": []map[string]interface{}{
+			{"songId": utils.Ternary(more, 2001, 2002), "fileSize": 1024 * 1024, "fileName": "song.mp3"},
+		}}, nil
+	})
+
+	out, err := execute(t, s, home, "cloud", "list")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "2001\t1.00M\tsong.mp3")
+	assert.Contains(t, out, "2002\t1.00M\tsong.mp3")
+	assert.Contains(t, out, "total: 2 size: 2.00M")
+	assert.Len(t, s.Requests("/api/v1/cloud/get"), 2)
+}
@@ -164,7 +164,7 @@ func (c *Partner) do(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("PartnerUserinfo: %w", err)
 	}
-	if info.Code == 703 {
+	if err := types.CheckCode(info, "PartnerUserinfo"); errors.Is(err, types.ErrNotPartner) {
 		return fmt.Errorf("您不是音乐合伙人不能进行测评 detail: %+v\n", info)
 	}
 	if info.Code != 200 {
@@ -375,9 +375,8 @@ func (c *Partner) do(ctx context.Context) error {
 end:
 
 	// 刷新token过期时间
-	refresh, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{})
-	if err != nil || refresh.Code != 200 {
-		log.Warn("TokenRefresh resp:%+v err: %s", refresh, err)
+	if _, err := cli.Session().RefreshIfNeeded(ctx); err != nil {
+		log.Warn("RefreshIfNeeded: %s", err)
 	}
 	return nil
 }
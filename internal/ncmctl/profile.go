@@ -0,0 +1,164 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/config"
+)
+
+// DefaultProfile 默认账号,使用配置文件中的cookie以及数据库路径
+const DefaultProfile = "default"
+
+var profileNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,32}$`)
+
+// Profile 账号配置,每个账号使用单独的cookie(包含设备id)以及数据库目录
+type Profile struct {
+	Name       string    `json:"name"`
+	CreateTime time.Time `json:"createTime"`
+}
+
+// Profiles 账号列表,保存在 {home}/.ncmctl/profiles.json
+type Profiles struct {
+	path     string
+	Current  string    `json:"current"`
+	Profiles []Profile `json:"profiles"`
+}
+
+// LoadProfiles 加载账号列表,文件不存在时返回空列表
+func LoadProfiles(path string) (*Profiles, error) {
+	var p = Profiles{path: path, Current: DefaultProfile}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &p, nil
+		}
+		return nil, fmt.Errorf("ReadFile: %w", err)
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if p.Current == "" {
+		p.Current = DefaultProfile
+	}
+	return &p, nil
+}
+
+// Save 保存账号列表
+func (p *Profiles) Save() error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), os.ModePerm); err != nil {
+		return fmt.Errorf("MkdirAll: %w", err)
+	}
+	if err := os.WriteFile(p.path, data, 0600); err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	return nil
+}
+
+// Exists 账号是否存在,默认账号总是存在
+func (p *Profiles) Exists(name string) bool {
+	return name == DefaultProfile || slices.ContainsFunc(p.Profiles, func(v Profile) bool { return v.Name == name })
+}
+
+// Names 返回包含默认账号在内的所有账号名称
+func (p *Profiles) Names() []string {
+	var names = []string{DefaultProfile}
+	for _, v := range p.Profiles {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+// Add 添加账号,已存在时忽略
+func (p *Profiles) Add(name string) error {
+	if err := validateProfile(name); err != nil {
+		return err
+	}
+	if p.Exists(name) {
+		return nil
+	}
+	p.Profiles = append(p.Profiles, Profile{Name: name, CreateTime: time.Now()})
+	return nil
+}
+
+// Remove 移除账号,当前账号被移除时切换为默认账号
+func (p *Profiles) Remove(name string) error {
+	if name == DefaultProfile {
+		return errors.New("default profile can not be removed")
+	}
+	if !p.Exists(name) {
+		return fmt.Errorf("profile %s not found", name)
+	}
+	p.Profiles = slices.DeleteFunc(p.Profiles, func(v Profile) bool { return v.Name == name })
+	if p.Current == name {
+		p.Current = DefaultProfile
+	}
+	return nil
+}
+
+func validateProfile(name string) error {
+	if !profileNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, only letters, digits, '_' and '-' are allowed", name)
+	}
+	return nil
+}
+
+// profileDir 账号数据目录
+func profileDir(home, name string) string {
+	return filepath.Join(home, ".ncmctl", "profiles", name)
+}
+
+// profileConfig 返回指定账号使用的配置,默认账号直接使用原配置.
+// 其他账号的cookie、数据库保存在账号目录中,CookieCloud的uuid使用 {uuid}-{账号名称} 避免多个账号覆盖同一份cookie
+func profileConfig(cfg *config.Config, home, name string) *config.Config {
+	if name == "" || name == DefaultProfile {
+		return cfg
+	}
+	var (
+		dir      = profileDir(home, name)
+		c        = *cfg
+		network  = *cfg.Network
+		database = *cfg.Database
+	)
+	network.Cookie.Filepath = filepath.Join(dir, "cookie.json")
+	network.Cookie.Database.Path = filepath.Join(dir, "database", "cookie")
+	if network.Cookie.CookieCloud.Uuid != "" {
+		network.Cookie.CookieCloud.Uuid += "-" + name
+	}
+	database.Path = filepath.Join(dir, "database", "badger")
+	c.Network, c.Database = &network, &database
+	return &c
+}
@@ -0,0 +1,194 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		path = filepath.Join(dir, ".ncmctl", "profiles.json")
+	)
+	// 文件不存在
+	p, err := LoadProfiles(path)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultProfile, p.Current)
+	assert.Empty(t, p.Profiles)
+	assert.Equal(t, []string{DefaultProfile}, p.Names())
+
+	// 保存后重新加载
+	assert.NoError(t, p.Add("alice"))
+	p.Current = "alice"
+	assert.NoError(t, p.Save())
+	p, err = LoadProfiles(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", p.Current)
+	assert.Equal(t, []string{DefaultProfile, "alice"}, p.Names())
+
+	// current为空时使用默认账号
+	assert.NoError(t, os.WriteFile(path, []byte(`{"profiles":[{"name":"bob"}]}`), 0600))
+	p, err = LoadProfiles(path)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultProfile, p.Current)
+	assert.True(t, p.Exists("bob"))
+
+	// 格式错误
+	assert.NoError(t, os.WriteFile(path, []byte(`{`), 0600))
+	_, err = LoadProfiles(path)
+	assert.Error(t, err)
+}
+
+func TestProfilesAddRemove(t *testing.T) {
+	p, err := LoadProfiles(filepath.Join(t.TempDir(), "profiles.json"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.Add("alice"))
+	assert.NoError(t, p.Add("alice"), "已存在时忽略")
+	assert.NoError(t, p.Add(DefaultProfile), "默认账号总是存在")
+	assert.Error(t, p.Add("../alice"))
+	assert.Equal(t, []string{DefaultProfile, "alice"}, p.Names())
+
+	assert.Error(t, p.Remove(DefaultProfile))
+	assert.Error(t, p.Remove("bob"))
+
+	// 移除当前账号时切换为默认账号
+	assert.NoError(t, p.Add("bob"))
+	p.Current = "alice"
+	assert.NoError(t, p.Remove("bob"))
+	assert.Equal(t, "alice", p.Current)
+	assert.NoError(t, p.Remove("alice"))
+	assert.Equal(t, DefaultProfile, p.Current)
+	assert.False(t, p.Exists("alice"))
+	assert.Equal(t, []string{DefaultProfile}, p.Names())
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "default"},
+		{name: "alice"},
+		{name: "Alice_01-b"},
+		{name: strings.Repeat("a", 32)},
+		{name: "", wantErr: true},
+		{name: strings.Repeat("a", 33), wantErr: true},
+		{name: "../alice", wantErr: true},
+		{name: "a/b", wantErr: true},
+		{name: "a b", wantErr: true},
+		{name: "张三", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateProfile(tt.name); tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProfileConfig(t *testing.T) {
+	var (
+		home = t.TempDir()
+		base = config.GetDefault()
+	)
+	base.ReplaceMagicVariables("HOME", home)
+	var (
+		cookiePath = base.Network.Cookie.Filepath
+		dbPath     = base.Database.Path
+	)
+
+	// 默认账号使用原配置
+	assert.Same(t, base, profileConfig(base, home, ""))
+	assert.Same(t, base, profileConfig(base, home, DefaultProfile))
+
+	alice := profileConfig(base, home, "alice")
+	bob := profileConfig(base, home, "bob")
+	assert.Equal(t, filepath.Join(home, ".ncmctl", "profiles", "alice", "cookie.json"), alice.Network.Cookie.Filepath)
+	assert.Equal(t, filepath.Join(home, ".ncmctl", "profiles", "alice", "database", "cookie"), alice.Network.Cookie.Database.Path)
+	assert.Equal(t, filepath.Join(home, ".ncmctl", "profiles", "alice", "database", "badger"), alice.Database.Path)
+	assert.NotEqual(t, alice.Network.Cookie.Filepath, bob.Network.Cookie.Filepath)
+	assert.NotEqual(t, alice.Database.Path, bob.Database.Path)
+
+	// CookieCloud未配置时保持为空
+	assert.Empty(t, alice.Network.Cookie.CookieCloud.Uuid)
+
+	// 不影响原配置
+	assert.Equal(t, cookiePath, base.Network.Cookie.Filepath)
+	assert.Equal(t, dbPath, base.Database.Path)
+	assert.Same(t, base.Log, alice.Log)
+
+	// CookieCloud的uuid按照账号区分
+	base.Network.Cookie.CookieCloud.Uuid = "uuid"
+	assert.Equal(t, "uuid", profileConfig(base, home, DefaultProfile).Network.Cookie.CookieCloud.Uuid)
+	assert.Equal(t, "uuid-alice", profileConfig(base, home, "alice").Network.Cookie.CookieCloud.Uuid)
+	assert.Equal(t, "uuid-bob", profileConfig(base, home, "bob").Network.Cookie.CookieCloud.Uuid)
+	assert.Equal(t, "uuid", base.Network.Cookie.CookieCloud.Uuid)
+}
+
+func TestAccount(t *testing.T) {
+	var (
+		s    = newServer(t)
+		home = t.TempDir()
+	)
+	_, err := execute(t, s, home, "--profile", "alice", "sign")
+	assert.ErrorContains(t, err, "profile alice not found")
+
+	// 登录时自动添加账号
+	_, err = execute(t, s, home, "--profile", "alice", "login", "cookie", "--format", "header", "MUSIC_U="+s.Account.Token)
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(profileDir(home, "alice"), "cookie.json"))
+	assert.NoFileExists(t, filepath.Join(home, ".ncmctl", "cookie.json"))
+
+	_, err = execute(t, s, home, "account", "switch", "bob")
+	assert.ErrorContains(t, err, "profile bob not found")
+	out, err := execute(t, s, home, "account", "switch", "alice")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "switch to alice")
+
+	out, err = execute(t, s, home, "account", "list")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "  default")
+	assert.Contains(t, out, "* alice")
+
+	// 移除当前账号后切换回默认账号
+	_, err = execute(t, s, home, "account", "remove", "alice")
+	assert.NoError(t, err)
+	assert.NoDirExists(t, profileDir(home, "alice"))
+	out, err = execute(t, s, home, "account", "list")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "* default")
+	assert.NotContains(t, out, "alice")
+}
@@ -124,9 +124,8 @@ func (c *Scrobble) execute(ctx context.Context) error {
 
 	// 刷新token过期时间
 	defer func() {
-		refresh, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{})
-		if err != nil || refresh.Code != 200 {
-			log.Warn("TokenRefresh resp:%+v err: %s", refresh, err)
+		if _, err := cli.Session().RefreshIfNeeded(ctx); err != nil {
+			log.Warn("RefreshIfNeeded: %s", err)
 		}
 	}()
 
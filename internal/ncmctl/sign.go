@@ -95,14 +95,18 @@ func (c *SignIn) execute(ctx context.Context) error {
 		return fmt.Errorf("need login")
 	}
 
-	// 执行云贝签到
-	resp, err := request.YunBeiSignIn(ctx, &weapi.YunBeiSignInReq{})
-	if err != nil {
+	// 云贝签到以及vip权益查询合并为一次批量请求
+	var (
+		resp weapi.YunBeiSignInResp
+		vip  weapi.VipGrowPointResp
+	)
+	batch := request.Batch(ctx,
+		weapi.BatchAPI{Key: "/api/pointmall/user/sign", Req: &weapi.YunBeiSignInReq{}, Resp: &resp},
+		weapi.BatchAPI{Key: "/api/vipnewcenter/app/level/growhpoint/basic", Req: &weapi.VipGrowPointReq{}, Resp: &vip},
+	)
+	if err := batch.Err("/api/pointmall/user/sign"); err != nil {
 		return fmt.Errorf("YunBeiSignIn: %w", err)
 	}
-	if resp.Code != 200 {
-		return fmt.Errorf("YunBeiSignIn: %+v", resp)
-	}
 	if resp.Data.Sign {
 		c.cmd.Println("云贝签到成功")
 	} else {
@@ -115,6 +119,8 @@ func (c *SignIn) execute(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("YunBeiSignInProgress: %w", err)
 		}
+
+		// 批量请求中同一个接口只能出现一次,因此领取奖励需要逐个请求
 		for _, v := range progress.Data.LotteryConfig {
 			if v.BaseLotteryId <= 0 && v.ExtraLotteryId <= 0 {
 				continue
@@ -127,6 +133,7 @@ func (c *SignIn) execute(ctx context.Context) error {
 			})
 			if err != nil {
 				log.Error("YunBeiSignLottery(%v): %s", v.BaseLotteryId, err)
+				continue
 			}
 			if reply.Data {
 				c.cmd.Printf("云贝连续签到天数=%v,奖励内容=%v 领取成功\n", v.SignDay, v.BaseGrant.Name)
@@ -134,7 +141,7 @@ func (c *SignIn) execute(ctx context.Context) error {
 			// todo: 满勤签到领取抽奖机会使用ExtraLotteryId,同时也是YunBeiSignLottery方法?
 		}
 
-		// 完成当前时刻可以领取的任务奖励
+		// 完成当前时刻可以领取的任务奖励,领取签到奖励后可能会完成新的任务,因此在领取之后查询
 		task, err := request.YunBeiTaskTodo(ctx, &weapi.YunBeiTaskTodoReq{})
 		if err != nil {
 			return fmt.Errorf("YunBeiTaskTodo: %w", err)
@@ -151,6 +158,7 @@ func (c *SignIn) execute(ctx context.Context) error {
 			})
 			if err != nil {
 				log.Error("YunBeiTaskFinish(%v): %s", v.UserTaskId, err)
+				continue
 			}
 			if reply.Code != 200 {
 				log.Error("YunBeiTaskFinish(%v) detail:%+v", v.UserTaskId, reply)
@@ -161,13 +169,9 @@ func (c *SignIn) execute(ctx context.Context) error {
 	}
 
 	// 查询vip权益
-	vip, err := request.VipGrowPoint(ctx, &weapi.VipGrowPointReq{})
-	if err != nil {
+	if err := batch.Err("/api/vipnewcenter/app/level/growhpoint/basic"); err != nil {
 		return fmt.Errorf("VipGrowPoint: %w", err)
 	}
-	if vip.Code != 200 {
-		return fmt.Errorf("VipGrowPoint: %+v", vip)
-	}
 	if vip.Data.UserLevel.LatestVipStatus != 1 {
 		c.cmd.Printf("暂无会员权益: %v\n", vip.Data.UserLevel.LatestVipStatus)
 		return nil
@@ -202,9 +206,8 @@ func (c *SignIn) execute(ctx context.Context) error {
 	}
 
 	// 刷新token过期时间
-	refresh, err := request.TokenRefresh(ctx, &weapi.TokenRefreshReq{})
-	if err != nil || refresh.Code != 200 {
-		log.Warn("TokenRefresh resp:%+v err: %s", refresh, err)
+	if _, err := cli.Session().RefreshIfNeeded(ctx); err != nil {
+		log.Warn("RefreshIfNeeded: %s", err)
 	}
 	return nil
 }
@@ -171,23 +171,67 @@ func (c *Task) execute(ctx context.Context, _ []string) error {
 		return fmt.Errorf("wrong time zone: %w", err)
 	}
 
-	cli, err := api.NewClient(c.root.Cfg.Network, c.l)
-	if err != nil {
-		return fmt.Errorf("NewClient: %w", err)
+	var job = cron.New(cron.WithLocation(local))
+	if err := c.schedule(ctx, job); err != nil {
+		return err
 	}
-	defer cli.Close(ctx)
+	job.Start()
 
-	request := weapi.New(cli)
-	if request.NeedLogin(ctx) {
+	nohup.Daemon(nohup.CloseHook(func(ctx context.Context) error {
+		job.Stop()
+		return nil
+	}))
+	return nil
+}
+
+// schedule 为账号注册定时任务,指定账号时只注册该账号任务,否则注册所有已登录账号的任务
+func (c *Task) schedule(ctx context.Context, job *cron.Cron) error {
+	var profiles = []string{c.root.Profile}
+	if c.root.Opts.Profile == "" {
+		profiles = c.root.profiles.Names()
+	}
+
+	var logged int
+	for _, name := range profiles {
+		root := c.root.WithProfile(name)
+		ok, err := c.login(ctx, root)
+		if err != nil {
+			return fmt.Errorf("[%s] %w", name, err)
+		}
+		if !ok {
+			c.cmd.Printf("[%s] need login, skip\n", name)
+			log.Warn("[%s] need login, skip", name)
+			continue
+		}
+		logged++
+		if err := c.register(ctx, job, root); err != nil {
+			return fmt.Errorf("[%s] %w", name, err)
+		}
+	}
+	if logged == 0 {
 		return fmt.Errorf("need login")
 	}
+	return nil
+}
+
+// login 检查账号是否已登录
+func (c *Task) login(ctx context.Context, root *Root) (bool, error) {
+	cli, err := api.NewClient(root.Cfg.Network, c.l)
+	if err != nil {
+		return false, fmt.Errorf("NewClient: %w", err)
+	}
+	defer cli.Close(ctx)
+	return !weapi.New(cli).NeedLogin(ctx), nil
+}
 
+// register 注册账号的定时任务
+func (c *Task) register(ctx context.Context, job *cron.Cron, root *Root) error {
 	var (
-		job     = cron.New(cron.WithLocation(local))
+		profile = root.Profile
 		partner = func() error {
-			c.cmd.Println("[partner] task register")
-			log.Info("[partner] task register")
-			partner := NewPartner(c.root, c.l)
+			c.cmd.Printf("[%s][partner] task register\n", profile)
+			log.Info("[%s][partner] task register", profile)
+			partner := NewPartner(root, c.l)
 			partner.cmd.DisableFlagParsing = true // 关闭子命令解析比如出现unknown flag错误
 			partner.opts = c.opts.PartnerOpts
 			if err := partner.validate(); err != nil {
@@ -195,23 +239,23 @@ func (c *Task) execute(ctx context.Context, _ []string) error {
 			}
 
 			id, err := job.AddFunc(c.opts.PartnerOptsCrontab, func() {
-				log.Info("[partner] task start")
+				log.Info("[%s][partner] task start", profile)
 				if err := partner.Command().ExecuteContext(ctx); err != nil {
-					log.Error("[partner] execute err: %s", err)
+					log.Error("[%s][partner] execute err: %s", profile, err)
 					return
 				}
-				log.Info("[partner] execute success")
+				log.Info("[%s][partner] execute success", profile)
 			})
 			if err != nil {
 				return fmt.Errorf("crontab error: %v", err)
 			}
-			log.Info("[partner] next execute: %s", job.Entry(id).Schedule.Next(time.Now()))
+			log.Info("[%s][partner] next execute: %s", profile, job.Entry(id).Schedule.Next(time.Now()))
 			return nil
 		}
 		scrobble = func() error {
-			c.cmd.Println("[scrobble] task register")
-			log.Info("[scrobble] task register")
-			s := NewScrobble(c.root, c.l)
+			c.cmd.Printf("[%s][scrobble] task register\n", profile)
+			log.Info("[%s][scrobble] task register", profile)
+			s := NewScrobble(root, c.l)
 			s.cmd.DisableFlagParsing = true
 			s.opts = c.opts.ScrobbleOpts
 			if err := s.validate(); err != nil {
@@ -219,23 +263,23 @@ func (c *Task) execute(ctx context.Context, _ []string) error {
 			}
 
 			id, err := job.AddFunc(c.opts.ScrobbleOptsCrontab, func() {
-				log.Info("[scrobble] task start")
+				log.Info("[%s][scrobble] task start", profile)
 				if err := s.Command().ExecuteContext(ctx); err != nil {
-					log.Error("[scrobble] execute err: %s", err)
+					log.Error("[%s][scrobble] execute err: %s", profile, err)
 					return
 				}
-				log.Info("[scrobble] execute success")
+				log.Info("[%s][scrobble] execute success", profile)
 			})
 			if err != nil {
 				return fmt.Errorf("[scrobble] crontab error: %v", err)
 			}
-			log.Info("[scrobble] next execute: %s", job.Entry(id).Schedule.Next(time.Now()))
+			log.Info("[%s][scrobble] next execute: %s", profile, job.Entry(id).Schedule.Next(time.Now()))
 			return nil
 		}
 		signIn = func() error {
-			c.cmd.Println("[sign] task register")
-			log.Info("[sign] task register")
-			signIn := NewSignIn(c.root, c.l)
+			c.cmd.Printf("[%s][sign] task register\n", profile)
+			log.Info("[%s][sign] task register", profile)
+			signIn := NewSignIn(root, c.l)
 			signIn.cmd.DisableFlagParsing = true
 			signIn.opts = c.opts.SignInOpts
 			if err := signIn.validate(); err != nil {
@@ -243,49 +287,39 @@ func (c *Task) execute(ctx context.Context, _ []string) error {
 			}
 
 			id, err := job.AddFunc(c.opts.SignInOptsCrontab, func() {
-				log.Info("[sign] task start")
+				log.Info("[%s][sign] task start", profile)
 				if err := signIn.Command().ExecuteContext(ctx); err != nil {
-					log.Error("[sign] execute err: %s", err)
+					log.Error("[%s][sign] execute err: %s", profile, err)
 					return
 				}
-				log.Info("[sign] execute success")
+				log.Info("[%s][sign] execute success", profile)
 			})
 			if err != nil {
 				return fmt.Errorf("[sign] crontab error: %v", err)
 			}
-			log.Info("[sign] next execute: %s", job.Entry(id).Schedule.Next(time.Now()))
+			log.Info("[%s][sign] next execute: %s", profile, job.Entry(id).Schedule.Next(time.Now()))
 			return nil
 		}
 	)
 
 	var o = c.opts
 	if o.RunAll || (!o.SignIn && !o.Partner && !o.Scrobble) {
-		if err := errors.Join(signIn(), partner(), scrobble()); err != nil {
+		return errors.Join(signIn(), partner(), scrobble())
+	}
+	if o.SignIn {
+		if err := signIn(); err != nil {
 			return err
 		}
-	} else {
-		if o.SignIn {
-			if err := signIn(); err != nil {
-				return err
-			}
-		}
-		if o.Partner {
-			if err := partner(); err != nil {
-				return err
-			}
+	}
+	if o.Partner {
+		if err := partner(); err != nil {
+			return err
 		}
-		if o.Scrobble {
-			if err := scrobble(); err != nil {
-				return err
-			}
+	}
+	if o.Scrobble {
+		if err := scrobble(); err != nil {
+			return err
 		}
 	}
-
-	job.Start()
-
-	nohup.Daemon(nohup.CloseHook(func(ctx context.Context) error {
-		job.Stop()
-		return nil
-	}))
 	return nil
 }
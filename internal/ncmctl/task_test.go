@@ -0,0 +1,85 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package ncmctl
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/fakeserver"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTask 创建定时任务命令,profile为空时对所有账号执行
+func newTask(t *testing.T, s *fakeserver.Server, home, profile string) (*Task, *bytes.Buffer) {
+	var (
+		out  bytes.Buffer
+		task = NewTask(newRoot(t, s, home, profile), log.New(&log.Config{Level: "debug", Stdout: true}))
+	)
+	task.cmd.SetOut(&out)
+	return task, &out
+}
+
+func TestTaskSchedule(t *testing.T) {
+	var (
+		s    = newServer(t)
+		home = t.TempDir()
+	)
+	task, _ := newTask(t, s, home, "")
+	assert.EqualError(t, task.schedule(context.TODO(), cron.New()), "need login")
+
+	// 默认账号以及alice已登录,bob未登录
+	login(t, s, home)
+	_, err := execute(t, s, home, "--profile", "alice", "login", "cookie", "--format", "header", "MUSIC_U="+s.Account.Token)
+	assert.NoError(t, err)
+	_, err = execute(t, s, home, "--profile", "bob", "login", "cookie", "--format", "header", "MUSIC_U=expired")
+	assert.NoError(t, err)
+
+	var job = cron.New()
+	task, out := newTask(t, s, home, "")
+	assert.NoError(t, task.schedule(context.TODO(), job))
+	assert.Len(t, job.Entries(), 6)
+	for _, name := range []string{"default", "alice"} {
+		for _, v := range []string{"partner", "scrobble", "sign"} {
+			assert.Contains(t, out.String(), "["+name+"]["+v+"] task register")
+		}
+	}
+	assert.Contains(t, out.String(), "[bob] need login, skip")
+	assert.NotContains(t, out.String(), "[bob][")
+
+	// 指定账号时只注册该账号
+	job = cron.New()
+	task, out = newTask(t, s, home, "alice")
+	task.opts.SignIn = true
+	assert.NoError(t, task.schedule(context.TODO(), job))
+	assert.Len(t, job.Entries(), 1)
+	assert.Equal(t, "[alice][sign] task register\n", out.String())
+
+	task, _ = newTask(t, s, home, "bob")
+	assert.EqualError(t, task.schedule(context.TODO(), cron.New()), "need login")
+}
@@ -69,7 +69,7 @@ func writeFile(cmd *cobra.Command, out string, data []byte) error {
 }
 
 var (
-	urlPattern = "/(song|artist|album|playlist)\\?id=(\\d+)"
+	urlPattern = "/(song|artist|album|playlist|user)(?:/home)?\\?id=(\\d+)"
 	reg        = regexp.MustCompile(urlPattern)
 )
 
@@ -160,12 +160,16 @@ func ParseCookeJson(r io.Reader) ([]*http.Cookie, error) {
 }
 
 type Music struct {
-	Id      int64
-	Name    string
-	Artist  []types.Artist
-	Album   types.Album
-	AlbumId int64
-	Time    int64
+	Id          int64
+	Name        string
+	Artist      []types.Artist
+	Album       types.Album
+	AlbumId     int64
+	Time        int64
+	Cd          string // 专辑中第几张CD,eg: "1" "1/2"
+	No          int64  // CD中第几首
+	PublishTime int64  // 发行时间,毫秒
+	Playlist    string // 所属歌单名
 }
 
 // NameString 返回去除特殊符号的歌曲名
@@ -24,6 +24,7 @@
 package cookie
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -32,19 +33,49 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/database"
 )
 
 type Config struct {
 	*Options
 	Filepath string        `json:"filepath" yaml:"filepath"`
 	Interval time.Duration `yaml:"interval" yaml:"interval"`
-	// crypto
+	// Crypto cookie文件加密配置
+	Crypto Crypto `json:"crypto" yaml:"crypto"`
+	// Storage 存储方式 file:本地文件(默认) database:数据库 cookiecloud:CookieCloud
+	Storage string `json:"storage" yaml:"storage"`
+	// Database 数据库存储配置,不能与其他程序使用同一个badger目录
+	Database database.Config `json:"database" yaml:"database"`
+	// CookieCloud CookieCloud存储配置
+	CookieCloud CookieCloud `json:"cookiecloud" yaml:"cookiecloud"`
+	keyring     Keyring
+	store       Store
 }
 
 func (p Config) Valid() error {
+	if err := p.Crypto.Valid(); err != nil {
+		return err
+	}
+	if p.store != nil {
+		return nil
+	}
+	switch p.Storage {
+	case "", StorageFile:
+		if p.Crypto.Backend == CryptoKeyring && p.Filepath == "" {
+			return errors.New("keyring backend requires cookie filepath")
+		}
+	case StorageDatabase:
+		if p.Database.Path == "" {
+			return errors.New("cookie.database.path is required")
+		}
+	case StorageCookieCloud:
+		return p.CookieCloud.Valid()
+	default:
+		return fmt.Errorf("cookie.storage %q not support", p.Storage)
+	}
 	return nil
 }
 
@@ -55,6 +86,9 @@ type Cookie struct {
 	async     bool
 	done      chan struct{}
 	closeOnce sync.Once
+	store     Store
+	closer    func(ctx context.Context) error
+	saved     []byte // 上次保存的内容,没有变化时不再保存
 }
 
 func NewCookie(opts ...Option) (*Cookie, error) {
@@ -62,6 +96,7 @@ func NewCookie(opts ...Option) (*Cookie, error) {
 		Options:  nil,
 		Filepath: "./cookie.json",
 		Interval: time.Second * 3,
+		keyring:  systemKeyring{},
 	}
 	for _, opt := range opts {
 		opt.apply(&cfg)
@@ -84,7 +119,13 @@ func NewCookie(opts ...Option) (*Cookie, error) {
 	if cfg.Interval <= 0 {
 		p.async = false
 	}
+	if err := p.newStore(); err != nil {
+		return nil, fmt.Errorf("newStore: %w", err)
+	}
 	if err := p.init(); err != nil {
+		if p.closer != nil {
+			_ = p.closer(context.Background())
+		}
 		return nil, fmt.Errorf("init: %w", err)
 	}
 	if p.async {
@@ -112,10 +153,91 @@ func (c *Cookie) Close(ctx context.Context) error {
 		if err := c.export(); err != nil {
 			log.Printf("cookie export err: %s", err)
 		}
+		if c.closer != nil {
+			if err := c.closer(ctx); err != nil {
+				log.Printf("cookie close store err: %s", err)
+			}
+		}
 	})
 	return nil
 }
 
+// Entry 获取url可以使用的指定名称cookie的完整信息,包括过期时间等,不存在或已过期时返回false
+func (c *Cookie) Entry(u *url.URL, name string) (Entry, bool) {
+	host, err := canonicalHost(u.Host)
+	if err != nil {
+		return Entry{}, false
+	}
+
+	c.jar.mu.Lock()
+	defer c.jar.mu.Unlock()
+
+	var (
+		now  = time.Now()
+		path = u.Path
+	)
+	if path == "" {
+		path = "/"
+	}
+	for _, e := range c.jar.entries[jarKey(host, c.jar.psList)] {
+		if e.Name != name || !e.domainMatch(host) || !e.pathMatch(path) {
+			continue
+		}
+		if e.Persistent && !e.Expires.After(now) {
+			continue
+		}
+		return Entry{
+			Name:       e.Name,
+			Value:      e.Value,
+			Domain:     e.Domain,
+			Path:       e.Path,
+			SameSite:   e.SameSite,
+			Secure:     e.Secure,
+			HttpOnly:   e.HttpOnly,
+			Persistent: e.Persistent,
+			HostOnly:   e.HostOnly,
+			Expires:    e.Expires,
+			Creation:   e.Creation,
+			LastAccess: e.LastAccess,
+			SeqNum:     e.seqNum,
+		}, true
+	}
+	return Entry{}, false
+}
+
+// Store 返回cookie使用的存储
+func (c *Cookie) Store() Store {
+	return c.store
+}
+
+func (c *Cookie) newStore() error {
+	if c.cfg.store != nil {
+		c.store = c.cfg.store
+		return nil
+	}
+	switch c.cfg.Storage {
+	case StorageDatabase:
+		db, err := openDatabase(c.cfg.Database)
+		if err != nil {
+			return fmt.Errorf("openDatabase: %w", err)
+		}
+		c.store, c.closer = NewDatabaseStore(db, "cookie", c.cfg.Crypto, c.cfg.keyring), db.Close
+	case StorageCookieCloud:
+		store, err := newCookieCloudStore(c.cfg.CookieCloud)
+		if err != nil {
+			return err
+		}
+		c.store = store
+	default:
+		store, err := NewFileStore(c.cfg.Filepath, c.cfg.Crypto, c.cfg.keyring)
+		if err != nil {
+			return err
+		}
+		c.store = store
+	}
+	return nil
+}
+
 func (c *Cookie) sync() {
 	tick := time.NewTicker(c.cfg.Interval)
 	defer tick.Stop()
@@ -133,29 +255,21 @@ func (c *Cookie) sync() {
 }
 
 func (c *Cookie) init() error {
-	// 如果文件存在则读取配置文件
-	if !fileExists(c.cfg.Filepath) {
-		log.Printf("cookie: warnning %s file not found", c.cfg.Filepath)
-		return os.MkdirAll(filepath.Dir(c.cfg.Filepath), os.ModePerm)
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
 
-	data, err := os.ReadFile(c.cfg.Filepath)
+	content, err := c.store.Load(ctx)
 	if err != nil {
 		return err
 	}
 
 	var (
-		content    map[string]map[string]Entry
 		imported   = make(map[string]map[string]entry)
 		nextSeqNum uint64
 	)
-	if err := json.Unmarshal(data, &content); err != nil {
-		return err
-	}
-	for domain, cookies := range content {
-		imported[domain] = make(map[string]entry)
-		for name, cookie := range cookies {
-			imported[domain][name] = entry{
+	for _, cookies := range content {
+		for _, cookie := range cookies {
+			e := entry{
 				Name:       cookie.Name,
 				Value:      cookie.Value,
 				Domain:     cookie.Domain,
@@ -170,7 +284,13 @@ func (c *Cookie) init() error {
 				LastAccess: cookie.LastAccess,
 				seqNum:     cookie.SeqNum,
 			}
-			if cookie.SeqNum > nextSeqNum {
+			// 不同存储的key不一定相同,按照jar的规则重新生成
+			key := jarKey(e.Domain, c.jar.psList)
+			if imported[key] == nil {
+				imported[key] = make(map[string]entry)
+			}
+			imported[key][e.id()] = e
+			if cookie.SeqNum >= nextSeqNum {
 				nextSeqNum = cookie.SeqNum + 1
 			}
 		}
@@ -179,14 +299,22 @@ func (c *Cookie) init() error {
 	c.jar.nextSeqNum = nextSeqNum
 	c.jar.entries = imported
 	c.mu.Unlock()
+
+	// 记录加载的内容,没有变化时退出不会覆盖存储中其他客户端写入的数据
+	data, err := json.Marshal(c.entries())
+	if err != nil {
+		return err
+	}
+	c.saved = data
 	return nil
 }
 
-func (c *Cookie) export() error {
+// entries 导出jar中的cookie
+func (c *Cookie) entries() Entries {
 	c.jar.mu.Lock()
 	defer c.jar.mu.Unlock()
 
-	var exported = make(map[string]map[string]Entry)
+	var exported = make(Entries)
 	for domain, cookies := range c.jar.entries {
 		exported[domain] = make(map[string]Entry)
 		for name, cookie := range cookies {
@@ -207,19 +335,27 @@ func (c *Cookie) export() error {
 			}
 		}
 	}
+	return exported
+}
+
+func (c *Cookie) export() error {
+	exported := c.entries()
 	data, err := json.Marshal(exported)
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(c.cfg.Filepath, data, os.ModePerm); err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			if err := os.MkdirAll(filepath.Dir(c.cfg.Filepath), os.ModePerm); err != nil {
-				return err
-			}
-			return nil
-		}
-		return fmt.Errorf("WriteFile: %w", err)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if bytes.Equal(data, c.saved) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := c.store.Save(ctx, exported); err != nil {
+		return err
 	}
+	c.saved = data
 	return nil
 }
 
@@ -269,6 +405,48 @@ func WithFilePath(filePath string) Option {
 	})
 }
 
+// WithCrypto sets the cookie file encryption.
+func WithCrypto(crypto Crypto) Option {
+	return optionFunc(func(p *Config) {
+		p.Crypto = crypto
+	})
+}
+
+// WithKeyring sets the keyring used by keyring backend.
+func WithKeyring(keyring Keyring) Option {
+	return optionFunc(func(p *Config) {
+		p.keyring = keyring
+	})
+}
+
+// WithStorage sets the storage type.
+func WithStorage(storage string) Option {
+	return optionFunc(func(p *Config) {
+		p.Storage = storage
+	})
+}
+
+// WithDatabase sets the database storage config.
+func WithDatabase(cfg database.Config) Option {
+	return optionFunc(func(p *Config) {
+		p.Database = cfg
+	})
+}
+
+// WithCookieCloud sets the cookiecloud storage config.
+func WithCookieCloud(cfg CookieCloud) Option {
+	return optionFunc(func(p *Config) {
+		p.CookieCloud = cfg
+	})
+}
+
+// WithStore sets a custom store, it takes precedence over storage type.
+func WithStore(store Store) Option {
+	return optionFunc(func(p *Config) {
+		p.store = store
+	})
+}
+
 // WithPublicSuffixList sets the public suffix list.
 func WithPublicSuffixList(list PublicSuffixList) Option {
 	return optionFunc(func(p *Config) {
@@ -0,0 +1,287 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package cookie
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// CryptoAuto 沿用已存在文件的加密方式,新文件使用明文
+	CryptoAuto = ""
+	// CryptoNone 明文保存
+	CryptoNone = "none"
+	// CryptoPassphrase 使用口令经过scrypt派生密钥后加密
+	CryptoPassphrase = "passphrase"
+	// CryptoKeyring 使用保存在系统密钥环中的随机密钥加密
+	CryptoKeyring = "keyring"
+)
+
+// PassphraseEnv 加密口令环境变量,优先级高于口令文件
+const PassphraseEnv = "NCM_COOKIE_PASSPHRASE"
+
+// KeyringService 系统密钥环中保存密钥使用的服务名称
+const KeyringService = "netease-cloud-music"
+
+var ErrPassphraseRequired = errors.New("cookie passphrase is required, set env " + PassphraseEnv + " or cookie.crypto.passphraseFile")
+
+// Crypto cookie文件加密配置
+type Crypto struct {
+	// Backend 加密方式 none:明文 passphrase:口令加密 keyring:系统密钥环 为空时沿用已存在文件的加密方式
+	Backend string `json:"backend" yaml:"backend"`
+	// PassphraseFile 口令文件路径,适用于docker secret等场景
+	PassphraseFile string `json:"passphraseFile" yaml:"passphraseFile"`
+}
+
+func (c Crypto) Valid() error {
+	switch c.Backend {
+	case CryptoAuto, CryptoNone, CryptoPassphrase, CryptoKeyring:
+		return nil
+	default:
+		return fmt.Errorf("cookie.crypto.backend %q not support", c.Backend)
+	}
+}
+
+// envelope 加密后的文件内容
+type envelope struct {
+	Version int    `json:"version"`
+	Backend string `json:"backend"`
+	Cipher  string `json:"cipher"`
+	KDF     string `json:"kdf,omitempty"`
+	Salt    []byte `json:"salt,omitempty"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// parseEnvelope 解析加密文件,明文文件返回false
+func parseEnvelope(data []byte) (*envelope, bool) {
+	var env envelope
+	if !bytes.Contains(data, []byte(`"cipher"`)) {
+		return nil, false
+	}
+	if err := json.Unmarshal(data, &env); err != nil || env.Cipher == "" || env.Backend == "" {
+		return nil, false
+	}
+	return &env, true
+}
+
+// sealer 使用aes-256-gcm加密cookie文件
+type sealer struct {
+	backend string
+	salt    []byte
+	key     []byte
+}
+
+func (s *sealer) seal(plain []byte) ([]byte, error) {
+	aead, err := newGCM(s.key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("rand: %w", err)
+	}
+	env := envelope{
+		Version: 1,
+		Backend: s.backend,
+		Cipher:  "aes-256-gcm",
+		Salt:    s.salt,
+		Nonce:   nonce,
+		Data:    aead.Seal(nil, nonce, plain, []byte(s.backend)),
+	}
+	if s.backend == CryptoPassphrase {
+		env.KDF = "scrypt"
+	}
+	return json.Marshal(env)
+}
+
+func (s *sealer) open(env *envelope) ([]byte, error) {
+	aead, err := newGCM(s.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid nonce size")
+	}
+	plain, err := aead.Open(nil, env.Nonce, env.Data, []byte(env.Backend))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt cookie file failed, wrong passphrase or key: %w", err)
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("aes.NewCipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// codec 负责cookie数据的序列化以及加解密
+type codec struct {
+	crypto  Crypto
+	keyring Keyring
+	account string // 系统密钥环中的账号
+	sealer  *sealer
+}
+
+// newSealer 根据加密方式创建sealer,salt为空时生成新的salt. 明文返回nil
+func (c *codec) newSealer(backend string, salt []byte) (*sealer, error) {
+	switch backend {
+	case CryptoAuto, CryptoNone:
+		return nil, nil
+	case CryptoPassphrase:
+		pass, err := c.passphrase()
+		if err != nil {
+			return nil, err
+		}
+		if len(salt) == 0 {
+			salt = make([]byte, 16)
+			if _, err := rand.Read(salt); err != nil {
+				return nil, fmt.Errorf("rand: %w", err)
+			}
+		}
+		key, err := scrypt.Key(pass, salt, 1<<15, 8, 1, 32)
+		if err != nil {
+			return nil, fmt.Errorf("scrypt: %w", err)
+		}
+		return &sealer{backend: backend, salt: salt, key: key}, nil
+	case CryptoKeyring:
+		key, err := c.keyringKey()
+		if err != nil {
+			return nil, err
+		}
+		return &sealer{backend: backend, key: key}, nil
+	default:
+		return nil, fmt.Errorf("cookie crypto backend %q not support", backend)
+	}
+}
+
+func (c *codec) passphrase() ([]byte, error) {
+	if pass := os.Getenv(PassphraseEnv); pass != "" {
+		return []byte(pass), nil
+	}
+	if file := c.crypto.PassphraseFile; file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("ReadFile: %w", err)
+		}
+		if pass := strings.TrimSpace(string(data)); pass != "" {
+			return []byte(pass), nil
+		}
+	}
+	return nil, ErrPassphraseRequired
+}
+
+// keyringKey 从系统密钥环获取密钥,不存在时生成随机密钥并保存. 每个存储使用单独的密钥
+func (c *codec) keyringKey() ([]byte, error) {
+	var account = c.account
+	secret, err := c.keyring.Get(KeyringService, account)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("keyring %s: invalid key", account)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, ErrKeyringNotFound) {
+		return nil, fmt.Errorf("keyring get: %w", err)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("rand: %w", err)
+	}
+	if err := c.keyring.Set(KeyringService, account, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, fmt.Errorf("keyring set: %w", err)
+	}
+	return key, nil
+}
+
+// init 存储中没有数据时根据配置创建sealer
+func (c *codec) init() error {
+	s, err := c.newSealer(c.crypto.Backend, nil)
+	if err != nil {
+		return err
+	}
+	c.sealer = s
+	return nil
+}
+
+// decode 解密并解析数据,明文数据在配置了加密时返回migrate为true,需要重新保存为密文
+func (c *codec) decode(data []byte) (entries Entries, migrate bool, err error) {
+	env, ok := parseEnvelope(data)
+	if !ok {
+		if err := c.init(); err != nil {
+			return nil, false, err
+		}
+		migrate = c.sealer != nil
+	} else {
+		s, err := c.newSealer(env.Backend, env.Salt)
+		if err != nil {
+			return nil, false, err
+		}
+		if data, err = s.open(env); err != nil {
+			return nil, false, err
+		}
+		c.sealer = s
+		// 沿用数据的加密方式,除非指定了其他方式
+		if backend := c.crypto.Backend; backend != CryptoAuto && backend != env.Backend {
+			if err := c.init(); err != nil {
+				return nil, false, err
+			}
+			migrate = true
+		}
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, false, err
+	}
+	return entries, migrate, nil
+}
+
+// encode 序列化并加密数据
+func (c *codec) encode(entries Entries) ([]byte, error) {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	if c.sealer == nil {
+		return data, nil
+	}
+	if data, err = c.sealer.seal(data); err != nil {
+		return nil, fmt.Errorf("seal: %w", err)
+	}
+	return data, nil
+}
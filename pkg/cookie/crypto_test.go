@@ -0,0 +1,130 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package cookie
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrypto(t *testing.T) {
+	var (
+		file = filepath.Join(t.TempDir(), "cookie.json")
+		u    = &url.URL{Scheme: "https", Host: "music.163.com"}
+	)
+	t.Setenv(PassphraseEnv, "secret")
+
+	jar, err := NewCookie(WithSyncInterval(0), WithFilePath(file), WithCrypto(Crypto{Backend: CryptoPassphrase}))
+	assert.NoError(t, err)
+	jar.SetCookies(u, []*http.Cookie{{Name: "MUSIC_U", Value: "token"}})
+
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "MUSIC_U")
+	env, ok := parseEnvelope(data)
+	assert.True(t, ok)
+	assert.Equal(t, CryptoPassphrase, env.Backend)
+
+	info, err := os.Stat(file)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// 未指定加密方式时沿用文件的加密方式
+	jar, err = NewCookie(WithSyncInterval(0), WithFilePath(file))
+	assert.NoError(t, err)
+	assert.Equal(t, "token", value(jar.Cookies(u), "MUSIC_U"))
+	assert.NoError(t, jar.Close(context.Background()))
+	data, err = os.ReadFile(file)
+	assert.NoError(t, err)
+	_, ok = parseEnvelope(data)
+	assert.True(t, ok)
+
+	// 口令错误
+	t.Setenv(PassphraseEnv, "wrong")
+	_, err = NewCookie(WithSyncInterval(0), WithFilePath(file))
+	assert.Error(t, err)
+
+	// 缺少口令
+	t.Setenv(PassphraseEnv, "")
+	_, err = NewCookie(WithSyncInterval(0), WithFilePath(file))
+	assert.ErrorIs(t, err, ErrPassphraseRequired)
+
+	// 口令文件,并解密为明文
+	pass := filepath.Join(t.TempDir(), "passphrase")
+	assert.NoError(t, os.WriteFile(pass, []byte("secret\n"), 0600))
+	jar, err = NewCookie(WithSyncInterval(0), WithFilePath(file), WithCrypto(Crypto{Backend: CryptoNone, PassphraseFile: pass}))
+	assert.NoError(t, err)
+	assert.NoError(t, jar.Close(context.Background()))
+	data, err = os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "MUSIC_U")
+}
+
+func TestCryptoMigrate(t *testing.T) {
+	var (
+		file    = filepath.Join(t.TempDir(), "cookie.json")
+		u       = &url.URL{Scheme: "https", Host: "music.163.com"}
+		keyring = &MemoryKeyring{}
+	)
+
+	jar, err := NewCookie(WithSyncInterval(0), WithFilePath(file))
+	assert.NoError(t, err)
+	jar.SetCookies(u, []*http.Cookie{{Name: "MUSIC_U", Value: "token"}})
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "MUSIC_U")
+
+	// 明文文件迁移为keyring加密
+	jar, err = NewCookie(WithSyncInterval(0), WithFilePath(file), WithKeyring(keyring), WithCrypto(Crypto{Backend: CryptoKeyring}))
+	assert.NoError(t, err)
+	assert.Equal(t, "token", value(jar.Cookies(u), "MUSIC_U"))
+	data, err = os.ReadFile(file)
+	assert.NoError(t, err)
+	env, ok := parseEnvelope(data)
+	assert.True(t, ok)
+	assert.Equal(t, CryptoKeyring, env.Backend)
+
+	jar, err = NewCookie(WithSyncInterval(0), WithFilePath(file), WithKeyring(keyring))
+	assert.NoError(t, err)
+	assert.Equal(t, "token", value(jar.Cookies(u), "MUSIC_U"))
+
+	// 密钥丢失
+	_, err = NewCookie(WithSyncInterval(0), WithFilePath(file), WithKeyring(&MemoryKeyring{}))
+	assert.Error(t, err)
+}
+
+func value(cookies []*http.Cookie, name string) string {
+	for _, c := range cookies {
+		if c.Name == name {
+			return c.Value
+		}
+	}
+	return ""
+}
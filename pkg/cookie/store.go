@@ -0,0 +1,116 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package cookie
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// StorageFile 保存到本地json文件
+	StorageFile = "file"
+	// StorageDatabase 保存到数据库
+	StorageDatabase = "database"
+	// StorageCookieCloud 保存到CookieCloud,多台机器共享登录状态
+	StorageCookieCloud = "cookiecloud"
+)
+
+// Entries cookie数据,第一层key为jarKey,第二层key为cookie id
+type Entries map[string]map[string]Entry
+
+// Store cookie持久化存储
+type Store interface {
+	// Load 加载cookie,没有数据时返回空
+	Load(ctx context.Context) (Entries, error)
+	// Save 保存cookie
+	Save(ctx context.Context, entries Entries) error
+}
+
+// FileStore 使用本地json文件保存cookie,支持加密
+type FileStore struct {
+	path  string
+	codec *codec
+}
+
+func NewFileStore(path string, crypto Crypto, keyring Keyring) (*FileStore, error) {
+	account, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("Abs: %w", err)
+	}
+	return &FileStore{
+		path:  path,
+		codec: &codec{crypto: crypto, keyring: keyring, account: account},
+	}, nil
+}
+
+func (s *FileStore) Load(ctx context.Context) (Entries, error) {
+	// 如果文件存在则读取配置文件
+	if !fileExists(s.path) {
+		log.Printf("cookie: warnning %s file not found", s.path)
+		if err := s.codec.init(); err != nil {
+			return nil, err
+		}
+		return nil, os.MkdirAll(filepath.Dir(s.path), os.ModePerm)
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	entries, migrate, err := s.codec.decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if migrate {
+		log.Printf("cookie: migrate %s crypto to %q", s.path, s.codec.crypto.Backend)
+		if err := s.Save(ctx, entries); err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+	}
+	return entries, nil
+}
+
+func (s *FileStore) Save(_ context.Context, entries Entries) error {
+	data, err := s.codec.encode(entries)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(s.path, data, 0600)
+	if errors.Is(err, os.ErrNotExist) {
+		// 目录不存在时创建后重新写入
+		if err := os.MkdirAll(filepath.Dir(s.path), os.ModePerm); err != nil {
+			return fmt.Errorf("MkdirAll: %w", err)
+		}
+		err = os.WriteFile(s.path, data, 0600)
+	}
+	if err != nil {
+		return fmt.Errorf("WriteFile: %w", err)
+	}
+	return nil
+}
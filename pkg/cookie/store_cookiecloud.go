@@ -0,0 +1,263 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package cookie
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/cookiecloud"
+	"github.com/chaunsin/netease-cloud-music/pkg/transport"
+)
+
+// CookieCloud CookieCloud存储配置
+type CookieCloud struct {
+	// ApiUrl CookieCloud服务地址
+	ApiUrl string `json:"apiUrl" yaml:"apiUrl"`
+	// Uuid 用户KEY
+	Uuid string `json:"uuid" yaml:"uuid"`
+	// Password 端对端加密密码
+	Password string `json:"password" yaml:"password"`
+	// Timeout 请求超时时间 默认30s
+	Timeout time.Duration `json:"timeout" yaml:"timeout"`
+	// Transport 传输层配置,由调用方设置
+	Transport transport.Config `json:"-" yaml:"-"`
+}
+
+func (c CookieCloud) Valid() error {
+	if c.ApiUrl == "" {
+		return errors.New("cookie.cookiecloud.apiUrl is required")
+	}
+	if c.Uuid == "" {
+		return errors.New("cookie.cookiecloud.uuid is required")
+	}
+	if c.Password == "" {
+		return errors.New("cookie.cookiecloud.password is required")
+	}
+	return nil
+}
+
+// CookieCloudStore 使用CookieCloud保存cookie,只同步网易相关域名的cookie,其他域名的数据保持不变
+type CookieCloudStore struct {
+	cli      *cookiecloud.Client
+	uuid     string
+	password string
+	mu       sync.Mutex
+	last     []byte // 上次同步的内容,没有变化时不再推送
+}
+
+func NewCookieCloudStore(cli *cookiecloud.Client, uuid, password string) *CookieCloudStore {
+	return &CookieCloudStore{cli: cli, uuid: uuid, password: password}
+}
+
+func newCookieCloudStore(cfg CookieCloud) (*CookieCloudStore, error) {
+	if err := cfg.Valid(); err != nil {
+		return nil, err
+	}
+	cli, err := cookiecloud.NewClient(&cookiecloud.Config{
+		ApiUrl:    cfg.ApiUrl,
+		Timeout:   cmp.Or(cfg.Timeout, 30*time.Second),
+		Retry:     3,
+		Transport: cfg.Transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cookiecloud.NewClient: %w", err)
+	}
+	return NewCookieCloudStore(cli, cfg.Uuid, cfg.Password), nil
+}
+
+func (s *CookieCloudStore) Load(ctx context.Context) (Entries, error) {
+	resp, err := s.cli.Get(ctx, &cookiecloud.GetReq{Uuid: s.uuid, Password: s.password})
+	if err != nil {
+		if errors.Is(err, cookiecloud.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cookiecloud.Get: %w", err)
+	}
+
+	var (
+		now     = time.Now()
+		entries = make(Entries)
+		data    = netease(resp.CookieData)
+	)
+	for _, cookies := range data {
+		for _, v := range cookies {
+			e := Entry{
+				Name:       v.Name,
+				Value:      v.Value,
+				Domain:     strings.TrimPrefix(v.Domain, "."),
+				Path:       v.Path,
+				SameSite:   sameSite(v.SameSite),
+				Secure:     v.Secure,
+				HttpOnly:   v.HttpOnly,
+				Persistent: !v.Session,
+				HostOnly:   v.HostOnly,
+				Creation:   now,
+				LastAccess: now,
+			}
+			if e.Persistent {
+				e.Expires = v.GetExpired()
+				if !e.Expires.After(now) {
+					continue
+				}
+			}
+			if entries[e.Domain] == nil {
+				entries[e.Domain] = make(map[string]Entry)
+			}
+			entries[e.Domain][e.Name+";"+e.Path] = e
+		}
+	}
+
+	s.mu.Lock()
+	s.last, _ = json.Marshal(data)
+	s.mu.Unlock()
+	return entries, nil
+}
+
+func (s *CookieCloudStore) Save(ctx context.Context, entries Entries) error {
+	var (
+		now  = time.Now()
+		data = make(map[string][]cookiecloud.CookieData)
+	)
+	for _, cookies := range entries {
+		for _, e := range cookies {
+			if !isNetease(e.Domain) || (e.Persistent && !e.Expires.After(now)) {
+				continue
+			}
+			v := cookiecloud.CookieData{
+				Domain:   e.Domain,
+				HostOnly: e.HostOnly,
+				HttpOnly: e.HttpOnly,
+				Name:     e.Name,
+				Path:     e.Path,
+				SameSite: browserSameSite(e.SameSite),
+				Secure:   e.Secure,
+				Session:  !e.Persistent,
+				StoreId:  "0",
+				Value:    e.Value,
+			}
+			if !e.HostOnly {
+				v.Domain = "." + e.Domain
+			}
+			if e.Persistent {
+				v.ExpirationDate = float64(e.Expires.UnixNano()) / 1e9
+			}
+			data[e.Domain] = append(data[e.Domain], v)
+		}
+	}
+	for _, v := range data {
+		slices.SortFunc(v, func(a, b cookiecloud.CookieData) int {
+			return cmp.Or(cmp.Compare(a.Domain, b.Domain), cmp.Compare(a.Path, b.Path), cmp.Compare(a.Name, b.Name))
+		})
+	}
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bytes.Equal(content, s.last) {
+		return nil
+	}
+
+	// 保留服务端其他域名的cookie
+	var cookie = cookiecloud.Cookie{CookieData: make(map[string][]cookiecloud.CookieData)}
+	resp, err := s.cli.Get(ctx, &cookiecloud.GetReq{Uuid: s.uuid, Password: s.password})
+	if err != nil && !errors.Is(err, cookiecloud.ErrNotFound) {
+		return fmt.Errorf("cookiecloud.Get: %w", err)
+	}
+	if err == nil {
+		cookie.LocalStorageData = resp.LocalStorageData
+		maps.Copy(cookie.CookieData, resp.CookieData)
+		maps.DeleteFunc(cookie.CookieData, func(domain string, _ []cookiecloud.CookieData) bool {
+			return isNetease(domain)
+		})
+	}
+	maps.Copy(cookie.CookieData, data)
+	cookie.UpdateTime = now
+
+	reply, err := s.cli.Push(ctx, &cookiecloud.PushReq{Uuid: s.uuid, Password: s.password, Cookie: cookie})
+	if err != nil {
+		return fmt.Errorf("cookiecloud.Push: %w", err)
+	}
+	if reply.Action != "done" {
+		return fmt.Errorf("cookiecloud.Push: %+v", reply)
+	}
+	s.last = content
+	return nil
+}
+
+// netease 过滤出网易相关域名的cookie
+func netease(data map[string][]cookiecloud.CookieData) map[string][]cookiecloud.CookieData {
+	var resp = make(map[string][]cookiecloud.CookieData)
+	for domain, cookies := range data {
+		if isNetease(domain) {
+			resp[domain] = cookies
+		}
+	}
+	return resp
+}
+
+func isNetease(domain string) bool {
+	domain = strings.TrimPrefix(domain, ".")
+	return domain == "163.com" || strings.HasSuffix(domain, ".163.com")
+}
+
+// sameSite 将浏览器插件中的sameSite转换为jar中保存的格式
+func sameSite(v string) string {
+	switch strings.ToLower(v) {
+	case "lax":
+		return "SameSite=Lax"
+	case "strict":
+		return "SameSite=Strict"
+	case "none", "no_restriction":
+		return "SameSite=None"
+	default:
+		return ""
+	}
+}
+
+// browserSameSite 将jar中保存的sameSite转换为浏览器插件中的格式
+func browserSameSite(v string) string {
+	switch v {
+	case "SameSite=Lax":
+		return "lax"
+	case "SameSite=Strict":
+		return "strict"
+	case "SameSite=None":
+		return "no_restriction"
+	default:
+		return "unspecified"
+	}
+}
@@ -0,0 +1,134 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package cookie
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/database"
+)
+
+// KV 数据库存储需要的接口, database.Database 满足该接口
+type KV interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value string, ttl ...time.Duration) error
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// DatabaseStore 使用数据库保存cookie,支持加密
+type DatabaseStore struct {
+	db    KV
+	key   string
+	codec *codec
+}
+
+func NewDatabaseStore(db KV, key string, crypto Crypto, keyring Keyring) *DatabaseStore {
+	return &DatabaseStore{
+		db:    db,
+		key:   key,
+		codec: &codec{crypto: crypto, keyring: keyring, account: "database/" + key},
+	}
+}
+
+func (s *DatabaseStore) Load(ctx context.Context) (Entries, error) {
+	ok, err := s.db.Exists(ctx, s.key)
+	if err != nil {
+		return nil, fmt.Errorf("Exists: %w", err)
+	}
+	if !ok {
+		return nil, s.codec.init()
+	}
+	data, err := s.db.Get(ctx, s.key)
+	if err != nil {
+		return nil, fmt.Errorf("Get: %w", err)
+	}
+	entries, migrate, err := s.codec.decode([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if migrate {
+		if err := s.Save(ctx, entries); err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+	}
+	return entries, nil
+}
+
+func (s *DatabaseStore) Save(ctx context.Context, entries Entries) error {
+	data, err := s.codec.encode(entries)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Set(ctx, s.key, string(data)); err != nil {
+		return fmt.Errorf("Set: %w", err)
+	}
+	return nil
+}
+
+// badger同一目录只能被打开一次,同一进程中的多个客户端共享数据库连接
+var databases = struct {
+	sync.Mutex
+	m map[string]*sharedDatabase
+}{m: make(map[string]*sharedDatabase)}
+
+type sharedDatabase struct {
+	database.Database
+	path string
+	ref  int
+}
+
+// openDatabase 打开数据库,相同路径的数据库会复用
+func openDatabase(cfg database.Config) (*sharedDatabase, error) {
+	path, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("Abs: %w", err)
+	}
+	databases.Lock()
+	defer databases.Unlock()
+	if db, ok := databases.m[path]; ok {
+		db.ref++
+		return db, nil
+	}
+	db, err := database.New(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	shared := &sharedDatabase{Database: db, path: path, ref: 1}
+	databases.m[path] = shared
+	return shared, nil
+}
+
+func (db *sharedDatabase) Close(ctx context.Context) error {
+	databases.Lock()
+	defer databases.Unlock()
+	if db.ref--; db.ref > 0 {
+		return nil
+	}
+	delete(databases.m, db.path)
+	return db.Database.Close(ctx)
+}
@@ -0,0 +1,170 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package cookie
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/cookiecloud"
+	"github.com/chaunsin/netease-cloud-music/pkg/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStoreSave(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		path    = filepath.Join(t.TempDir(), "not", "exist", "cookie.json")
+		entries = Entries{"music.163.com": {"music.163.com;/;MUSIC_U": {Name: "MUSIC_U", Value: "token", Domain: "music.163.com", Path: "/"}}}
+	)
+	store, err := NewFileStore(path, Crypto{Backend: CryptoNone}, nil)
+	assert.NoError(t, err)
+	// 目录不存在时创建目录后写入
+	assert.NoError(t, store.Save(ctx, entries))
+	assert.FileExists(t, path)
+	got, err := store.Load(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, entries, got)
+}
+
+func TestDatabaseStore(t *testing.T) {
+	var (
+		cfg = database.Config{Driver: "badger", Path: t.TempDir()}
+		u   = &url.URL{Scheme: "https", Host: "music.163.com"}
+	)
+	jar, err := NewCookie(WithSyncInterval(0), WithStorage(StorageDatabase), WithDatabase(cfg))
+	assert.NoError(t, err)
+	// 同一进程中共享数据库
+	jar2, err := NewCookie(WithSyncInterval(0), WithStorage(StorageDatabase), WithDatabase(cfg))
+	assert.NoError(t, err)
+	jar.SetCookies(u, []*http.Cookie{{Name: "MUSIC_U", Value: "token", MaxAge: 3600}})
+	assert.NoError(t, jar.Close(context.Background()))
+	assert.NoError(t, jar2.Close(context.Background()))
+
+	jar, err = NewCookie(WithSyncInterval(0), WithStorage(StorageDatabase), WithDatabase(cfg))
+	assert.NoError(t, err)
+	assert.Equal(t, "token", value(jar.Cookies(u), "MUSIC_U"))
+	assert.NoError(t, jar.Close(context.Background()))
+}
+
+// cookieCloudServer 模拟CookieCloud服务端
+func cookieCloudServer(t *testing.T) (*httptest.Server, func() cookiecloud.Cookie) {
+	var (
+		mu     sync.Mutex
+		data   = map[string]string{}
+		pushed = 0
+		mux    = http.NewServeMux()
+	)
+	mux.HandleFunc("POST /update", func(w http.ResponseWriter, r *http.Request) {
+		var body cookiecloud.Body
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		data[body.Uuid] = body.Encrypted
+		pushed++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(cookiecloud.PushResp{Action: "done"})
+	})
+	mux.HandleFunc("GET /get/{uuid}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		encrypted, ok := data[r.PathValue("uuid")]
+		mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(cookiecloud.Body{Uuid: r.PathValue("uuid"), Encrypted: encrypted})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, func() cookiecloud.Cookie {
+		mu.Lock()
+		defer mu.Unlock()
+		var cookie cookiecloud.Cookie
+		plain, err := cookiecloud.Decrypt(cookiecloud.Md5String("uuid", "-", "password")[:16], data["uuid"])
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(plain, &cookie))
+		return cookie
+	}
+}
+
+func TestCookieCloudStore(t *testing.T) {
+	var (
+		server, cloud = cookieCloudServer(t)
+		cfg           = CookieCloud{ApiUrl: server.URL, Uuid: "uuid", Password: "password"}
+		u             = &url.URL{Scheme: "https", Host: "music.163.com"}
+	)
+
+	// 服务端存在其他域名的数据
+	cli, err := cookiecloud.NewClient(&cookiecloud.Config{ApiUrl: server.URL, Timeout: time.Second})
+	assert.NoError(t, err)
+	_, err = cli.Push(context.Background(), &cookiecloud.PushReq{Uuid: "uuid", Password: "password", Cookie: cookiecloud.Cookie{
+		CookieData: map[string][]cookiecloud.CookieData{
+			"example.com": {{Domain: "example.com", HostOnly: true, Name: "sid", Path: "/", Session: true, Value: "1"}},
+		},
+	}})
+	assert.NoError(t, err)
+
+	// 机器A登录
+	a, err := NewCookie(WithSyncInterval(0), WithStorage(StorageCookieCloud), WithCookieCloud(cfg))
+	assert.NoError(t, err)
+	a.SetCookies(u, []*http.Cookie{{Name: "MUSIC_U", Value: "token", Domain: ".music.163.com", Path: "/", MaxAge: 3600, HttpOnly: true}})
+
+	data := cloud()
+	assert.Len(t, data.CookieData["example.com"], 1)
+	assert.Len(t, data.CookieData["music.163.com"], 1)
+	assert.Equal(t, ".music.163.com", data.CookieData["music.163.com"][0].Domain)
+
+	// 机器B共享登录状态,并刷新token
+	b, err := NewCookie(WithSyncInterval(0), WithStorage(StorageCookieCloud), WithCookieCloud(cfg))
+	assert.NoError(t, err)
+	assert.Equal(t, "token", value(b.Cookies(u), "MUSIC_U"))
+	b.SetCookies(u, []*http.Cookie{{Name: "MUSIC_U", Value: "refreshed", Domain: ".music.163.com", Path: "/", MaxAge: 3600, HttpOnly: true}})
+	assert.NoError(t, b.Close(context.Background()))
+
+	data = cloud()
+	assert.Len(t, data.CookieData["example.com"], 1)
+	assert.Equal(t, "refreshed", data.CookieData["music.163.com"][0].Value)
+
+	c, err := NewCookie(WithSyncInterval(0), WithStorage(StorageCookieCloud), WithCookieCloud(cfg))
+	assert.NoError(t, err)
+	assert.Equal(t, "refreshed", value(c.Cookies(u), "MUSIC_U"))
+}
+
+func TestStorageValid(t *testing.T) {
+	_, err := NewCookie(WithStorage("redis"))
+	assert.Error(t, err)
+	_, err = NewCookie(WithStorage(StorageCookieCloud))
+	assert.Error(t, err)
+}
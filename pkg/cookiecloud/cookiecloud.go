@@ -25,14 +25,19 @@ package cookiecloud
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/chaunsin/netease-cloud-music/pkg/transport"
+
 	"github.com/go-resty/resty/v2"
 )
 
+// ErrNotFound uuid在服务端不存在,通常是还未推送过数据
+var ErrNotFound = errors.New("cookiecloud: not found")
+
 type Body struct {
 	Uuid      string `json:"uuid"`
 	Encrypted string `json:"encrypted"`
@@ -90,6 +95,8 @@ type Config struct {
 	Timeout time.Duration `json:"timeout" yaml:"timeout"`
 	Retry   int           `json:"retry" yaml:"retry"`
 	Debug   bool          `json:"debug" yaml:"debug"`
+	// Transport 代理、证书等传输层配置
+	Transport transport.Config `json:"transport" yaml:"transport"`
 }
 
 type Client struct {
@@ -98,11 +105,16 @@ type Client struct {
 }
 
 func NewClient(cfg *Config) (*Client, error) {
+	tr, err := transport.New(&cfg.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("transport.New: %w", err)
+	}
+
 	cli := resty.New()
+	cli.SetTransport(tr)
 	cli.SetBaseURL(cfg.ApiUrl)
 	cli.SetRetryCount(cfg.Retry)
 	cli.SetTimeout(cfg.Timeout)
-	cli.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
 	cli.SetDebug(cfg.Debug)
 	return &Client{
 		cfg: cfg,
@@ -148,7 +160,7 @@ func (c *Client) Get(ctx context.Context, req *GetReq) (*GetResp, error) {
 		return nil, fmt.Errorf("failed to request server: %v", err)
 	}
 	if res.StatusCode() == 404 {
-		return nil, fmt.Errorf("uuid %s not found", req.Uuid)
+		return nil, fmt.Errorf("uuid %s: %w", req.Uuid, ErrNotFound)
 	}
 	if res.StatusCode() != 200 {
 		return nil, fmt.Errorf("server return status %d body %+v", res.StatusCode(), resp)
@@ -181,7 +193,8 @@ func (c *Client) Push(ctx context.Context, req *PushReq) (*PushResp, error) {
 		return nil, fmt.Errorf("password is required")
 	}
 
-	data, err := json.Marshal(req)
+	// 只加密cookie数据,与浏览器插件推送的内容保持一致
+	data, err := json.Marshal(req.Cookie)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %v", err)
 	}
@@ -39,6 +39,7 @@ import (
 	"math/big"
 	"math/rand"
 	"strings"
+	"sync/atomic"
 )
 
 const (
@@ -107,6 +108,8 @@ func aesEncrypt(text, key, iv, mode, format string) (string, error) {
 		return hex.EncodeToString(cipherText), nil
 	case "HEX":
 		return strings.ToUpper(hex.EncodeToString(cipherText)), nil
+	case "":
+		return string(cipherText), nil
 	default:
 		return "", fmt.Errorf("%s unknown format", format)
 	}
@@ -219,6 +222,18 @@ func RsaEncrypt(ciphertext, key string) (string, error) {
 	return hex.EncodeToString(encryptedBytes), nil
 }
 
+// RsaDecrypt 私钥解密无填充方式,ciphertext为 RsaEncrypt 返回的hex字符串.
+func RsaDecrypt(ciphertext string, key *rsa.PrivateKey) (string, error) {
+	if key == nil {
+		return "", errors.New("private key is nil")
+	}
+	c, ok := new(big.Int).SetString(ciphertext, 16)
+	if !ok {
+		return "", errors.New("invalid hex ciphertext")
+	}
+	return string(c.Exp(c, key.D, key.N).Bytes()), nil
+}
+
 // Pkcs7Padding 补码,严格遵循 RFC 5652 规范.
 func Pkcs7Padding(data []byte, blockSize int) ([]byte, error) {
 	if blockSize <= 0 || blockSize > 255 {
@@ -272,7 +287,11 @@ func WeApiEncrypt(object interface{}) (map[string]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("aesEncrypt: %w", err)
 	}
-	encSecKey, err := RsaEncrypt(reverseString(secretKey), publicKey)
+	var key = publicKey
+	if v := weapiPublicKey.Load(); v != nil {
+		key = *v
+	}
+	encSecKey, err := RsaEncrypt(reverseString(secretKey), key)
 	if err != nil {
 		return nil, fmt.Errorf("RsaEncrypt: %w", err)
 	}
@@ -282,10 +301,36 @@ func WeApiEncrypt(object interface{}) (map[string]string, error) {
 	}, nil
 }
 
-// WeApiDecrypt 解密 TODO: 由于拿不到私钥则不能解密.
-func WeApiDecrypt(params, encSecKey string) (map[string]string, error) {
-	panic("unrealized")
-	return nil, nil
+// weapiPublicKey 替换后的weapi公钥,为nil时使用默认公钥,see: SetWeApiPublicKey
+var weapiPublicKey atomic.Pointer[string]
+
+// SetWeApiPublicKey 替换weapi加密随机密钥使用的rsa公钥(PEM格式),key为空时恢复默认公钥.
+// 用于本地模拟服务测试,模拟服务持有对应的私钥从而可以使用 WeApiDecrypt 解密请求参数.
+func SetWeApiPublicKey(key string) {
+	if key == "" {
+		weapiPublicKey.Store(nil)
+		return
+	}
+	weapiPublicKey.Store(&key)
+}
+
+// WeApiDecrypt 解密,返回加密前的json. key为加密时使用的公钥对应的私钥,
+// 由于拿不到网易云的私钥,只能解密使用 SetWeApiPublicKey 替换公钥后加密的内容
+func WeApiDecrypt(params, encSecKey string, key *rsa.PrivateKey) ([]byte, error) {
+	secretKey, err := RsaDecrypt(encSecKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("RsaDecrypt: %w", err)
+	}
+	secretKey = reverseString(secretKey)
+	encryptText, err := aesDecrypt(params, secretKey, iv, "cbc", "base64")
+	if err != nil {
+		return nil, fmt.Errorf("aesDecrypt: %w", err)
+	}
+	data, err := aesDecrypt(string(encryptText), presetKey, iv, "cbc", "base64")
+	if err != nil {
+		return nil, fmt.Errorf("aesDecrypt: %w", err)
+	}
+	return data, nil
 }
 
 // LinuxApiEncrypt 加密.
@@ -341,6 +386,15 @@ func EApiDecrypt(ciphertext, encode string) ([]byte, error) {
 	return plaintext, nil
 }
 
+// EApiEncryptResponse 加密eapi接口响应内容,与 EApiDecrypt(ciphertext, "") 相对应,一般用于模拟服务端.
+func EApiEncryptResponse(data []byte) ([]byte, error) {
+	ciphertext, err := aesEncrypt(string(data), eApiKey, "", "ecb", "")
+	if err != nil {
+		return nil, fmt.Errorf("aesEncrypt: %w", err)
+	}
+	return []byte(ciphertext), nil
+}
+
 // CacheKeyEncrypt 生成缓存 key.
 func CacheKeyEncrypt(data string) (string, error) {
 	block, err := aes.NewCipher([]byte(cacheKey))
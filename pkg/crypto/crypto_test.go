@@ -25,7 +25,13 @@ package crypto
 
 import (
 	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -97,6 +103,30 @@ func TestWeApiEncrypt(t *testing.T) {
 	}
 }
 
+func TestWeApiDecrypt(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+
+	// 使用默认公钥加密的内容无法解密
+	data, err := WeApiEncrypt(map[string]string{"phone": "188********"})
+	assert.NoError(t, err)
+	_, err = WeApiDecrypt(data["params"], data["encSecKey"], key)
+	assert.Error(t, err)
+
+	SetWeApiPublicKey(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	defer SetWeApiPublicKey("")
+	data, err = WeApiEncrypt(map[string]string{"phone": "188********", "ctcode": "86"})
+	assert.NoError(t, err)
+	got, err := WeApiDecrypt(data["params"], data["encSecKey"], key)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"phone":"188********","ctcode":"86"}`, string(got))
+
+	_, err = WeApiDecrypt(data["params"], "not hex", key)
+	assert.Error(t, err)
+}
+
 func TestEApiDecrypt(t *testing.T) {
 	type args struct {
 		encode string
@@ -152,6 +182,17 @@ func TestEApiDecrypt(t *testing.T) {
 	}
 }
 
+func TestEApiEncryptResponse(t *testing.T) {
+	var data = []byte(`{"code":200,"data":true}`)
+	ciphertext, err := EApiEncryptResponse(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "DCC52B3013E9B66C038F8E027E580ECEDF84E0F44CB93FC365BED7B646A9BC08", strings.ToUpper(hex.EncodeToString(ciphertext)))
+
+	plaintext, err := EApiDecrypt(string(ciphertext), "")
+	assert.NoError(t, err)
+	assert.Equal(t, data, plaintext)
+}
+
 func TestEApiEncrypt(t *testing.T) {
 	type args struct {
 		url    string
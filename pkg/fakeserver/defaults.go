@@ -0,0 +1,297 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package fakeserver
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+type object = map[string]interface{}
+
+// defaults 注册默认的接口处理函数,可通过 Handle 或 Reply 覆盖
+func (s *Server) defaults() {
+	s.AddSong(Song{Id: 1001, Name: "fakeserver song", Artist: "fakeserver", Album: "fakeserver", Data: []byte("fakeserver song 1001")})
+
+	// 批量请求
+	s.Handle("/api/batch", s.batch)
+
+	// 登录
+	s.Reply("/api/login/qrcode/unikey", object{"code": 200, "unikey": "fakeserver-unikey"})
+	s.Handle("/api/login/qrcode/client/login", func(req *Request) (interface{}, error) {
+		s.setLogin(req)
+		return object{"code": 803, "message": "授权登陆成功"}, nil
+	})
+	s.Handle("/api/w/login/cellphone", func(req *Request) (interface{}, error) {
+		s.setLogin(req)
+		return object{"code": 200, "loginType": 1, "account": s.account(), "profile": s.profile()}, nil
+	})
+	s.Reply("/api/sms/captcha/sent", object{"code": 200, "data": true})
+	s.Reply("/api/sms/captcha/verify", object{"code": 200, "data": true})
+	s.Reply("/api/login/token/refresh", object{"code": 200, "bizCode": "201"})
+	s.Handle("/api/logout", func(req *Request) (interface{}, error) {
+		req.SetCookie(&http.Cookie{Name: "MUSIC_U", Value: "", MaxAge: -1})
+		return object{"code": 200}, nil
+	})
+
+	// 用户信息
+	s.Handle("/api/w/nuser/account/get", func(req *Request) (interface{}, error) {
+		if !s.login(req) {
+			return object{"code": 200, "account": nil, "profile": nil}, nil
+		}
+		return object{"code": 200, "account": s.account(), "profile": s.profile()}, nil
+	})
+	s.Handle("/api/w/v1/user/detail/", func(req *Request) (interface{}, error) {
+		return object{
+			"code":      200,
+			"level":     10,
+			"userPoint": object{"userId": s.Account.UserId, "balance": 100},
+			"profile":   s.profile(),
+		}, nil
+	})
+
+	// 歌单以及歌曲
+	s.Handle("/api/v6/playlist/detail", func(req *Request) (interface{}, error) {
+		var (
+			songs    = s.Songs()
+			trackIds = make([]object, 0, len(songs))
+		)
+		for _, song := range songs {
+			trackIds = append(trackIds, object{"id": song.Id})
+		}
+		return object{
+			"code":       200,
+			"playlist":   object{"id": 1, "name": "fakeserver", "userId": s.Account.UserId, "trackCount": len(songs), "trackIds": trackIds, "tracks": s.tracks(songs)},
+			"privileges": s.privileges(songs),
+		}, nil
+	})
+	s.Handle("/api/v3/song/detail", func(req *Request) (interface{}, error) {
+		songs := s.findSongs(req.Param("c"))
+		return object{"code": 200, "songs": s.tracks(songs), "privileges": s.privileges(songs)}, nil
+	})
+	s.Handle("/api/song/music/detail/get", func(req *Request) (interface{}, error) {
+		var data = object{"songId": 0, "h": object{"br": 320000, "size": 0}}
+		if songs := s.Songs(); len(songs) > 0 {
+			data = object{"songId": songs[0].Id, "h": object{"br": 320000, "size": len(songs[0].Data)}}
+		}
+		return object{"code": 200, "data": data}, nil
+	})
+	s.Handle("/api/song/enhance/player/url/v1", s.songUrls)
+	s.Handle("/api/song/enhance/player/url", s.songUrls)
+	s.Handle("/api/song/enhance/download/url", func(req *Request) (interface{}, error) {
+		var data interface{}
+		if urls := s.urls(s.findSongs(req.Param("id"))); len(urls) > 0 {
+			data = urls[0]
+		}
+		return object{"code": 200, "data": data}, nil
+	})
+	s.Reply("/api/song/lyric/v1", object{"code": 200, "lrc": object{"version": 1, "lyric": "[00:00.00]fakeserver\n"}})
+	s.Reply("/api/toplist", object{"code": 200, "list": []object{{"id": 1, "name": "fakeserver"}}})
+	s.Reply("/api/feedback/weblog", object{"code": 200, "data": "success"})
+
+	// 云贝以及vip
+	s.Handle("/api/pointmall/user/sign", func(req *Request) (interface{}, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		sign := !s.signed
+		s.signed = true
+		return object{"code": 200, "data": object{"sign": sign}}, nil
+	})
+	s.Reply("/api/pointmall/user/sign/config", object{"code": 200, "data": object{"extraCount": 0, "lotteryConfig": []object{}}})
+	s.Reply("/api/pointmall/user/sign/lottery/get", object{"code": 200, "data": true})
+	s.Reply("/api/usertool/task/todo/query", object{"code": 200, "data": []object{}})
+	s.Reply("/api/usertool/task/point/receive", object{"code": 200, "data": true})
+	s.Reply("/api/vip-center-bff/task/sign", object{"code": 200, "data": true})
+	s.Reply("/api/vipnewcenter/app/level/growhpoint/basic", object{"code": 200, "data": object{}})
+	s.Reply("/api/vipnewcenter/app/level/task/reward/getall", object{"code": 200, "data": object{}})
+
+	// 音乐合伙人
+	s.Handle("/api/music/partner/user/info/get", func(req *Request) (interface{}, error) {
+		return object{"code": 200, "data": object{"userId": s.Account.UserId, "nickName": s.Account.Nickname, "title": "JUNIOR", "status": "NORMAL"}}, nil
+	})
+	s.Reply("/api/music/partner/daily/task/get", object{"code": 200, "data": object{"id": 1, "count": 0, "completedCount": 0, "works": []object{}}})
+	s.Reply("/api/music/partner/extra/wait/evaluate/work/list", object{"code": 200, "data": []object{}})
+	s.Reply("/api/music/partner/work/evaluate", object{"code": 200, "data": true})
+	s.Reply("/api/partner/resource/interact/report", object{"code": 200, "data": true})
+
+	// 云盘上传
+	s.Reply("/api/cloud/upload/check", object{"code": 200, "songId": "2001", "needUpload": true})
+	s.Reply("/api/nos/token/alloc", object{"code": 200, "result": object{
+		"bucket":     "fakeserver",
+		"token":      "UPLOAD fakeserver",
+		"objectKey":  "obj/fakeserver/2001.mp3",
+		"docId":      "-1",
+		"resourceId": 2001,
+	}})
+	s.Reply("/api/upload/cloud/info/v2", object{"code": 200, "songId": "2001", "songIdLong": 2001, "exists": true})
+	s.Reply("/api/v1/cloud/music/status", object{"code": 200, "statuses": object{"2001": object{"status": 0}}})
+	s.Reply("/api/cloud/pub/v2", object{"code": 200, "privateCloud": object{"songId": 2001}})
+}
+
+// setLogin 登录成功后设置cookie
+func (s *Server) setLogin(req *Request) {
+	var expires = time.Now().AddDate(0, 0, 30)
+	req.SetCookie(&http.Cookie{Name: "MUSIC_U", Value: s.Account.Token, Expires: expires, HttpOnly: true})
+	req.SetCookie(&http.Cookie{Name: "__csrf", Value: "fakeserver-csrf", Expires: expires})
+}
+
+// login 请求是否携带了登录cookie
+func (s *Server) login(req *Request) bool {
+	token, ok := req.Cookie("MUSIC_U")
+	return ok && token == s.Account.Token
+}
+
+func (s *Server) account() object {
+	return object{"id": s.Account.UserId, "userName": "fakeserver", "vipType": s.Account.VipType}
+}
+
+func (s *Server) profile() object {
+	return object{"userId": s.Account.UserId, "nickname": s.Account.Nickname, "vipType": s.Account.VipType}
+}
+
+// findSongs 按照请求参数中的歌曲id过滤歌曲,ids格式为: 1001 或 [1001,"1002"] 或 [{"id":"1001"}],参数为空时返回全部歌曲
+func (s *Server) findSongs(ids string) []Song {
+	var (
+		list []interface{}
+		want = make(map[string]bool)
+	)
+	decoder := json.NewDecoder(strings.NewReader(ids))
+	decoder.UseNumber()
+	if err := decoder.Decode(&list); err != nil {
+		list = []interface{}{ids}
+	}
+	for _, v := range list {
+		if m, ok := v.(map[string]interface{}); ok {
+			v = m["id"]
+		}
+		if id := strings.SplitN(fmt.Sprintf("%v", v), "_", 2)[0]; id != "" {
+			want[id] = true
+		}
+	}
+	var songs = s.Songs()
+	if ids == "" {
+		return songs
+	}
+	return slices.DeleteFunc(songs, func(song Song) bool { return !want[fmt.Sprintf("%d", song.Id)] })
+}
+
+func (s *Server) tracks(songs []Song) []object {
+	var list []object
+	for _, song := range songs {
+		list = append(list, object{
+			"id":   song.Id,
+			"name": song.Name,
+			"ar":   []object{{"id": song.Id, "name": song.Artist}},
+			"al":   object{"id": song.Id, "name": song.Album},
+			"dt":   180000,
+		})
+	}
+	return list
+}
+
+func (s *Server) privileges(songs []Song) []object {
+	var list []object
+	for _, song := range songs {
+		list = append(list, object{"id": song.Id, "st": 0, "pl": 320000, "dl": 320000, "maxbr": 320000})
+	}
+	return list
+}
+
+// urls 歌曲下载地址
+func (s *Server) urls(songs []Song) []object {
+	var list []object
+	for _, song := range songs {
+		sum := md5.Sum(song.Data)
+		list = append(list, object{
+			"id":         song.Id,
+			"url":        fmt.Sprintf("%s/fakeserver/song/%d.mp3", strings.TrimSuffix(s.URL, "/"), song.Id),
+			"br":         320000,
+			"size":       len(song.Data),
+			"md5":        hex.EncodeToString(sum[:]),
+			"code":       200,
+			"type":       "mp3",
+			"level":      "exhigh",
+			"encodeType": "mp3",
+			"time":       180000,
+		})
+	}
+	return list
+}
+
+// batch 按照参数中的接口路径依次调用对应的处理函数,返回内容以接口路径为key合并
+func (s *Server) batch(req *Request) (interface{}, error) {
+	var reply = object{"code": 200}
+	for endpoint := range req.Params {
+		if !strings.HasPrefix(endpoint, "/api/") {
+			continue
+		}
+		var sub = Request{
+			Mode:     req.Mode,
+			Host:     req.Host,
+			Endpoint: endpoint,
+			Header:   req.Header,
+			Cookies:  req.Cookies,
+		}
+		if data := req.Param(endpoint); data != "" {
+			decoder := json.NewDecoder(strings.NewReader(data))
+			decoder.UseNumber()
+			if err := decoder.Decode(&sub.Params); err != nil {
+				return nil, fmt.Errorf("%s: json.Decode: %w", endpoint, err)
+			}
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, sub)
+		h := s.handler(endpoint)
+		s.mu.Unlock()
+
+		if h == nil {
+			reply[endpoint] = object{"code": 404, "message": "fakeserver: " + endpoint + " not found"}
+			continue
+		}
+		result, err := h(&sub)
+		if err != nil {
+			reply[endpoint] = object{"code": 500, "message": "fakeserver: " + err.Error()}
+			continue
+		}
+		switch v := result.(type) {
+		case []byte:
+			reply[endpoint] = json.RawMessage(v)
+		default:
+			reply[endpoint] = v
+		}
+		req.setCookies = append(req.setCookies, sub.setCookies...)
+	}
+	return reply, nil
+}
+
+func (s *Server) songUrls(req *Request) (interface{}, error) {
+	return object{"code": 200, "data": s.urls(s.findSongs(req.Param("ids")))}, nil
+}
@@ -0,0 +1,494 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+// Package fakeserver 本地模拟网易云音乐服务,用于在不访问真实账号的情况下端到端测试ncmctl命令.
+//
+// 支持weapi、eapi、linux以及api四种协议,请求会统一转换为/api开头的接口路径(Request.Endpoint)后查找处理函数.
+// 客户端通过 api.Config.BaseURL 指向 Server.URL 即可将所有请求转发到本服务,包括NOS上传以及歌曲下载,
+// 原始域名通过X-Forwarded-Host请求头获取.
+// 注意: weapi使用rsa加密随机密钥,由于没有网易云的私钥,模拟服务运行期间会通过 crypto.SetWeApiPublicKey
+// 将当前进程的weapi公钥替换为模拟服务生成的公钥,所有模拟服务关闭后恢复默认公钥.
+package fakeserver
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/pkg/crypto"
+)
+
+// Mode 请求使用的加密协议
+type Mode string
+
+const (
+	ModeWEAPI Mode = "weapi"
+	ModeEAPI  Mode = "eapi"
+	ModeLinux Mode = "linux"
+	ModeAPI   Mode = "api"
+)
+
+// eapiSeparator eapi加密前参数的分隔符,格式为: url-36cd479b6b5-json-36cd479b6b5-digest
+const eapiSeparator = "-36cd479b6b5-"
+
+// Request 解析后的接口请求
+type Request struct {
+	Mode Mode
+	// Host 客户端请求的原始域名,例如: music.163.com 优先使用X-Forwarded-Host请求头
+	Host string
+	// Endpoint 统一为/api开头的接口路径,例如: /api/w/nuser/account/get
+	Endpoint string
+	// Params 解密后的请求参数
+	Params  map[string]interface{}
+	Header  http.Header
+	Cookies []*http.Cookie
+
+	encrypt    bool
+	setCookies []*http.Cookie
+}
+
+// Param 获取字符串形式的请求参数
+func (r *Request) Param(key string) string {
+	switch v := r.Params[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// Cookie 获取请求携带的cookie
+func (r *Request) Cookie(name string) (string, bool) {
+	for _, c := range r.Cookies {
+		if c.Name == name {
+			return c.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetCookie 响应时设置cookie,域名为music.163.com时cookie作用于.music.163.com
+func (r *Request) SetCookie(c *http.Cookie) {
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if c.Domain == "" && strings.HasSuffix(r.Host, "music.163.com") {
+		c.Domain = "music.163.com"
+	}
+	r.setCookies = append(r.setCookies, c)
+}
+
+// Handler 接口处理函数,返回值会被序列化为json,类型为[]byte或json.RawMessage时原样返回
+type Handler func(req *Request) (interface{}, error)
+
+// Song 模拟的歌曲,下载地址为 Server.URL/fakeserver/song/{id}.mp3
+type Song struct {
+	Id     int64
+	Name   string
+	Artist string
+	Album  string
+	Data   []byte
+}
+
+// Account 模拟的登录账号
+type Account struct {
+	UserId   int64
+	Nickname string
+	VipType  int64
+	// Token 登录成功后设置的MUSIC_U
+	Token string
+}
+
+// Server 模拟服务
+type Server struct {
+	URL     string
+	Account Account
+
+	srv      *httptest.Server
+	key      *rsa.PrivateKey // weapi私钥
+	closed   sync.Once
+	mu       sync.Mutex
+	handlers map[string]Handler
+	songs    []Song
+	uploads  map[string][]byte
+	requests []Request
+	signed   bool
+}
+
+// New 创建并启动模拟服务,默认注册登录、用户信息、歌单、歌曲地址、云盘上传以及云贝等接口.
+// 使用完毕后需要调用 Close 关闭.
+func New() *Server {
+	s := Server{
+		Account: Account{
+			UserId:   10000,
+			Nickname: "fakeserver",
+			VipType:  11,
+			Token:    "fakeserver-music-u",
+		},
+		handlers: make(map[string]Handler),
+		uploads:  make(map[string][]byte),
+	}
+	s.key = acquireWeApiKey()
+	s.srv = httptest.NewServer(&s)
+	s.URL = s.srv.URL
+	s.defaults()
+	return &s
+}
+
+// Close 关闭模拟服务
+func (s *Server) Close() {
+	s.closed.Do(func() {
+		s.srv.Close()
+		releaseWeApiKey()
+	})
+}
+
+// weapiKey 模拟服务解密weapi请求使用的rsa密钥,同一进程中的模拟服务共用
+var weapiKey struct {
+	sync.Mutex
+	key  *rsa.PrivateKey
+	pem  string
+	refs int
+}
+
+// acquireWeApiKey 返回weapi私钥,第一个模拟服务启动时替换weapi公钥
+func acquireWeApiKey() *rsa.PrivateKey {
+	weapiKey.Lock()
+	defer weapiKey.Unlock()
+	if weapiKey.key == nil {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			panic(fmt.Sprintf("fakeserver: rsa.GenerateKey: %s", err))
+		}
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			panic(fmt.Sprintf("fakeserver: MarshalPKIXPublicKey: %s", err))
+		}
+		weapiKey.key = key
+		weapiKey.pem = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+	if weapiKey.refs == 0 {
+		crypto.SetWeApiPublicKey(weapiKey.pem)
+	}
+	weapiKey.refs++
+	return weapiKey.key
+}
+
+// releaseWeApiKey 最后一个模拟服务关闭时恢复默认的weapi公钥
+func releaseWeApiKey() {
+	weapiKey.Lock()
+	defer weapiKey.Unlock()
+	if weapiKey.refs--; weapiKey.refs == 0 {
+		crypto.SetWeApiPublicKey("")
+	}
+}
+
+// Handle 注册接口处理函数,endpoint以/结尾时按照前缀匹配,例如: /api/v1/album/
+func (s *Server) Handle(endpoint string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[endpoint] = h
+}
+
+// Reply 注册固定返回内容的接口
+func (s *Server) Reply(endpoint string, body interface{}) {
+	s.Handle(endpoint, func(*Request) (interface{}, error) { return body, nil })
+}
+
+// LoadFixtures 从json文件中加载固定返回内容,文件格式为: {"/api/xxx": {"code":200}}
+func (s *Server) LoadFixtures(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ReadFile: %w", err)
+	}
+	var fixtures map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	for endpoint, body := range fixtures {
+		s.Reply(endpoint, body)
+	}
+	return nil
+}
+
+// AddSong 添加歌曲,歌单、歌曲详情以及歌曲地址等接口会返回已添加的歌曲,id相同时替换
+func (s *Server) AddSong(song Song) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.songs {
+		if s.songs[i].Id == song.Id {
+			s.songs[i] = song
+			return
+		}
+	}
+	s.songs = append(s.songs, song)
+}
+
+// Songs 返回已添加的歌曲
+func (s *Server) Songs() []Song {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Song(nil), s.songs...)
+}
+
+// Upload 返回通过NOS上传的文件内容,key为 bucket/objectKey
+func (s *Server) Upload(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.uploads[key]
+	return data, ok
+}
+
+// Requests 返回已收到的接口请求,endpoint为空时返回全部
+func (s *Server) Requests(endpoint string) []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []Request
+	for _, r := range s.requests {
+		if endpoint == "" || r.Endpoint == endpoint {
+			list = append(list, r)
+		}
+	}
+	return list
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/lbs":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"lbs": s.URL, "upload": []string{s.URL}})
+	case strings.HasPrefix(r.URL.Path, "/fakeserver/song/"):
+		s.serveSong(w, r)
+	case r.Header.Get("X-Nos-Token") != "":
+		s.serveUpload(w, r)
+	case strings.HasPrefix(r.URL.Path, "/weapi/"), strings.HasPrefix(r.URL.Path, "/eapi/"),
+		strings.HasPrefix(r.URL.Path, "/api/"):
+		s.serveApi(w, r)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"code": 404, "message": "fakeserver: " + r.URL.Path + " not found"})
+	}
+}
+
+func (s *Server) serveApi(w http.ResponseWriter, r *http.Request) {
+	req, err := s.parse(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"code": 400, "message": "fakeserver: " + err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, *req)
+	h := s.handler(req.Endpoint)
+	s.mu.Unlock()
+
+	if h == nil {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"code": 404, "message": "fakeserver: " + req.Endpoint + " not found"})
+		return
+	}
+	reply, err := h(req)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"code": 500, "message": "fakeserver: " + err.Error()})
+		return
+	}
+
+	var body []byte
+	switch v := reply.(type) {
+	case []byte:
+		body = v
+	case json.RawMessage:
+		body = v
+	default:
+		if body, err = json.Marshal(v); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"code": 500, "message": "fakeserver: " + err.Error()})
+			return
+		}
+	}
+	for _, c := range req.setCookies {
+		http.SetCookie(w, c)
+	}
+	if req.encrypt {
+		if body, err = crypto.EApiEncryptResponse(body); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]interface{}{"code": 500, "message": "fakeserver: " + err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+	} else {
+		w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// handler 查找接口处理函数,优先精确匹配,其次按照最长前缀匹配
+func (s *Server) handler(endpoint string) Handler {
+	if h, ok := s.handlers[endpoint]; ok {
+		return h
+	}
+	var (
+		h      Handler
+		prefix string
+	)
+	for k, v := range s.handlers {
+		if strings.HasSuffix(k, "/") && strings.HasPrefix(endpoint, k) && len(k) > len(prefix) {
+			h, prefix = v, k
+		}
+	}
+	return h
+}
+
+// parse 按照请求路径识别加密协议并解密请求参数
+func (s *Server) parse(r *http.Request) (*Request, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("ParseForm: %w", err)
+	}
+	var req = Request{
+		Host:    r.Host,
+		Header:  r.Header,
+		Cookies: r.Cookies(),
+	}
+	if host := r.Header.Get("X-Forwarded-Host"); host != "" {
+		req.Host = host
+	}
+
+	switch path := r.URL.Path; {
+	case path == "/api/linux/forward":
+		req.Mode = ModeLinux
+		plaintext, err := crypto.LinuxApiDecrypt(r.Form.Get("eparams"))
+		if err != nil {
+			return nil, fmt.Errorf("LinuxApiDecrypt: %w", err)
+		}
+		var forward struct {
+			Url    string                 `json:"url"`
+			Params map[string]interface{} `json:"params"`
+		}
+		if err := json.Unmarshal(plaintext, &forward); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+		uri, err := neturl.Parse(forward.Url)
+		if err != nil {
+			return nil, fmt.Errorf("url.Parse: %w", err)
+		}
+		req.Endpoint, req.Params = uri.Path, forward.Params
+	case strings.HasPrefix(path, "/weapi/"):
+		req.Mode = ModeWEAPI
+		req.Endpoint = "/api/" + strings.TrimPrefix(path, "/weapi/")
+		plaintext, err := crypto.WeApiDecrypt(r.Form.Get("params"), r.Form.Get("encSecKey"), s.key)
+		if err != nil {
+			return nil, fmt.Errorf("WeApiDecrypt: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(plaintext))
+		decoder.UseNumber()
+		if err := decoder.Decode(&req.Params); err != nil {
+			return nil, fmt.Errorf("json.Decode: %w", err)
+		}
+	case strings.HasPrefix(path, "/eapi/"):
+		req.Mode = ModeEAPI
+		plaintext, err := crypto.EApiDecrypt(r.Form.Get("params"), "hex")
+		if err != nil {
+			return nil, fmt.Errorf("EApiDecrypt: %w", err)
+		}
+		var (
+			text  = string(plaintext)
+			start = strings.Index(text, eapiSeparator)
+			end   = strings.LastIndex(text, eapiSeparator)
+		)
+		if start < 0 || start == end {
+			return nil, errors.New("eapi params format invalid")
+		}
+		req.Endpoint = text[:start]
+		decoder := json.NewDecoder(strings.NewReader(text[start+len(eapiSeparator) : end]))
+		decoder.UseNumber()
+		if err := decoder.Decode(&req.Params); err != nil {
+			return nil, fmt.Errorf("json.Decode: %w", err)
+		}
+		req.encrypt = req.Param("e_r") == "true"
+	default:
+		req.Mode = ModeAPI
+		req.Endpoint = path
+		req.Params = make(map[string]interface{}, len(r.Form))
+		for k := range r.Form {
+			req.Params[k] = r.Form.Get(k)
+		}
+	}
+	return &req, nil
+}
+
+func (s *Server) serveSong(w http.ResponseWriter, r *http.Request) {
+	var name = strings.TrimPrefix(r.URL.Path, "/fakeserver/song/")
+	for _, song := range s.Songs() {
+		if fmt.Sprintf("%d.mp3", song.Id) == name {
+			http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(song.Data))
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// serveUpload 模拟NOS分片上传,complete=true时视为上传完成
+func (s *Server) serveUpload(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"errCode": "400", "errMsg": err.Error()})
+		return
+	}
+	var key = strings.TrimPrefix(r.URL.Path, "/")
+	if unescaped, err := neturl.PathUnescape(key); err == nil {
+		key = unescaped
+	}
+
+	s.mu.Lock()
+	if r.URL.Query().Get("offset") == "0" {
+		s.uploads[key] = nil
+	}
+	s.uploads[key] = append(s.uploads[key], data...)
+	offset := len(s.uploads[key])
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"requestId":      "fakeserver",
+		"offset":         offset,
+		"context":        "fakeserver-context",
+		"callbackRetMsg": `{"code":200}`,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
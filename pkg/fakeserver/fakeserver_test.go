@@ -0,0 +1,251 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package fakeserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaunsin/netease-cloud-music/api"
+	"github.com/chaunsin/netease-cloud-music/api/types"
+	"github.com/chaunsin/netease-cloud-music/api/weapi"
+	"github.com/chaunsin/netease-cloud-music/pkg/cookie"
+	"github.com/chaunsin/netease-cloud-music/pkg/crypto"
+	"github.com/chaunsin/netease-cloud-music/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(t *testing.M) {
+	log.Default = log.New(&log.Config{
+		Level:  "debug",
+		Stdout: true,
+	})
+	os.Exit(t.Run())
+}
+
+func newClient(t *testing.T, s *Server) *api.Client {
+	cli, err := api.NewClient(&api.Config{
+		Timeout: 10 * time.Second,
+		Cookie:  cookie.Config{Filepath: filepath.Join(t.TempDir(), "cookie.json")},
+		BaseURL: s.URL,
+	}, log.Default)
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = cli.Close(context.TODO()) })
+	return cli
+}
+
+func TestLogin(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	var (
+		ctx = context.TODO()
+		cli = weapi.New(newClient(t, s))
+	)
+	assert.True(t, cli.NeedLogin(ctx))
+
+	key, err := cli.QrcodeCreateKey(ctx, &weapi.QrcodeCreateKeyReq{})
+	assert.NoError(t, err)
+	assert.Equal(t, "fakeserver-unikey", key.UniKey)
+
+	check, err := cli.QrcodeCheck(ctx, &weapi.QrcodeCheckReq{Key: key.UniKey})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(803), check.Code)
+	assert.False(t, cli.NeedLogin(ctx))
+
+	info, err := cli.GetUserInfo(ctx, &weapi.GetUserInfoReq{})
+	assert.NoError(t, err)
+	assert.Equal(t, s.Account.UserId, info.Profile.UserId)
+	assert.Len(t, s.Requests("/api/w/nuser/account/get"), 2)
+	assert.Equal(t, ModeWEAPI, s.Requests("/api/w/nuser/account/get")[0].Mode)
+}
+
+func TestEApi(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	var (
+		ctx    = context.TODO()
+		client = newClient(t, s)
+		cli    = weapi.New(client)
+	)
+	reply, err := cli.LoginCellphone(ctx, &weapi.LoginCellphoneReq{Phone: "18800000000", Countrycode: 86, Password: "fake"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), reply.Code)
+	assert.Equal(t, s.Account.UserId, reply.Profile.UserId)
+
+	// eapi请求参数可以解密
+	requests := s.Requests("/api/w/login/cellphone")
+	assert.Len(t, requests, 1)
+	assert.Equal(t, ModeEAPI, requests[0].Mode)
+	assert.Equal(t, "18800000000", requests[0].Param("phone"))
+	assert.False(t, cli.NeedLogin(ctx))
+
+	// 要求响应加密
+	var (
+		echo types.RespCommon[string]
+		opts = api.NewOptions()
+	)
+	opts.CryptoMode = api.CryptoModeEAPI
+	opts.EncryptResponse = true
+	s.Reply("/api/fakeserver/echo", map[string]interface{}{"code": 200, "data": "echo"})
+	_, err = client.Request(ctx, "https://interface.music.163.com/eapi/fakeserver/echo", map[string]interface{}{"id": 1}, &echo, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "echo", echo.Data)
+	requests = s.Requests("/api/fakeserver/echo")
+	assert.Len(t, requests, 1)
+	assert.True(t, requests[0].encrypt)
+	assert.Equal(t, "1", requests[0].Param("id"))
+}
+
+func TestSign(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	var (
+		ctx = context.TODO()
+		cli = weapi.New(newClient(t, s))
+	)
+	reply, err := cli.YunBeiSignIn(ctx, &weapi.YunBeiSignInReq{})
+	assert.NoError(t, err)
+	assert.True(t, reply.Data.Sign)
+	reply, err = cli.YunBeiSignIn(ctx, &weapi.YunBeiSignInReq{})
+	assert.NoError(t, err)
+	assert.False(t, reply.Data.Sign)
+
+	todo, err := cli.YunBeiTaskTodo(ctx, &weapi.YunBeiTaskTodoReq{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(200), todo.Code)
+}
+
+func TestDownload(t *testing.T) {
+	s := New()
+	defer s.Close()
+	s.AddSong(Song{Id: 1002, Name: "second", Artist: "fakeserver", Album: "fakeserver", Data: []byte("fakeserver song 1002")})
+
+	var (
+		ctx    = context.TODO()
+		client = newClient(t, s)
+		cli    = weapi.New(client)
+	)
+	playlist, err := cli.PlaylistDetail(ctx, &weapi.PlaylistDetailReq{Id: "1"})
+	assert.NoError(t, err)
+	assert.Len(t, playlist.Playlist.TrackIds, 2)
+
+	urls, err := cli.SongPlayerV1(ctx, &weapi.SongPlayerV1Req{Ids: types.IntsString{1001, 1002}, Level: types.LevelExhigh})
+	assert.NoError(t, err)
+	assert.Len(t, urls.Data, 2)
+	if reqs := s.Requests("/api/song/enhance/player/url/v1"); assert.Len(t, reqs, 1) {
+		assert.Equal(t, "[1001,1002]", reqs[0].Param("ids"))
+	}
+
+	one, err := cli.SongPlayerV1(ctx, &weapi.SongPlayerV1Req{Ids: types.IntsString{1002}, Level: types.LevelExhigh})
+	assert.NoError(t, err)
+	if assert.Len(t, one.Data, 1) {
+		assert.EqualValues(t, 1002, one.Data[0].Id)
+	}
+
+	var (
+		drd  = urls.Data[1]
+		dest = filepath.Join(t.TempDir(), "1002.mp3")
+	)
+	_, err = client.DownloadFile(ctx, &api.DownloadFileReq{Url: drd.Url, Filename: dest, Md5: drd.Md5, Size: drd.Size}, nil)
+	assert.NoError(t, err)
+	data, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, "fakeserver song 1002", string(data))
+}
+
+func TestCloudUpload(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	var (
+		ctx  = context.TODO()
+		cli  = weapi.New(newClient(t, s))
+		file = filepath.Join(t.TempDir(), "song.mp3")
+	)
+	assert.NoError(t, os.WriteFile(file, []byte("fakeserver upload"), 0644))
+
+	alloc, err := cli.CloudTokenAlloc(ctx, &weapi.CloudTokenAllocReq{})
+	assert.NoError(t, err)
+	reply, err := cli.CloudUpload(ctx, &weapi.CloudUploadReq{
+		Bucket:    alloc.Bucket,
+		ObjectKey: alloc.ObjectKey,
+		Token:     alloc.Token,
+		Filepath:  file,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, reply.ErrCode)
+
+	data, ok := s.Upload(alloc.Bucket + "/" + alloc.ObjectKey)
+	assert.True(t, ok)
+	assert.Equal(t, "fakeserver upload", string(data))
+}
+
+func TestNotFound(t *testing.T) {
+	s := New()
+	defer s.Close()
+	s.Reply("/api/v1/album/", map[string]interface{}{"code": 200, "songs": []interface{}{}})
+
+	var (
+		ctx = context.TODO()
+		cli = weapi.New(newClient(t, s))
+	)
+	_, err := cli.Album(ctx, &weapi.AlbumReq{Id: "1"})
+	assert.NoError(t, err)
+
+	_, err = cli.Layout(ctx, &weapi.LayoutReq{})
+	assert.NoError(t, err)
+	_, err = cli.ArtistSongs(ctx, &weapi.ArtistSongsReq{Id: 1})
+	assert.Error(t, err)
+}
+
+func TestWeApiKey(t *testing.T) {
+	var (
+		ctx = context.TODO()
+		s1  = New()
+		s2  = New()
+		cli = weapi.New(newClient(t, s2))
+	)
+	// 其他模拟服务关闭后仍然可以解密
+	s1.Close()
+	s1.Close()
+	_, err := cli.QrcodeCreateKey(ctx, &weapi.QrcodeCreateKeyReq{Type: 1})
+	assert.NoError(t, err)
+	if reqs := s2.Requests("/api/login/qrcode/unikey"); assert.Len(t, reqs, 1) {
+		assert.Equal(t, "1", reqs[0].Param("type"))
+	}
+
+	// 全部关闭后恢复默认公钥
+	s2.Close()
+	data, err := crypto.WeApiEncrypt(map[string]string{"type": "1"})
+	assert.NoError(t, err)
+	_, err = crypto.WeApiDecrypt(data["params"], data["encSecKey"], s2.key)
+	assert.Error(t, err)
+}
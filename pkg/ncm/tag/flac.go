@@ -28,6 +28,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/go-flac/flacpicture/v2"
 	"github.com/go-flac/flacvorbis/v2"
@@ -143,6 +145,60 @@ func (f *Flac) SetLyrics(lyrics string) error {
 	return f.addTag("LYRICS", lyrics)
 }
 
+// SetSyncedLyrics 以LRC格式写入LYRICS字段,大多数播放器可以识别其中的时间标签
+func (f *Flac) SetSyncedLyrics(lines []LyricLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		var (
+			ms      = line.Time.Milliseconds()
+			minutes = ms / 60000
+			seconds = ms / 1000 % 60
+		)
+		b.WriteString(fmt.Sprintf("[%02d:%02d.%02d]%s\n", minutes, seconds, ms%1000/10, line.Text))
+	}
+	return f.addTag("LYRICS", strings.TrimSuffix(b.String(), "\n"))
+}
+
+func (f *Flac) SetAlbumArtist(artists []string) error {
+	return f.addTag("ALBUMARTIST", artists...)
+}
+
+func (f *Flac) SetTrackNumber(number, total int) error {
+	if number <= 0 {
+		return nil
+	}
+	if err := f.addTag(flacvorbis.FIELD_TRACKNUMBER, strconv.Itoa(number)); err != nil {
+		return err
+	}
+	if total > 0 {
+		return f.addTag("TRACKTOTAL", strconv.Itoa(total))
+	}
+	return nil
+}
+
+func (f *Flac) SetDiscNumber(number, total int) error {
+	if number <= 0 {
+		return nil
+	}
+	if err := f.addTag("DISCNUMBER", strconv.Itoa(number)); err != nil {
+		return err
+	}
+	if total > 0 {
+		return f.addTag("DISCTOTAL", strconv.Itoa(total))
+	}
+	return nil
+}
+
+func (f *Flac) SetYear(year int) error {
+	if year <= 0 {
+		return nil
+	}
+	return f.addTag(flacvorbis.FIELD_DATE, strconv.Itoa(year))
+}
+
 func (f *Flac) setVorbisCommentMeta(block *flac.MetaDataBlock) {
 	var idx = -1
 	for i, m := range f.flac.Meta {
@@ -0,0 +1,90 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package tag
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lrcTimeReg 匹配LRC时间标签,eg: [00:12.34] [01:02.345] [01:02]
+var lrcTimeReg = regexp.MustCompile(`\[(\d+):(\d{1,2})(?:[.:](\d{1,3}))?]`)
+
+// LyricLine 一行带时间的歌词
+type LyricLine struct {
+	Time time.Duration
+	Text string
+}
+
+// ParseLRC 解析LRC格式歌词,一行中包含多个时间标签时会拆分成多行,结果按时间排序。
+// 没有时间标签的行(比如[ar:xxx]等元信息或者网易云歌词中的json贡献者信息)会被忽略
+func ParseLRC(lrc string) []LyricLine {
+	var list = make([]LyricLine, 0)
+	for _, line := range strings.Split(strings.ReplaceAll(lrc, "\r\n", "\n"), "\n") {
+		matches := lrcTimeReg.FindAllStringSubmatchIndex(line, -1)
+		if len(matches) == 0 || matches[0][0] != 0 {
+			continue
+		}
+		var (
+			end  = matches[len(matches)-1][1]
+			text = strings.TrimSpace(line[end:])
+		)
+		for _, m := range matches {
+			var (
+				minute, _ = strconv.Atoi(line[m[2]:m[3]])
+				second, _ = strconv.Atoi(line[m[4]:m[5]])
+				ms        int
+			)
+			if m[6] >= 0 {
+				frac := line[m[6]:m[7]]
+				ms, _ = strconv.Atoi(frac)
+				// 小数部分可能是1~3位
+				for i := len(frac); i < 3; i++ {
+					ms *= 10
+				}
+			}
+			list = append(list, LyricLine{
+				Time: time.Duration(minute)*time.Minute + time.Duration(second)*time.Second + time.Duration(ms)*time.Millisecond,
+				Text: text,
+			})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Time < list[j].Time })
+	return list
+}
+
+// PlainLyrics 将LRC格式歌词转换成不带时间标签的纯文本歌词
+func PlainLyrics(lrc string) string {
+	var (
+		lines = ParseLRC(lrc)
+		text  = make([]string, 0, len(lines))
+	)
+	for _, line := range lines {
+		text = append(text, line.Text)
+	}
+	return strings.TrimSpace(strings.Join(text, "\n"))
+}
@@ -0,0 +1,91 @@
+// MIT License
+//
+// Copyright (c) 2025 chaunsin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+
+package tag
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bogem/id3v2/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const testLRC = `{"t":0,"c":[{"tx":"作曲: "},{"tx":"周杰伦"}]}
+[ar:周杰伦]
+[00:01.5]第一句
+[00:10.12][01:10.12]副歌
+[00:05.123] 第二句 `
+
+func TestParseLRC(t *testing.T) {
+	got := ParseLRC(testLRC)
+	assert.Equal(t, []LyricLine{
+		{Time: 1500 * time.Millisecond, Text: "第一句"},
+		{Time: 5123 * time.Millisecond, Text: "第二句"},
+		{Time: 10120 * time.Millisecond, Text: "副歌"},
+		{Time: 70120 * time.Millisecond, Text: "副歌"},
+	}, got)
+	assert.Empty(t, ParseLRC("纯音乐,请欣赏"))
+}
+
+func TestPlainLyrics(t *testing.T) {
+	assert.Equal(t, "第一句\n第二句\n副歌\n副歌", PlainLyrics(testLRC))
+}
+
+func TestSetSongMp3(t *testing.T) {
+	var filename = filepath.Join(t.TempDir(), "song.mp3")
+	assert.NoError(t, os.WriteFile(filename, bytes.Repeat([]byte{0xFF, 0xFB, 0x90, 0x00}, 64), 0644))
+
+	m, err := NewMp3(filename)
+	if err != nil {
+		t.Fatalf("NewMp3() error = %v", err)
+	}
+	err = SetSong(m, &Song{
+		Title:        "标题",
+		Album:        "专辑",
+		Artists:      []string{"歌手1", "歌手2"},
+		AlbumArtists: []string{"歌手1"},
+		TrackNumber:  3,
+		TrackTotal:   10,
+		DiscNumber:   1,
+		Year:         2003,
+		Lyrics:       testLRC,
+	})
+	assert.NoError(t, err)
+
+	tag, err := id3v2.Open(filename, id3v2.Options{Parse: true})
+	assert.NoError(t, err)
+	defer tag.Close()
+	assert.Equal(t, "标题", tag.Title())
+	assert.Equal(t, "2003", tag.Year())
+	assert.Equal(t, "歌手1", tag.GetTextFrame("TPE2").Text)
+	assert.Equal(t, "3/10", tag.GetTextFrame("TRCK").Text)
+	assert.Equal(t, "1", tag.GetTextFrame("TPOS").Text)
+	assert.Len(t, tag.GetFrames("SYLT"), 1)
+	uslt, ok := tag.GetLastFrame("USLT").(id3v2.UnsynchronisedLyricsFrame)
+	assert.True(t, ok)
+	assert.Equal(t, "第一句\n第二句\n副歌\n副歌", uslt.Lyrics)
+}
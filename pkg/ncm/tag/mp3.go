@@ -24,7 +24,12 @@
 package tag
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
 
 	"github.com/bogem/id3v2/v2"
 )
@@ -120,6 +125,85 @@ func (m *Mp3) SetLyrics(lyrics string) error {
 
 }
 
+// SetSyncedLyrics 写入SYLT同步歌词帧,id3v2库不支持SYLT因此需要自行构造帧内容。
+// see: https://id3.org/id3v2.3.0#Synchronised_lyrics.2Ftext
+func (m *Mp3) SetSyncedLyrics(lines []LyricLine) error {
+	if frames := m.tag.GetFrames("SYLT"); len(frames) != 0 || len(lines) == 0 {
+		return nil
+	}
+	var body bytes.Buffer
+	body.WriteByte(m.encoding.Key)
+	body.WriteString("zho")  // todo: support other language
+	body.WriteByte(2)        // 时间戳格式: 毫秒
+	body.WriteByte(1)        // 内容类型: 歌词
+	body.Write(m.encode("")) // 内容描述
+	body.Write(m.encoding.TerminationBytes)
+	for _, line := range lines {
+		body.Write(m.encode(line.Text))
+		body.Write(m.encoding.TerminationBytes)
+		_ = binary.Write(&body, binary.BigEndian, uint32(line.Time.Milliseconds()))
+	}
+	m.tag.AddFrame("SYLT", id3v2.UnknownFrame{Body: body.Bytes()})
+	return nil
+}
+
+// encode 按照帧编码转换文本
+func (m *Mp3) encode(text string) []byte {
+	switch m.encoding.Key {
+	case id3v2.EncodingUTF16.Key, id3v2.EncodingUTF16BE.Key:
+		var buf bytes.Buffer
+		if m.encoding.Key == id3v2.EncodingUTF16.Key {
+			buf.Write([]byte{0xFF, 0xFE}) // BOM little endian
+		}
+		for _, r := range utf16.Encode([]rune(text)) {
+			if m.encoding.Key == id3v2.EncodingUTF16.Key {
+				_ = binary.Write(&buf, binary.LittleEndian, r)
+			} else {
+				_ = binary.Write(&buf, binary.BigEndian, r)
+			}
+		}
+		return buf.Bytes()
+	default:
+		return []byte(text)
+	}
+}
+
+func (m *Mp3) SetAlbumArtist(artists []string) error {
+	var id = m.tag.CommonID("Band/Orchestra/Accompaniment")
+	if frames := m.tag.GetFrames(id); len(frames) == 0 && len(artists) > 0 {
+		m.tag.AddTextFrame(id, m.encoding, strings.Join(artists, "/"))
+	}
+	return nil
+}
+
+func (m *Mp3) SetTrackNumber(number, total int) error {
+	return m.setNumber(m.tag.CommonID("Track number/Position in set"), number, total)
+}
+
+func (m *Mp3) SetDiscNumber(number, total int) error {
+	return m.setNumber(m.tag.CommonID("Part of a set"), number, total)
+}
+
+// setNumber 写入形如 1/10 的编号
+func (m *Mp3) setNumber(id string, number, total int) error {
+	if frames := m.tag.GetFrames(id); len(frames) != 0 || number <= 0 {
+		return nil
+	}
+	var text = strconv.Itoa(number)
+	if total > 0 {
+		text += "/" + strconv.Itoa(total)
+	}
+	m.tag.AddTextFrame(id, m.encoding, text)
+	return nil
+}
+
+func (m *Mp3) SetYear(year int) error {
+	if m.tag.Year() == "" && year > 0 {
+		m.tag.SetYear(strconv.Itoa(year))
+	}
+	return nil
+}
+
 func (m *Mp3) Save() error {
 	if err := m.tag.Save(); err != nil {
 		_ = m.tag.Close()
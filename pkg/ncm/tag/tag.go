@@ -50,6 +50,11 @@ type Tagger interface {
 	SetArtist([]string) error
 	SetComment(string) error
 	SetLyrics(string) error
+	SetSyncedLyrics([]LyricLine) error
+	SetAlbumArtist([]string) error
+	SetTrackNumber(number, total int) error
+	SetDiscNumber(number, total int) error
+	SetYear(int) error
 	Save() error // must be called
 }
 
@@ -156,6 +161,96 @@ func SetMetadata(tag Tagger, imgData []byte, meta *ncm.MetadataMusic) error {
 	return tag.Save()
 }
 
+// Song 歌曲完整的tag信息,零值字段不会写入
+type Song struct {
+	Title        string
+	Album        string
+	Artists      []string
+	AlbumArtists []string
+	TrackNumber  int
+	TrackTotal   int
+	DiscNumber   int
+	DiscTotal    int
+	Year         int
+	Comment      string
+	Cover        []byte // 封面图片内容
+	CoverUrl     string // 封面图片地址,Cover为空时会尝试下载
+	Lyrics       string // LRC格式歌词,会同时写入同步歌词以及去除时间标签后的纯文本歌词
+}
+
+// SetSong 写入歌曲tag信息并保存
+func SetSong(tag Tagger, song *Song) error {
+	var cover = song.Cover
+	if len(cover) <= 0 && song.CoverUrl != "" {
+		data, err := fetchUrl(song.CoverUrl)
+		if err != nil {
+			log.Printf("[tag] fetch %s err:%s", song.CoverUrl, err)
+		} else {
+			cover = data
+		}
+	}
+	if len(cover) > 0 {
+		var mime = ncm.DetectCoverType(cover).MIME()
+		if err := tag.SetCover(cover, mime); err != nil {
+			return fmt.Errorf("SetCover(%v): %w", mime, err)
+		}
+	}
+
+	if song.Title != "" {
+		if err := tag.SetTitle(song.Title); err != nil {
+			return fmt.Errorf("SetTitle: %w", err)
+		}
+	}
+	if song.Album != "" {
+		if err := tag.SetAlbum(song.Album); err != nil {
+			return fmt.Errorf("SetAlbum: %w", err)
+		}
+	}
+	if len(song.Artists) > 0 {
+		if err := tag.SetArtist(song.Artists); err != nil {
+			return fmt.Errorf("SetArtist: %w", err)
+		}
+	}
+	if len(song.AlbumArtists) > 0 {
+		if err := tag.SetAlbumArtist(song.AlbumArtists); err != nil {
+			return fmt.Errorf("SetAlbumArtist: %w", err)
+		}
+	}
+	if song.TrackNumber > 0 {
+		if err := tag.SetTrackNumber(song.TrackNumber, song.TrackTotal); err != nil {
+			return fmt.Errorf("SetTrackNumber: %w", err)
+		}
+	}
+	if song.DiscNumber > 0 {
+		if err := tag.SetDiscNumber(song.DiscNumber, song.DiscTotal); err != nil {
+			return fmt.Errorf("SetDiscNumber: %w", err)
+		}
+	}
+	if song.Year > 0 {
+		if err := tag.SetYear(song.Year); err != nil {
+			return fmt.Errorf("SetYear: %w", err)
+		}
+	}
+	if song.Comment != "" {
+		if err := tag.SetComment(song.Comment); err != nil {
+			return fmt.Errorf("SetComment: %w", err)
+		}
+	}
+	if lines := ParseLRC(song.Lyrics); len(lines) > 0 {
+		if err := tag.SetSyncedLyrics(lines); err != nil {
+			return fmt.Errorf("SetSyncedLyrics: %w", err)
+		}
+		if err := tag.SetLyrics(PlainLyrics(song.Lyrics)); err != nil {
+			return fmt.Errorf("SetLyrics: %w", err)
+		}
+	} else if song.Lyrics != "" {
+		if err := tag.SetLyrics(song.Lyrics); err != nil {
+			return fmt.Errorf("SetLyrics: %w", err)
+		}
+	}
+	return tag.Save()
+}
+
 func fetchUrl(url string) ([]byte, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
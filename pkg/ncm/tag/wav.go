@@ -55,6 +55,30 @@ func (m *WAV) SetComment(comment string) error {
 	return nil
 }
 
+func (m *WAV) SetLyrics(lyrics string) error {
+	return nil
+}
+
+func (m *WAV) SetSyncedLyrics(lines []LyricLine) error {
+	return nil
+}
+
+func (m *WAV) SetAlbumArtist(artists []string) error {
+	return nil
+}
+
+func (m *WAV) SetTrackNumber(number, total int) error {
+	return nil
+}
+
+func (m *WAV) SetDiscNumber(number, total int) error {
+	return nil
+}
+
+func (m *WAV) SetYear(year int) error {
+	return nil
+}
+
 func (m *WAV) Save() error {
 	return nil
 }
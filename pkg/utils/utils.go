@@ -28,6 +28,7 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"math/rand"
 	"net/http"
 	"os"
@@ -183,6 +184,21 @@ func MD5Hex(data []byte) (string, error) {
 	return hex.EncodeToString(m.Sum(nil)), err
 }
 
+// MD5HexFile 计算文件md5值.
+func MD5HexFile(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var m = md5.New()
+	if _, err := io.Copy(m, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(m.Sum(nil)), nil
+}
+
 // Ternary is a generic function that mimics a ternary expression.
 func Ternary[T any](condition bool, trueVal, falseVal T) T {
 	if condition {
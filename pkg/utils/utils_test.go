@@ -146,6 +146,19 @@ func TestMd5Hex(t *testing.T) {
 	assert.Equal(t, "afc48be2ca7c8afc38fbcb67ed7ff610", md5)
 }
 
+func TestMD5HexFile(t *testing.T) {
+	var filename = filepath.Join(t.TempDir(), "md5.txt")
+	assert.NoError(t, os.WriteFile(filename, []byte("netease-cloud-music"), 0644))
+
+	md5, err := MD5HexFile(filename)
+	assert.NoError(t, err)
+	want, _ := MD5Hex([]byte("netease-cloud-music"))
+	assert.Equal(t, want, md5)
+
+	_, err = MD5HexFile(filepath.Join(t.TempDir(), "not-exist"))
+	assert.Error(t, err)
+}
+
 func TestSplitSlice(t *testing.T) {
 	type args[T any] struct {
 		input     []T
@@ -36,6 +36,10 @@ NCMCTL_QINGLONG_LOGIN_MODE=${NCMCTL_QINGLONG_LOGIN_MODE:-cookiecloud}
 NCMCTL_QINGLONG_LOGIN_ACCOUNT=${NCMCTL_QINGLONG_LOGIN_ACCOUNT:-''}
 # NCMCTL_QINGLONG_LOGIN_PASSWORD 登录密码
 NCMCTL_QINGLONG_LOGIN_PASSWORD=${NCMCTL_QINGLONG_LOGIN_PASSWORD:-''}
+# NCMCTL_QINGLONG_LOGIN_CODE phone模式未设置密码时使用的短信验证码,为空时只发送验证码,收到后设置该变量再次运行
+NCMCTL_QINGLONG_LOGIN_CODE=${NCMCTL_QINGLONG_LOGIN_CODE:-''}
+# NCMCTL_QINGLONG_LOGIN_COUNTRYCODE phone模式使用的国家码 默认86
+NCMCTL_QINGLONG_LOGIN_COUNTRYCODE=${NCMCTL_QINGLONG_LOGIN_COUNTRYCODE:-86}
 # NCMCTL_QINGLONG_LOGIN_COOKIE cookie模式时使用（文件路径或cookie字符串）
 NCMCTL_QINGLONG_LOGIN_COOKIE=${NCMCTL_QINGLONG_LOGIN_COOKIE:-''}
 # NCMCTL_QINGLONG_LOGIN_COOKIECLOUD_SERVER cookiecloud模式时使用得服务器地址
@@ -53,10 +57,15 @@ case "${NCMCTL_QINGLONG_LOGIN_MODE}" in
             echo "Error: Please set the environment variable for the account" >&2
             exit 1
         fi
-        login_args+=("${NCMCTL_QINGLONG_LOGIN_ACCOUNT}")
+        login_args+=("${NCMCTL_QINGLONG_LOGIN_ACCOUNT}" --countrycode "${NCMCTL_QINGLONG_LOGIN_COUNTRYCODE}")
 
         if [[ -n "${NCMCTL_QINGLONG_LOGIN_PASSWORD}" ]]; then
             login_args+=(-p "${NCMCTL_QINGLONG_LOGIN_PASSWORD}")
+        elif [[ -n "${NCMCTL_QINGLONG_LOGIN_CODE}" ]]; then
+            login_args+=(--code "${NCMCTL_QINGLONG_LOGIN_CODE}")
+        else
+            echo "Tip: NCMCTL_QINGLONG_LOGIN_CODE is empty, only send sms captcha. set it after receiving the captcha and run again"
+            login_args+=(--send)
         fi
         ;;
 